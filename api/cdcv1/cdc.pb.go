@@ -0,0 +1,435 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: api/cdcv1/cdc.proto
+
+package cdcv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Position LSN de commit e seqval (binary(10) em hex)
+type Position struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Lsn    string                 `protobuf:"bytes,1,opt,name=lsn,proto3" json:"lsn,omitempty"`
+	Seqval string                 `protobuf:"bytes,2,opt,name=seqval,proto3" json:"seqval,omitempty"`
+	// schema.tabela do evento: snapshot, truncate e histórico temporal repetem
+	// a mesma posição em várias tabelas. Devolva a posição recebida inteira em
+	// Ack e from_position.
+	Table         string `protobuf:"bytes,3,opt,name=table,proto3" json:"table,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_api_cdcv1_cdc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Position) GetLsn() string {
+	if x != nil {
+		return x.Lsn
+	}
+	return ""
+}
+
+func (x *Position) GetSeqval() string {
+	if x != nil {
+		return x.Seqval
+	}
+	return ""
+}
+
+func (x *Position) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+type SubscribeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// identifica o consumidor para Ack e retomada
+	ConsumerId string `protobuf:"bytes,1,opt,name=consumer_id,json=consumerId,proto3" json:"consumer_id,omitempty"`
+	// schema.tabela (sem diferenciar maiúsculas); vazio = todas
+	Tables []string `protobuf:"bytes,2,rep,name=tables,proto3" json:"tables,omitempty"`
+	// posição exclusiva; vazia = último ack do consumidor ou apenas eventos novos
+	FromPosition  *Position `protobuf:"bytes,3,opt,name=from_position,json=fromPosition,proto3" json:"from_position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_api_cdcv1_cdc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubscribeRequest) GetConsumerId() string {
+	if x != nil {
+		return x.ConsumerId
+	}
+	return ""
+}
+
+func (x *SubscribeRequest) GetTables() []string {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetFromPosition() *Position {
+	if x != nil {
+		return x.FromPosition
+	}
+	return nil
+}
+
+type ChangeEvent struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Op         string                 `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"`
+	Database   string                 `protobuf:"bytes,2,opt,name=database,proto3" json:"database,omitempty"`
+	Schema     string                 `protobuf:"bytes,3,opt,name=schema,proto3" json:"schema,omitempty"`
+	Table      string                 `protobuf:"bytes,4,opt,name=table,proto3" json:"table,omitempty"`
+	Position   *Position              `protobuf:"bytes,5,opt,name=position,proto3" json:"position,omitempty"`
+	CommitTime *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=commit_time,json=commitTime,proto3" json:"commit_time,omitempty"`
+	// chave primária em JSON (ordem das colunas da chave)
+	Key []byte `protobuf:"bytes,7,opt,name=key,proto3" json:"key,omitempty"`
+	// evento completo em JSON, no mesmo formato dos demais sinks
+	Payload       []byte `protobuf:"bytes,8,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeEvent) Reset() {
+	*x = ChangeEvent{}
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeEvent) ProtoMessage() {}
+
+func (x *ChangeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeEvent.ProtoReflect.Descriptor instead.
+func (*ChangeEvent) Descriptor() ([]byte, []int) {
+	return file_api_cdcv1_cdc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ChangeEvent) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetDatabase() string {
+	if x != nil {
+		return x.Database
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetSchema() string {
+	if x != nil {
+		return x.Schema
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *ChangeEvent) GetCommitTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommitTime
+	}
+	return nil
+}
+
+func (x *ChangeEvent) GetKey() []byte {
+	if x != nil {
+		return x.Key
+	}
+	return nil
+}
+
+func (x *ChangeEvent) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type AckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ConsumerId    string                 `protobuf:"bytes,1,opt,name=consumer_id,json=consumerId,proto3" json:"consumer_id,omitempty"`
+	Position      *Position              `protobuf:"bytes,2,opt,name=position,proto3" json:"position,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckRequest) Reset() {
+	*x = AckRequest{}
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckRequest) ProtoMessage() {}
+
+func (x *AckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckRequest.ProtoReflect.Descriptor instead.
+func (*AckRequest) Descriptor() ([]byte, []int) {
+	return file_api_cdcv1_cdc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AckRequest) GetConsumerId() string {
+	if x != nil {
+		return x.ConsumerId
+	}
+	return ""
+}
+
+func (x *AckRequest) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+type AckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckResponse) Reset() {
+	*x = AckResponse{}
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckResponse) ProtoMessage() {}
+
+func (x *AckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_cdcv1_cdc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckResponse.ProtoReflect.Descriptor instead.
+func (*AckResponse) Descriptor() ([]byte, []int) {
+	return file_api_cdcv1_cdc_proto_rawDescGZIP(), []int{4}
+}
+
+var File_api_cdcv1_cdc_proto protoreflect.FileDescriptor
+
+const file_api_cdcv1_cdc_proto_rawDesc = "" +
+	"\n" +
+	"\x13api/cdcv1/cdc.proto\x12\bgocdc.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"J\n" +
+	"\bPosition\x12\x10\n" +
+	"\x03lsn\x18\x01 \x01(\tR\x03lsn\x12\x16\n" +
+	"\x06seqval\x18\x02 \x01(\tR\x06seqval\x12\x14\n" +
+	"\x05table\x18\x03 \x01(\tR\x05table\"\x84\x01\n" +
+	"\x10SubscribeRequest\x12\x1f\n" +
+	"\vconsumer_id\x18\x01 \x01(\tR\n" +
+	"consumerId\x12\x16\n" +
+	"\x06tables\x18\x02 \x03(\tR\x06tables\x127\n" +
+	"\rfrom_position\x18\x03 \x01(\v2\x12.gocdc.v1.PositionR\ffromPosition\"\x80\x02\n" +
+	"\vChangeEvent\x12\x0e\n" +
+	"\x02op\x18\x01 \x01(\tR\x02op\x12\x1a\n" +
+	"\bdatabase\x18\x02 \x01(\tR\bdatabase\x12\x16\n" +
+	"\x06schema\x18\x03 \x01(\tR\x06schema\x12\x14\n" +
+	"\x05table\x18\x04 \x01(\tR\x05table\x12.\n" +
+	"\bposition\x18\x05 \x01(\v2\x12.gocdc.v1.PositionR\bposition\x12;\n" +
+	"\vcommit_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"commitTime\x12\x10\n" +
+	"\x03key\x18\a \x01(\fR\x03key\x12\x18\n" +
+	"\apayload\x18\b \x01(\fR\apayload\"]\n" +
+	"\n" +
+	"AckRequest\x12\x1f\n" +
+	"\vconsumer_id\x18\x01 \x01(\tR\n" +
+	"consumerId\x12.\n" +
+	"\bposition\x18\x02 \x01(\v2\x12.gocdc.v1.PositionR\bposition\"\r\n" +
+	"\vAckResponse2\x84\x01\n" +
+	"\fChangeStream\x12@\n" +
+	"\tSubscribe\x12\x1a.gocdc.v1.SubscribeRequest\x1a\x15.gocdc.v1.ChangeEvent0\x01\x122\n" +
+	"\x03Ack\x12\x14.gocdc.v1.AckRequest\x1a\x15.gocdc.v1.AckResponseB\x18Z\x16go-cdc/api/cdcv1;cdcv1b\x06proto3"
+
+var (
+	file_api_cdcv1_cdc_proto_rawDescOnce sync.Once
+	file_api_cdcv1_cdc_proto_rawDescData []byte
+)
+
+func file_api_cdcv1_cdc_proto_rawDescGZIP() []byte {
+	file_api_cdcv1_cdc_proto_rawDescOnce.Do(func() {
+		file_api_cdcv1_cdc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_cdcv1_cdc_proto_rawDesc), len(file_api_cdcv1_cdc_proto_rawDesc)))
+	})
+	return file_api_cdcv1_cdc_proto_rawDescData
+}
+
+var file_api_cdcv1_cdc_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_api_cdcv1_cdc_proto_goTypes = []any{
+	(*Position)(nil),              // 0: gocdc.v1.Position
+	(*SubscribeRequest)(nil),      // 1: gocdc.v1.SubscribeRequest
+	(*ChangeEvent)(nil),           // 2: gocdc.v1.ChangeEvent
+	(*AckRequest)(nil),            // 3: gocdc.v1.AckRequest
+	(*AckResponse)(nil),           // 4: gocdc.v1.AckResponse
+	(*timestamppb.Timestamp)(nil), // 5: google.protobuf.Timestamp
+}
+var file_api_cdcv1_cdc_proto_depIdxs = []int32{
+	0, // 0: gocdc.v1.SubscribeRequest.from_position:type_name -> gocdc.v1.Position
+	0, // 1: gocdc.v1.ChangeEvent.position:type_name -> gocdc.v1.Position
+	5, // 2: gocdc.v1.ChangeEvent.commit_time:type_name -> google.protobuf.Timestamp
+	0, // 3: gocdc.v1.AckRequest.position:type_name -> gocdc.v1.Position
+	1, // 4: gocdc.v1.ChangeStream.Subscribe:input_type -> gocdc.v1.SubscribeRequest
+	3, // 5: gocdc.v1.ChangeStream.Ack:input_type -> gocdc.v1.AckRequest
+	2, // 6: gocdc.v1.ChangeStream.Subscribe:output_type -> gocdc.v1.ChangeEvent
+	4, // 7: gocdc.v1.ChangeStream.Ack:output_type -> gocdc.v1.AckResponse
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_api_cdcv1_cdc_proto_init() }
+func file_api_cdcv1_cdc_proto_init() {
+	if File_api_cdcv1_cdc_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_cdcv1_cdc_proto_rawDesc), len(file_api_cdcv1_cdc_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_cdcv1_cdc_proto_goTypes,
+		DependencyIndexes: file_api_cdcv1_cdc_proto_depIdxs,
+		MessageInfos:      file_api_cdcv1_cdc_proto_msgTypes,
+	}.Build()
+	File_api_cdcv1_cdc_proto = out.File
+	file_api_cdcv1_cdc_proto_goTypes = nil
+	file_api_cdcv1_cdc_proto_depIdxs = nil
+}
@@ -0,0 +1,177 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: api/cdcv1/cdc.proto
+
+package cdcv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ChangeStream_Subscribe_FullMethodName = "/gocdc.v1.ChangeStream/Subscribe"
+	ChangeStream_Ack_FullMethodName       = "/gocdc.v1.ChangeStream/Ack"
+)
+
+// ChangeStreamClient is the client API for ChangeStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChangeStream entrega os eventos de CDC diretamente aos consumidores. Cada
+// consumidor (consumer_id) confirma o que processou com Ack e, ao reconectar
+// sem from_position, retoma do último ack enquanto os eventos ainda estiverem
+// no buffer de retenção do servidor.
+type ChangeStreamClient interface {
+	// Subscribe envia os eventos posteriores à posição inicial, em ordem
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChangeEvent], error)
+	// Ack registra a última posição processada pelo consumidor
+	Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*AckResponse, error)
+}
+
+type changeStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChangeStreamClient(cc grpc.ClientConnInterface) ChangeStreamClient {
+	return &changeStreamClient{cc}
+}
+
+func (c *changeStreamClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChangeEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChangeStream_ServiceDesc.Streams[0], ChangeStream_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, ChangeEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChangeStream_SubscribeClient = grpc.ServerStreamingClient[ChangeEvent]
+
+func (c *changeStreamClient) Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*AckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AckResponse)
+	err := c.cc.Invoke(ctx, ChangeStream_Ack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChangeStreamServer is the server API for ChangeStream service.
+// All implementations must embed UnimplementedChangeStreamServer
+// for forward compatibility.
+//
+// ChangeStream entrega os eventos de CDC diretamente aos consumidores. Cada
+// consumidor (consumer_id) confirma o que processou com Ack e, ao reconectar
+// sem from_position, retoma do último ack enquanto os eventos ainda estiverem
+// no buffer de retenção do servidor.
+type ChangeStreamServer interface {
+	// Subscribe envia os eventos posteriores à posição inicial, em ordem
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[ChangeEvent]) error
+	// Ack registra a última posição processada pelo consumidor
+	Ack(context.Context, *AckRequest) (*AckResponse, error)
+	mustEmbedUnimplementedChangeStreamServer()
+}
+
+// UnimplementedChangeStreamServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChangeStreamServer struct{}
+
+func (UnimplementedChangeStreamServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[ChangeEvent]) error {
+	return status.Error(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedChangeStreamServer) Ack(context.Context, *AckRequest) (*AckResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Ack not implemented")
+}
+func (UnimplementedChangeStreamServer) mustEmbedUnimplementedChangeStreamServer() {}
+func (UnimplementedChangeStreamServer) testEmbeddedByValue()                      {}
+
+// UnsafeChangeStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChangeStreamServer will
+// result in compilation errors.
+type UnsafeChangeStreamServer interface {
+	mustEmbedUnimplementedChangeStreamServer()
+}
+
+func RegisterChangeStreamServer(s grpc.ServiceRegistrar, srv ChangeStreamServer) {
+	// If the following call panics, it indicates UnimplementedChangeStreamServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChangeStream_ServiceDesc, srv)
+}
+
+func _ChangeStream_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChangeStreamServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, ChangeEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChangeStream_SubscribeServer = grpc.ServerStreamingServer[ChangeEvent]
+
+func _ChangeStream_Ack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChangeStreamServer).Ack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChangeStream_Ack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChangeStreamServer).Ack(ctx, req.(*AckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChangeStream_ServiceDesc is the grpc.ServiceDesc for ChangeStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChangeStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gocdc.v1.ChangeStream",
+	HandlerType: (*ChangeStreamServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ack",
+			Handler:    _ChangeStream_Ack_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _ChangeStream_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/cdcv1/cdc.proto",
+}
@@ -0,0 +1,6 @@
+// api/cdcv1/doc.go
+
+// Package cdcv1 contém a API gRPC ChangeStream (gerada a partir de cdc.proto)
+package cdcv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative api/cdcv1/cdc.proto
@@ -19,7 +19,9 @@ func Init(config *config.Config) (*DatabaseManager, static.ErrorUtil) {
 	log.Info().Msg("Initializing database configuration...")
 
 	log.Info().Msg("Checking configuration environments...")
-	if config.DBHost == "" || config.DBPort == "" || config.DBUser == "" || config.DBPass == "" || config.DBName == "" {
+	// Credenciais dependem do modo de autenticação e são validadas pelo driver específico.
+	// Com uma connection string completa, host/porta/banco podem vir dela.
+	if config.DBConnString == "" && (config.DBHost == "" || config.DBPort == "" || config.DBName == "") {
 		log.Error().Caller().Msg("Database configuration is incomplete.")
 		return nil, static.ErrEnvVarMissing
 	}
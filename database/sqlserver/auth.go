@@ -0,0 +1,189 @@
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"go-cdc/static"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/microsoft/go-mssqldb/azuread"
+	"github.com/rs/zerolog/log"
+)
+
+// Modos de autenticação suportados (APP_GO_CDC_DB_AUTH_MODE)
+const (
+	AuthModeSQL                   = "sql"
+	AuthModeAzureClientSecret     = "azure_client_secret"
+	AuthModeAzureClientCert       = "azure_client_certificate"
+	AuthModeAzureWorkloadIdentity = "azure_workload_identity"
+	AuthModeAzureManagedIdentity  = "azure_managed_identity"
+	AuthModeAccessTokenFile       = "access_token_file"
+	AuthModeAccessTokenCommand    = "access_token_command"
+)
+
+// tokenCommandTimeout limita a execução do comando que gera o access token
+const tokenCommandTimeout = 30 * time.Second
+
+// isAzureADMode indica se o modo usa o driver azuread (fedauth)
+func (c *connParams) isAzureADMode() bool {
+	switch c.AuthMode {
+	case AuthModeAzureClientSecret, AuthModeAzureClientCert, AuthModeAzureWorkloadIdentity, AuthModeAzureManagedIdentity:
+		return true
+	}
+	return false
+}
+
+// isAccessTokenMode indica se o token é obtido fora do driver (arquivo ou comando)
+func (c *connParams) isAccessTokenMode() bool {
+	return c.AuthMode == AuthModeAccessTokenFile || c.AuthMode == AuthModeAccessTokenCommand
+}
+
+// Validate verifica se os campos exigidos pelo modo de autenticação estão presentes
+func (c *connParams) Validate() static.ErrorUtil {
+	invalid := func(msg string) static.ErrorUtil {
+		log.Error().Caller().Str("auth_mode", c.AuthMode).Msg(msg)
+		return static.NewErrorUtil(msg, "SQLSERVER_AUTH_CONFIG_INVALID", nil, "auth_mode="+c.AuthMode)
+	}
+
+	if c.ConnString != "" {
+		if _, err := parseConnString(c.ConnString); err != nil {
+			log.Error().Caller().Err(err).Msg("Invalid database connection string")
+			return static.NewErrorUtil("Invalid database connection string", "SQLSERVER_CONN_STRING_INVALID", err, err.Error())
+		}
+	}
+	if c.ExtraParams != "" {
+		if _, err := parseConnStringADO(c.ExtraParams); err != nil {
+			log.Error().Caller().Err(err).Msg("Invalid database connection parameters")
+			return static.NewErrorUtil("Invalid database connection parameters", "SQLSERVER_CONN_PARAMS_INVALID", err, err.Error())
+		}
+	}
+
+	switch c.AuthMode {
+	case AuthModeSQL:
+		params := c.buildParams()
+		if params.Get("user id") == "" || params.Get("password") == "" {
+			return invalid("SQL authentication requires user and password")
+		}
+	case AuthModeAzureClientSecret:
+		if c.AzureClientID == "" || c.AzureClientSecret == "" {
+			return invalid("Azure client secret authentication requires client id and client secret")
+		}
+	case AuthModeAzureClientCert:
+		if c.AzureClientID == "" || c.AzureClientCertPath == "" {
+			return invalid("Azure client certificate authentication requires client id and certificate path")
+		}
+	case AuthModeAzureWorkloadIdentity:
+		if c.AzureFederatedTokenFile == "" {
+			return invalid("Azure workload identity authentication requires a federated token file")
+		}
+	case AuthModeAzureManagedIdentity:
+		// client id opcional: vazio usa a identidade atribuída pelo sistema
+	case AuthModeAccessTokenFile:
+		if c.AccessTokenFile == "" {
+			return invalid("Access token file authentication requires a token file path")
+		}
+	case AuthModeAccessTokenCommand:
+		if c.AccessTokenCommand == "" {
+			return invalid("Access token command authentication requires a command")
+		}
+	default:
+		return invalid("Unsupported database authentication mode")
+	}
+
+	return nil
+}
+
+// setAuthParams adiciona os parâmetros de credenciais conforme o modo de autenticação
+func (c *connParams) setAuthParams(p *dsnParams) {
+	// user id no formato client id@tenant id aceito pelo driver azuread
+	azureUser := c.AzureClientID
+	if azureUser != "" && c.AzureTenantID != "" {
+		azureUser += "@" + c.AzureTenantID
+	}
+
+	switch c.AuthMode {
+	case AuthModeAzureClientSecret:
+		p.Set("fedauth", azuread.ActiveDirectoryServicePrincipal)
+		p.Set("user id", azureUser)
+		p.Set("password", c.AzureClientSecret)
+	case AuthModeAzureClientCert:
+		p.Set("fedauth", azuread.ActiveDirectoryServicePrincipal)
+		p.Set("user id", azureUser)
+		p.Set("clientcertpath", c.AzureClientCertPath)
+		if c.AzureClientCertPassword != "" {
+			p.Set("password", c.AzureClientCertPassword)
+		}
+	case AuthModeAzureWorkloadIdentity:
+		p.Set("fedauth", azuread.ActiveDirectoryWorkloadIdentity)
+		p.Set("tokenfilepath", c.AzureFederatedTokenFile)
+		if azureUser != "" {
+			p.Set("user id", azureUser)
+		}
+	case AuthModeAzureManagedIdentity:
+		p.Set("fedauth", azuread.ActiveDirectoryManagedIdentity)
+		if c.AzureClientID != "" {
+			p.Set("user id", c.AzureClientID)
+		}
+	case AuthModeAccessTokenFile, AuthModeAccessTokenCommand:
+		// o token é injetado pelo connector, sem credenciais na connection string
+	default:
+		// credenciais da connection string são mantidas quando os campos individuais estão vazios
+		if c.DBUser != "" {
+			p.Set("user id", c.DBUser)
+		}
+		if c.DBPass != "" {
+			p.Set("password", c.DBPass)
+		}
+	}
+}
+
+// openDB abre o pool com o driver adequado ao modo de autenticação
+func (c *connParams) openDB() (*sql.DB, error) {
+	dsn := c.GetConnString(true)
+
+	switch {
+	case c.isAzureADMode():
+		return sql.Open(azuread.DriverName, dsn)
+	case c.isAccessTokenMode():
+		connector, err := mssql.NewConnectorWithAccessTokenProvider(dsn, c.accessToken)
+		if err != nil {
+			return nil, err
+		}
+		return sql.OpenDB(connector), nil
+	default:
+		return sql.Open("sqlserver", dsn)
+	}
+}
+
+// accessToken lê o token do arquivo ou executa o comando configurado.
+// É chamado a cada nova conexão, então tokens renovados externamente são respeitados.
+func (c *connParams) accessToken(ctx context.Context) (string, error) {
+	var raw []byte
+	var err error
+
+	switch c.AuthMode {
+	case AuthModeAccessTokenFile:
+		raw, err = os.ReadFile(c.AccessTokenFile)
+	case AuthModeAccessTokenCommand:
+		cmdCtx, cancel := context.WithTimeout(ctx, tokenCommandTimeout)
+		defer cancel()
+		raw, err = exec.CommandContext(cmdCtx, "sh", "-c", c.AccessTokenCommand).Output()
+	}
+	if err != nil {
+		log.Error().Caller().Err(err).Str("auth_mode", c.AuthMode).Msg("Failed to obtain database access token")
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(raw))
+	if token == "" {
+		return "", errors.New("empty database access token")
+	}
+
+	return token, nil
+}
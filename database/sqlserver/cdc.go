@@ -0,0 +1,355 @@
+// database/sqlserver/cdc.go
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"strings"
+	"time"
+
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Valores de __$operation em fn_cdc_get_all_changes
+const (
+	cdcOpDelete       = 1
+	cdcOpInsert       = 2
+	cdcOpUpdateBefore = 3
+	cdcOpUpdateAfter  = 4
+)
+
+const commitTimeColumn = "__go_cdc_commit_time"
+
+// zeroLSN usado como limite inferior quando ainda não há posição armazenada
+var zeroLSN = make([]byte, 10)
+
+// CaptureInstance metadados de uma tabela habilitada para CDC (cdc.change_tables)
+type CaptureInstance struct {
+	Name               string
+	SourceSchema       string
+	SourceTable        string
+	SourceObjectID     int
+	StartLSN           string
+	IndexName          string
+	SupportsNetChanges bool
+
+	// CapturedColumns preenchido por GetCapturedColumns; usado para decodificar __$update_mask
+	CapturedColumns []CapturedColumn
+}
+
+// FullTableName retorna schema.tabela da tabela de origem
+func (c *CaptureInstance) FullTableName() string {
+	return c.SourceSchema + "." + c.SourceTable
+}
+
+// QuoteIdentifier delimita um identificador com colchetes
+func QuoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+// IsCDCEnabled indica se o banco atual está habilitado para CDC
+func (s *SQLServer) IsCDCEnabled(ctx context.Context) (bool, static.ErrorUtil) {
+	var enabled bool
+	err := s.withRetry(ctx, "is_cdc_enabled", func(ctx context.Context) error {
+		return s.db.QueryRowContext(ctx, "SELECT is_cdc_enabled FROM sys.databases WHERE database_id = DB_ID()").Scan(&enabled)
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to check CDC status")
+		return false, static.NewErrorUtil("Failed to check CDC status", "SQLSERVER_CDC_STATUS_FAILED", err, err.Error())
+	}
+	return enabled, nil
+}
+
+// Exec executa uma instrução administrativa (sem retry: procedures de CDC não são idempotentes)
+func (s *SQLServer) Exec(ctx context.Context, statement string) static.ErrorUtil {
+	if _, err := s.db.ExecContext(ctx, statement); err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to execute statement")
+		return static.NewErrorUtil("Failed to execute statement", "SQLSERVER_EXEC_FAILED", err, err.Error())
+	}
+	return nil
+}
+
+// ListCaptureInstances lista as capture instances; quando uma tabela possui duas
+// (migração de schema), a mais recente vem primeiro
+func (s *SQLServer) ListCaptureInstances(ctx context.Context) ([]CaptureInstance, static.ErrorUtil) {
+	var instances []CaptureInstance
+	err := s.withRetry(ctx, "list_capture_instances", func(ctx context.Context) error {
+		var errList error
+		instances, errList = s.listCaptureInstances(ctx)
+		return errList
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to list CDC capture instances")
+		return nil, static.NewErrorUtil("Failed to list CDC capture instances", "SQLSERVER_CDC_LIST_FAILED", err, err.Error())
+	}
+
+	return instances, nil
+}
+
+func (s *SQLServer) listCaptureInstances(ctx context.Context) ([]CaptureInstance, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT ct.capture_instance, sch.name, t.name, ct.source_object_id,
+		       ct.start_lsn, ct.index_name, ct.supports_net_changes
+		FROM cdc.change_tables ct
+		JOIN sys.tables t ON t.object_id = ct.source_object_id
+		JOIN sys.schemas sch ON sch.schema_id = t.schema_id
+		ORDER BY sch.name, t.name, ct.create_date DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var instances []CaptureInstance
+	for rows.Next() {
+		var ci CaptureInstance
+		var startLSN []byte
+		var indexName sql.NullString
+		if err := rows.Scan(&ci.Name, &ci.SourceSchema, &ci.SourceTable, &ci.SourceObjectID,
+			&startLSN, &indexName, &ci.SupportsNetChanges); err != nil {
+			return nil, err
+		}
+		ci.StartLSN = LSNToHex(startLSN)
+		ci.IndexName = indexName.String
+		instances = append(instances, ci)
+	}
+
+	return instances, rows.Err()
+}
+
+// ReadChanges lê até limit alterações da capture instance posteriores a after e
+// com LSN <= toLSN. truncated indica que o limite foi atingido e há mais dados;
+// nesse caso o lote é estendido até o fim do LSN do último evento, para que
+// nenhuma transação de origem fique dividida entre dois lotes.
+func (s *SQLServer) ReadChanges(ctx context.Context, ci *CaptureInstance, database string, after event.Position, fromLSN string, toLSN string, limit int) ([]event.Event, bool, static.ErrorUtil) {
+	fail := func(err error) ([]event.Event, bool, static.ErrorUtil) {
+		log.Error().Caller().Err(err).Str("capture_instance", ci.Name).Msg("Failed to read CDC changes")
+		return nil, false, static.NewErrorUtil("Failed to read CDC changes", "SQLSERVER_CDC_READ_FAILED", err, err.Error())
+	}
+
+	from, err := LSNFromHex(fromLSN)
+	if err != nil {
+		return fail(err)
+	}
+	to, err := LSNFromHex(toLSN)
+	if err != nil {
+		return fail(err)
+	}
+	afterLSN, afterSeq := zeroLSN, zeroLSN
+	if !after.IsZero() {
+		if afterLSN, err = LSNFromHex(after.LSN); err != nil {
+			return fail(err)
+		}
+		if afterSeq, err = LSNFromHex(after.SeqVal); err != nil {
+			return fail(err)
+		}
+	}
+
+	// leitura é idempotente: em erro transitório o lote inteiro é relido
+	var events []event.Event
+	var truncated bool
+	err = s.withRetry(ctx, "read_changes", func(ctx context.Context) error {
+		var errQuery error
+		events, truncated, errQuery = s.queryChanges(ctx, ci, database, from, to, limit, afterLSN, afterSeq)
+		if errQuery != nil || !truncated || len(events) == 0 {
+			return errQuery
+		}
+
+		// restante da transação do último evento, sem limite
+		last := events[len(events)-1].Position
+		lastLSN, errLSN := LSNFromHex(last.LSN)
+		if errLSN != nil {
+			return errLSN
+		}
+		lastSeq, errSeq := LSNFromHex(last.SeqVal)
+		if errSeq != nil {
+			return errSeq
+		}
+		rest, _, errRest := s.queryChanges(ctx, ci, database, lastLSN, lastLSN, math.MaxInt32, lastLSN, lastSeq)
+		events = append(events, rest...)
+		return errRest
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	return events, truncated, nil
+}
+
+func (s *SQLServer) queryChanges(ctx context.Context, ci *CaptureInstance, database string, from []byte, to []byte, limit int, afterLSN []byte, afterSeq []byte) ([]event.Event, bool, error) {
+	query := `
+		SELECT TOP (@p3) ct.*, tm.tran_end_time AS ` + commitTimeColumn + `
+		FROM cdc.` + QuoteIdentifier("fn_cdc_get_all_changes_"+ci.Name) + `(@p1, @p2, N'all update old') ct
+		LEFT JOIN cdc.lsn_time_mapping tm ON tm.start_lsn = ct.__$start_lsn
+		WHERE ct.__$start_lsn > @p4 OR (ct.__$start_lsn = @p4 AND ct.__$seqval > @p5)
+		ORDER BY ct.__$start_lsn, ct.__$seqval, ct.__$operation`
+
+	rows, err := s.db.QueryContext(ctx, query, from, to, limit, afterLSN, afterSeq)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var events []event.Event
+	var pendingBefore map[string]interface{}
+	var pendingSeq string
+	read := 0
+
+	for rows.Next() {
+		values := make([]interface{}, len(columnTypes))
+		pointers := make([]interface{}, len(columnTypes))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, false, err
+		}
+		read++
+
+		var startLSN, seqVal string
+		var operation int64
+		var updateMask []byte
+		var commitTime time.Time
+		data := make(map[string]interface{}, len(columnTypes))
+
+		for i, ct := range columnTypes {
+			name := ct.Name()
+			switch name {
+			case "__$start_lsn":
+				startLSN = LSNToHex(values[i].([]byte))
+			case "__$seqval":
+				seqVal = LSNToHex(values[i].([]byte))
+			case "__$operation":
+				operation = values[i].(int64)
+			case "__$update_mask":
+				updateMask, _ = values[i].([]byte)
+			case commitTimeColumn:
+				if t, ok := values[i].(time.Time); ok {
+					commitTime = t
+				}
+			default:
+				if strings.HasPrefix(name, "__$") {
+					continue
+				}
+				data[name] = normalizeValue(ct.DatabaseTypeName(), values[i])
+			}
+		}
+
+		evt := event.Event{
+			Database:        database,
+			Schema:          ci.SourceSchema,
+			Table:           ci.SourceTable,
+			CaptureInstance: ci.Name,
+			Position:        event.Position{LSN: startLSN, SeqVal: seqVal},
+			CommitTime:      commitTime,
+		}
+
+		switch operation {
+		case cdcOpDelete:
+			evt.Op = event.OpDelete
+			evt.Before = data
+		case cdcOpInsert:
+			evt.Op = event.OpInsert
+			evt.After = data
+		case cdcOpUpdateBefore:
+			// imagem anterior: emitida junto com a imagem posterior (mesmo seqval)
+			pendingBefore, pendingSeq = data, seqVal
+			continue
+		case cdcOpUpdateAfter:
+			evt.Op = event.OpUpdate
+			evt.After = data
+			if len(ci.CapturedColumns) > 0 {
+				evt.UpdatedColumns = updatedColumns(updateMask, ci.CapturedColumns)
+			}
+			if pendingSeq == seqVal {
+				evt.Before = pendingBefore
+			}
+			pendingBefore, pendingSeq = nil, ""
+		default:
+			continue
+		}
+
+		events = append(events, evt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	// Se o TOP cortou entre a imagem anterior e a posterior de um update, a
+	// imagem anterior pendente é descartada e relida na extensão do lote até o
+	// fim do LSN (ReadChanges)
+	return events, read >= limit, nil
+}
+
+// CapturedColumn coluna presente na change table de uma capture instance
+type CapturedColumn struct {
+	Name    string
+	Ordinal int // posição no __$update_mask
+	Type    string
+}
+
+// GetCapturedColumns lê as colunas capturadas (cdc.captured_columns) da capture instance
+func (s *SQLServer) GetCapturedColumns(ctx context.Context, captureInstance string) ([]CapturedColumn, static.ErrorUtil) {
+	var columns []CapturedColumn
+	err := s.withRetry(ctx, "get_captured_columns", func(ctx context.Context) error {
+		columns = nil
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT cc.column_name, cc.column_ordinal, cc.column_type
+			FROM cdc.captured_columns cc
+			JOIN cdc.change_tables ct ON ct.object_id = cc.object_id
+			WHERE ct.capture_instance = @p1
+			ORDER BY cc.column_ordinal`, captureInstance)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var col CapturedColumn
+			var ordinal int64
+			if err := rows.Scan(&col.Name, &ordinal, &col.Type); err != nil {
+				return err
+			}
+			col.Ordinal = int(ordinal)
+			columns = append(columns, col)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Str("capture_instance", captureInstance).Msg("Failed to read captured columns")
+		return nil, static.NewErrorUtil("Failed to read captured columns", "SQLSERVER_CAPTURED_COLUMNS_FAILED", err, err.Error())
+	}
+	return columns, nil
+}
+
+// isBitSet equivale a sys.fn_cdc_is_bit_set: o ordinal 1 é o bit menos
+// significativo do último byte da máscara
+func isBitSet(mask []byte, ordinal int) bool {
+	if ordinal < 1 {
+		return false
+	}
+	idx := len(mask) - 1 - (ordinal-1)/8
+	if idx < 0 {
+		return false
+	}
+	return mask[idx]&(1<<uint((ordinal-1)%8)) != 0
+}
+
+// updatedColumns retorna as colunas marcadas no __$update_mask
+func updatedColumns(mask []byte, columns []CapturedColumn) []string {
+	var updated []string
+	for _, col := range columns {
+		if isBitSet(mask, col.Ordinal) {
+			updated = append(updated, col.Name)
+		}
+	}
+	return updated
+}
@@ -0,0 +1,67 @@
+package sqlserver
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIsBitSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		mask    []byte
+		ordinal int
+		want    bool
+	}{
+		{"first column", []byte{0x01}, 1, true},
+		{"third column", []byte{0x04}, 3, true},
+		{"unset column", []byte{0x04}, 2, false},
+		{"eighth column", []byte{0x80}, 8, true},
+		{"ninth column in previous byte", []byte{0x01, 0x00}, 9, true},
+		{"first column in last byte", []byte{0x01, 0x01}, 1, true},
+		{"tenth column", []byte{0x02, 0x00}, 10, true},
+		{"ordinal beyond mask", []byte{0xff}, 9, false},
+		{"ordinal zero", []byte{0xff}, 0, false},
+		{"empty mask", nil, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBitSet(tt.mask, tt.ordinal); got != tt.want {
+				t.Errorf("isBitSet(%x, %d) = %v, want %v", tt.mask, tt.ordinal, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdatedColumns(t *testing.T) {
+	columns := []CapturedColumn{
+		{Name: "id", Ordinal: 1},
+		{Name: "name", Ordinal: 2},
+		{Name: "email", Ordinal: 3},
+		{Name: "status", Ordinal: 8},
+		{Name: "updated_at", Ordinal: 9},
+		{Name: "notes", Ordinal: 12},
+	}
+
+	tests := []struct {
+		name string
+		mask []byte
+		want []string
+	}{
+		// UPDATE t SET name = ..., updated_at = ...
+		{"name and updated_at", []byte{0x01, 0x02}, []string{"name", "updated_at"}},
+		// inserts e deletes marcam todas as colunas
+		{"all columns", []byte{0x0f, 0xff}, []string{"id", "name", "email", "status", "updated_at", "notes"}},
+		{"notes only", []byte{0x08, 0x00}, []string{"notes"}},
+		{"status only", []byte{0x00, 0x80}, []string{"status"}},
+		{"nothing", []byte{0x00, 0x00}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := updatedColumns(tt.mask, columns); !slices.Equal(got, tt.want) {
+				t.Errorf("updatedColumns(%x) = %v, want %v", tt.mask, got, tt.want)
+			}
+		})
+	}
+}
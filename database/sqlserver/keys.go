@@ -0,0 +1,116 @@
+// database/sqlserver/keys.go
+package sqlserver
+
+import (
+	"context"
+
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// KeySource origem das colunas de chave de uma tabela
+type KeySource string
+
+const (
+	KeySourceCDCIndex    KeySource = "cdc_index"   // @index_name informado em sp_cdc_enable_table
+	KeySourcePrimaryKey  KeySource = "primary_key" // sys.indexes is_primary_key
+	KeySourceUniqueIndex KeySource = "unique_index"
+	KeySourceNone        KeySource = "none"
+)
+
+// GetKeyColumns descobre as colunas que identificam unicamente uma linha, na
+// ordem: índice definido no CDC, primary key e, por fim, o menor índice único
+// sem filtro.
+func (s *SQLServer) GetKeyColumns(ctx context.Context, ci *CaptureInstance) ([]string, KeySource, static.ErrorUtil) {
+	if ci.IndexName != "" {
+		columns, errKey := s.queryColumns(ctx, "get_cdc_index_columns", `
+			SELECT ic.column_name
+			FROM cdc.index_columns ic
+			JOIN cdc.change_tables ct ON ct.object_id = ic.object_id
+			WHERE ct.capture_instance = @p1
+			ORDER BY ic.index_ordinal`, ci.Name)
+		if errKey != nil {
+			return nil, KeySourceNone, errKey
+		}
+		if len(columns) > 0 {
+			return columns, KeySourceCDCIndex, nil
+		}
+	}
+
+	// primary key primeiro; depois índices únicos sem filtro com menos colunas
+	var columns []string
+	var isPrimaryKey bool
+	err := s.withRetry(ctx, "get_index_key_columns", func(ctx context.Context) error {
+		columns = nil
+		rows, err := s.db.QueryContext(ctx, `
+			WITH candidates AS (
+				SELECT TOP (1) i.index_id, i.is_primary_key
+				FROM sys.indexes i
+				WHERE i.object_id = @p1
+				  AND (i.is_primary_key = 1 OR (i.is_unique = 1 AND i.has_filter = 0 AND i.is_disabled = 0))
+				ORDER BY i.is_primary_key DESC, i.is_unique_constraint DESC,
+				         (SELECT COUNT(*) FROM sys.index_columns c
+				          WHERE c.object_id = i.object_id AND c.index_id = i.index_id AND c.is_included_column = 0),
+				         i.index_id
+			)
+			SELECT col.name, cand.is_primary_key
+			FROM candidates cand
+			JOIN sys.index_columns ic ON ic.object_id = @p1 AND ic.index_id = cand.index_id
+			JOIN sys.columns col ON col.object_id = ic.object_id AND col.column_id = ic.column_id
+			WHERE ic.is_included_column = 0
+			ORDER BY ic.key_ordinal`, ci.SourceObjectID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column, &isPrimaryKey); err != nil {
+				return err
+			}
+			columns = append(columns, column)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Str("table", ci.FullTableName()).Msg("Failed to read key metadata")
+		return nil, KeySourceNone, static.NewErrorUtil("Failed to read key metadata", "SQLSERVER_KEY_DISCOVERY_FAILED", err, err.Error())
+	}
+
+	switch {
+	case len(columns) == 0:
+		return nil, KeySourceNone, nil
+	case isPrimaryKey:
+		return columns, KeySourcePrimaryKey, nil
+	}
+	return columns, KeySourceUniqueIndex, nil
+}
+
+// queryColumns executa uma consulta que retorna uma lista de nomes de coluna
+func (s *SQLServer) queryColumns(ctx context.Context, operation string, query string, args ...interface{}) ([]string, static.ErrorUtil) {
+	var columns []string
+	err := s.withRetry(ctx, operation, func(ctx context.Context) error {
+		columns = nil
+		rows, err := s.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				return err
+			}
+			columns = append(columns, column)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Str("operation", operation).Msg("Failed to read column metadata")
+		return nil, static.NewErrorUtil("Failed to read column metadata", "SQLSERVER_COLUMN_METADATA_FAILED", err, err.Error())
+	}
+	return columns, nil
+}
@@ -0,0 +1,60 @@
+// database/sqlserver/lsn.go
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LSNToHex converte um LSN binary(10) para hex maiúsculo de largura fixa
+func LSNToHex(lsn []byte) string {
+	return strings.ToUpper(hex.EncodeToString(lsn))
+}
+
+// LSNFromHex converte o hex de volta para o binary(10) usado nas funções de CDC
+func LSNFromHex(lsn string) ([]byte, error) {
+	return hex.DecodeString(strings.TrimPrefix(strings.ToLower(lsn), "0x"))
+}
+
+// isZeroLSN indica LSN vazio ou 0x000...0 (retornado para capture instance inválida)
+func isZeroLSN(lsn string) bool {
+	return strings.Trim(lsn, "0") == ""
+}
+
+// GetMaxLSN retorna o maior LSN disponível nas change tables ("" se ainda não houver)
+func (s *SQLServer) GetMaxLSN(ctx context.Context) (string, static.ErrorUtil) {
+	var lsn []byte
+	err := s.withRetry(ctx, "get_max_lsn", func(ctx context.Context) error {
+		return s.db.QueryRowContext(ctx, "SELECT sys.fn_cdc_get_max_lsn()").Scan(&lsn)
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to get CDC max LSN")
+		return "", static.NewErrorUtil("Failed to get CDC max LSN", "SQLSERVER_CDC_MAX_LSN_FAILED", err, err.Error())
+	}
+	if isZeroLSN(LSNToHex(lsn)) {
+		return "", nil
+	}
+	return LSNToHex(lsn), nil
+}
+
+// GetMinLSN retorna o menor LSN ainda disponível para a capture instance
+func (s *SQLServer) GetMinLSN(ctx context.Context, captureInstance string) (string, static.ErrorUtil) {
+	var lsn []byte
+	err := s.withRetry(ctx, "get_min_lsn", func(ctx context.Context) error {
+		return s.db.QueryRowContext(ctx, "SELECT sys.fn_cdc_get_min_lsn(@p1)", captureInstance).Scan(&lsn)
+	})
+	if err != nil && err != sql.ErrNoRows {
+		log.Error().Caller().Err(err).Str("capture_instance", captureInstance).Msg("Failed to get CDC min LSN")
+		return "", static.NewErrorUtil("Failed to get CDC min LSN", "SQLSERVER_CDC_MIN_LSN_FAILED", err, err.Error())
+	}
+	if isZeroLSN(LSNToHex(lsn)) {
+		return "", nil
+	}
+	return LSNToHex(lsn), nil
+}
@@ -18,27 +18,76 @@ type connParams struct {
 	DBUser string
 	DBPass string
 	DBName string
+
+	AuthMode                string
+	AzureTenantID           string
+	AzureClientID           string
+	AzureClientSecret       string
+	AzureClientCertPath     string
+	AzureClientCertPassword string
+	AzureFederatedTokenFile string
+	AccessTokenFile         string
+	AccessTokenCommand      string
+
+	ConnString  string // URL sqlserver:// ou connection string ADO completa
+	ExtraParams string // pares chave=valor;... aplicados por último
+
+	// ReadFromSecondary roteia as conexões pelo listener para um secundário legível
+	ReadFromSecondary bool
 }
 
+// GetConnString monta a connection string final. Precedência (menor para maior):
+// connection string completa, campos individuais, parâmetros extras.
 func (c *connParams) GetConnString(showPassword bool) string {
-	password := "******"
-	if showPassword {
-		password = c.DBPass
+	return c.buildParams().String(showPassword)
+}
+
+func (c *connParams) buildParams() *dsnParams {
+	params := newDSNParams()
+
+	// Erros de parse já foram reportados por Validate
+	if c.ConnString != "" {
+		if base, err := parseConnString(c.ConnString); err == nil {
+			params.Merge(base)
+		}
+	}
+
+	if c.DBHost != "" {
+		params.Set("server", c.DBHost)
+	}
+	c.setAuthParams(params)
+	if c.DBPort != "" {
+		params.Set("port", c.DBPort)
+	}
+	if c.DBName != "" {
+		params.Set("database", c.DBName)
 	}
+	if c.ReadFromSecondary {
+		params.Set("applicationintent", "ReadOnly")
+		// reconexão rápida pelo listener após failover em AGs multi-subnet
+		params.SetDefault("multisubnetfailover", "true")
+	}
+	params.SetDefault("encrypt", "true")
+	params.SetDefault("trustservercertificate", "true")
 
-	return "server=" + c.DBHost + ";" +
-		"user id=" + c.DBUser + ";" +
-		"password=" + password + ";" +
-		"port=" + c.DBPort + ";" +
-		"database=" + c.DBName + ";" +
-		"encrypt=" + "true" + ";" +
-		"trustservercertificate=" + "true" + ";"
+	if c.ExtraParams != "" {
+		if extra, err := parseConnStringADO(c.ExtraParams); err == nil {
+			params.Merge(extra)
+		}
+	}
+
+	return params
 }
 
 // SQLServer encapsula a conexão e configuração
 type SQLServer struct {
 	db         *sql.DB
 	connConfig *connParams
+
+	maxIdleConns        int
+	retryMaxAttempts    int
+	retryInitialBackoff time.Duration
+	retryMaxBackoff     time.Duration
 }
 
 // NewSQLServer cria e inicializa uma nova instância (construtor)
@@ -49,11 +98,34 @@ func NewSQLServer(config *config.Config) (*SQLServer, static.ErrorUtil) {
 		DBUser: config.DBUser,
 		DBPass: config.DBPass,
 		DBName: config.DBName,
+
+		AuthMode:                config.DBAuthMode,
+		AzureTenantID:           config.DBAzureTenantID,
+		AzureClientID:           config.DBAzureClientID,
+		AzureClientSecret:       config.DBAzureClientSecret,
+		AzureClientCertPath:     config.DBAzureClientCertPath,
+		AzureClientCertPassword: config.DBAzureClientCertPassword,
+		AzureFederatedTokenFile: config.DBAzureFederatedTokenFile,
+		AccessTokenFile:         config.DBAccessTokenFile,
+		AccessTokenCommand:      config.DBAccessTokenCommand,
+
+		ConnString:  config.DBConnString,
+		ExtraParams: config.DBConnParams,
+
+		ReadFromSecondary: config.DBReadFromSecondary,
+	}
+	if connConfig.AuthMode == "" {
+		connConfig.AuthMode = AuthModeSQL
+	}
+
+	if errAuth := connConfig.Validate(); errAuth != nil {
+		return nil, errAuth
 	}
 
-	log.Info().Msg("Configuring database connection...")
+	log.Info().Msgf("Configuring database connection (auth mode: %s)...", connConfig.AuthMode)
+	log.Debug().Msgf("Connection string: %s", connConfig.GetConnString(false))
 
-	db, err := sql.Open("sqlserver", connConfig.GetConnString(true))
+	db, err := connConfig.openDB()
 	if err != nil {
 		log.Error().Caller().Err(err).Msg("Failed to open database connection")
 		return nil, static.NewErrorUtil("Failed to open database connection", "SQLSERVER_INIT_FAILED", err, err.Error())
@@ -66,9 +138,12 @@ func NewSQLServer(config *config.Config) (*SQLServer, static.ErrorUtil) {
 	db.SetConnMaxIdleTime(time.Duration(config.DBConnMaxIdleTime) * time.Minute)
 
 	sqlServer := &SQLServer{
-		db:         db,
-		connConfig: connConfig,
+		db:           db,
+		connConfig:   connConfig,
+		maxIdleConns: config.DBMaxIdleConns,
 	}
+	sqlServer.retryMaxAttempts, sqlServer.retryInitialBackoff, sqlServer.retryMaxBackoff = retryDefaults(
+		config.DBRetryMaxAttempts, config.DBRetryInitialBackoffMs, config.DBRetryMaxBackoffMs)
 
 	// Verifica conexão inicial
 	log.Info().Msg("Pinging database to verify connection...")
@@ -94,7 +169,10 @@ func (s *SQLServer) HealthCheck(ctx context.Context, timeoutSeconds int) static.
 	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
 	defer cancel()
 
-	if err := s.db.PingContext(ctx); err != nil {
+	err := s.withRetry(ctx, "ping", func(ctx context.Context) error {
+		return s.db.PingContext(ctx)
+	})
+	if err != nil {
 		log.Error().Caller().Err(err).Msg("Database health check failed")
 		return static.NewErrorUtil("Database health check failed", "SQLSERVER_HEALTH_CHECK_FAILED", err, err.Error())
 	}
@@ -0,0 +1,94 @@
+// database/sqlserver/replica.go
+package sqlserver
+
+import (
+	"context"
+
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ReplicaInfo identifica a réplica que está atendendo as conexões do pool
+type ReplicaInfo struct {
+	ServerName    string
+	DatabaseName  string
+	Updateability string // READ_WRITE no primário, READ_ONLY em secundário legível
+}
+
+// IsReadOnly indica se a conexão caiu em um secundário legível
+func (r *ReplicaInfo) IsReadOnly() bool {
+	return r.Updateability == "READ_ONLY"
+}
+
+// LSNStatus resultado da verificação de continuidade de um LSN armazenado
+type LSNStatus int
+
+const (
+	// LSNReachable o LSN está dentro da janela disponível na réplica
+	LSNReachable LSNStatus = iota
+	// LSNAhead a réplica ainda não aplicou (redo) até o LSN armazenado: aguardar
+	LSNAhead
+	// LSNLost o cleanup já removeu alterações posteriores ao LSN: não é possível continuar
+	LSNLost
+)
+
+func (s LSNStatus) String() string {
+	switch s {
+	case LSNReachable:
+		return "reachable"
+	case LSNAhead:
+		return "ahead_of_replica"
+	case LSNLost:
+		return "lost"
+	}
+	return "unknown"
+}
+
+// GetReplicaInfo consulta em qual servidor/réplica a conexão atual está
+func (s *SQLServer) GetReplicaInfo(ctx context.Context) (*ReplicaInfo, static.ErrorUtil) {
+	info := &ReplicaInfo{}
+	err := s.withRetry(ctx, "get_replica_info", func(ctx context.Context) error {
+		return s.db.QueryRowContext(ctx, `
+			SELECT
+				CAST(@@SERVERNAME AS nvarchar(256)),
+				DB_NAME(),
+				CAST(DATABASEPROPERTYEX(DB_NAME(), 'Updateability') AS nvarchar(32))`).
+			Scan(&info.ServerName, &info.DatabaseName, &info.Updateability)
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to get replica information")
+		return nil, static.NewErrorUtil("Failed to get replica information", "SQLSERVER_REPLICA_INFO_FAILED", err, err.Error())
+	}
+	return info, nil
+}
+
+// CheckLSNReachable verifica se a leitura pode continuar a partir do LSN armazenado
+// na réplica atual. Um LSN à frente do max LSN indica redo lag no secundário e
+// nunca deve ser tratado como "pular adiante". Para uma tabela sem alterações o
+// LSN armazenado é a marca d'água de leitura, não o do último evento.
+func (s *SQLServer) CheckLSNReachable(ctx context.Context, captureInstance string, lsn string) (LSNStatus, static.ErrorUtil) {
+	minLSN, errMin := s.GetMinLSN(ctx, captureInstance)
+	if errMin != nil {
+		return LSNLost, errMin
+	}
+	maxLSN, errMax := s.GetMaxLSN(ctx)
+	if errMax != nil {
+		return LSNLost, errMax
+	}
+	return lsnStatus(lsn, minLSN, maxLSN), nil
+}
+
+// lsnStatus compara o LSN armazenado com a faixa [min_lsn, max_lsn] da réplica
+func lsnStatus(lsn string, minLSN string, maxLSN string) LSNStatus {
+	switch {
+	case maxLSN == "" || lsn > maxLSN:
+		return LSNAhead
+	case minLSN == "":
+		// capture instance ainda não existe nesta réplica (redo pendente)
+		return LSNAhead
+	case lsn < minLSN:
+		return LSNLost
+	}
+	return LSNReachable
+}
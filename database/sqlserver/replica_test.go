@@ -0,0 +1,32 @@
+package sqlserver
+
+import "testing"
+
+func TestLSNStatus(t *testing.T) {
+	const (
+		minLSN = "00000000000000000050"
+		maxLSN = "00000000000000000100"
+	)
+	tests := []struct {
+		name   string
+		lsn    string
+		minLSN string
+		want   LSNStatus
+	}{
+		{"within retention", "00000000000000000060", minLSN, LSNReachable},
+		{"at min lsn", minLSN, minLSN, LSNReachable},
+		// tabela sem alterações: a marca d'água de leitura acompanha o max LSN
+		{"quiet table watermark", maxLSN, minLSN, LSNReachable},
+		{"behind cleanup", "00000000000000000040", minLSN, LSNLost},
+		{"redo lag", "00000000000000000200", minLSN, LSNAhead},
+		{"capture instance not on replica", "00000000000000000060", "", LSNAhead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lsnStatus(tt.lsn, tt.minLSN, maxLSN); got != tt.want {
+				t.Errorf("lsnStatus(%s, %s, %s) = %v, want %v", tt.lsn, tt.minLSN, maxLSN, got, tt.want)
+			}
+		})
+	}
+}
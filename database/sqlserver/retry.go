@@ -0,0 +1,174 @@
+// database/sqlserver/retry.go
+package sqlserver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"go-cdc/internal/retry"
+
+	mssql "github.com/microsoft/go-mssqldb"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrorClass classificação de um erro do SQL Server para fins de retry
+type ErrorClass int
+
+const (
+	// ErrorUnknown erros não classificados (ex.: sintaxe) não são repetidos
+	ErrorUnknown ErrorClass = iota
+	// ErrorTransient falhas temporárias: deadlock, throttling, failover, rede
+	ErrorTransient
+	// ErrorFatal falhas que não se resolvem com retry: login, permissão
+	ErrorFatal
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorTransient:
+		return "transient"
+	case ErrorFatal:
+		return "fatal"
+	}
+	return "unknown"
+}
+
+// Números de erro transitórios (deadlock, lock timeout, Azure SQL throttling/reconfiguração, AG em failover)
+var transientErrorNumbers = map[int32]bool{
+	1205:  true, // deadlock victim
+	1222:  true, // lock request time out
+	233:   true, // connection closed by server
+	4060:  true, // cannot open database (comum durante failover)
+	976:   true, // database not accessible on secondary replica
+	983:   true, // availability database not accessible
+	10053: true, // transport-level error
+	10054: true, // connection reset by peer
+	10060: true, // network timeout
+	10928: true, // resource limit reached
+	10929: true, // resource governance
+	40143: true,
+	40197: true, // service error processing request
+	40501: true, // service busy
+	40613: true, // database unavailable
+	49918: true,
+	49919: true,
+	49920: true,
+}
+
+// Números de erro fatais (credenciais e permissões)
+var fatalErrorNumbers = map[int32]bool{
+	18456: true, // login failed
+	18452: true, // untrusted domain login
+	229:   true, // permission denied on object
+	230:   true, // permission denied on column
+	262:   true, // permission denied in database
+	297:   true, // user does not have permission
+	300:   true, // VIEW SERVER STATE permission denied
+	916:   true, // server principal cannot access database
+}
+
+// ClassifyError identifica se o erro deve ser repetido
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorUnknown
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorFatal
+	}
+
+	var sqlErr mssql.Error
+	if errors.As(err, &sqlErr) {
+		switch {
+		case fatalErrorNumbers[sqlErr.Number]:
+			return ErrorFatal
+		case transientErrorNumbers[sqlErr.Number]:
+			return ErrorTransient
+		}
+		return ErrorUnknown
+	}
+
+	var netErr net.Error
+	var streamErr mssql.StreamError
+	switch {
+	case errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNABORTED),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &netErr),
+		errors.As(err, &streamErr):
+		return ErrorTransient
+	}
+
+	return ErrorUnknown
+}
+
+// isConnectionError indica falhas de rede, que invalidam conexões ociosas do pool
+func isConnectionError(err error) bool {
+	var sqlErr mssql.Error
+	return ClassifyError(err) == ErrorTransient && !errors.As(err, &sqlErr)
+}
+
+// withRetry executa fn repetindo erros transitórios com backoff exponencial limitado
+func (s *SQLServer) withRetry(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	backoff := retry.NewBackoff(s.retryInitialBackoff, s.retryMaxBackoff)
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		class := ClassifyError(err)
+		if class != ErrorTransient || attempt+1 >= s.retryMaxAttempts || ctx.Err() != nil {
+			return err
+		}
+
+		wait := backoff.Duration(attempt)
+		log.Warn().
+			Err(err).
+			Str("operation", operation).
+			Str("error_class", class.String()).
+			Int("attempt", attempt+1).
+			Int("max_attempts", s.retryMaxAttempts).
+			Dur("backoff", wait).
+			Msg("Transient database error, retrying")
+
+		if isConnectionError(err) {
+			s.resetIdleConnections()
+		}
+		if errSleep := retry.Sleep(ctx, wait); errSleep != nil {
+			return err
+		}
+	}
+}
+
+// resetIdleConnections descarta as conexões ociosas para que as próximas sejam
+// abertas novamente pelo listener (ex.: após failover)
+func (s *SQLServer) resetIdleConnections() {
+	s.db.SetMaxIdleConns(0)
+	s.db.SetMaxIdleConns(s.maxIdleConns)
+	log.Info().Msg("Idle database connections discarded, reconnecting")
+}
+
+// retryDefaults garante valores mínimos válidos para a política de retry
+func retryDefaults(maxAttempts int, initialMs int, maxMs int) (int, time.Duration, time.Duration) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if initialMs <= 0 {
+		initialMs = 100
+	}
+	if maxMs < initialMs {
+		maxMs = initialMs
+	}
+	return maxAttempts, time.Duration(initialMs) * time.Millisecond, time.Duration(maxMs) * time.Millisecond
+}
@@ -0,0 +1,177 @@
+// database/sqlserver/schema.go
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go-cdc/internal/schema"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GetTableColumns lê a definição atual das colunas da tabela de origem
+func (s *SQLServer) GetTableColumns(ctx context.Context, objectID int) ([]schema.Column, static.ErrorUtil) {
+	var columns []schema.Column
+	err := s.withRetry(ctx, "get_table_columns", func(ctx context.Context) error {
+		columns = nil
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT c.name, TYPE_NAME(c.user_type_id), c.max_length, c.precision, c.scale,
+			       c.is_nullable, ROW_NUMBER() OVER (ORDER BY c.column_id), c.collation_name,
+			       c.is_computed, ISNULL(cc.is_persisted, 0), cc.definition, c.is_sparse,
+			       c.is_identity, CONVERT(nvarchar(40), ic.seed_value), CONVERT(nvarchar(40), ic.increment_value),
+			       c.is_rowguidcol, dc.definition,
+			       CASE c.generated_always_type WHEN 1 THEN 'row_start' WHEN 2 THEN 'row_end' ELSE '' END
+			FROM sys.columns c
+			LEFT JOIN sys.computed_columns cc ON cc.object_id = c.object_id AND cc.column_id = c.column_id
+			LEFT JOIN sys.identity_columns ic ON ic.object_id = c.object_id AND ic.column_id = c.column_id
+			LEFT JOIN sys.default_constraints dc ON dc.object_id = c.default_object_id
+			WHERE c.object_id = @p1
+			ORDER BY c.column_id`, objectID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var col schema.Column
+			var maxLength, precision, scale, ordinal int64
+			var collation, computedDefinition, seed, increment, defaultDefinition sql.NullString
+			if err := rows.Scan(&col.Name, &col.Type, &maxLength, &precision, &scale,
+				&col.Nullable, &ordinal, &collation,
+				&col.Computed, &col.Persisted, &computedDefinition, &col.Sparse,
+				&col.Identity, &seed, &increment,
+				&col.RowGUIDCol, &defaultDefinition, &col.GeneratedAlways); err != nil {
+				return err
+			}
+			col.MaxLength, col.Precision, col.Scale, col.Ordinal = int(maxLength), int(precision), int(scale), int(ordinal)
+			col.Collation = collation.String
+			col.ComputedDefinition = computedDefinition.String
+			col.IdentitySeed, col.IdentityIncrement = seed.String, increment.String
+			col.Default = defaultDefinition.String
+			columns = append(columns, col)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Int("object_id", objectID).Msg("Failed to read table columns")
+		return nil, static.NewErrorUtil("Failed to read table columns", "SQLSERVER_TABLE_COLUMNS_FAILED", err, err.Error())
+	}
+	return columns, nil
+}
+
+// GetLatestDDLLSNs retorna o LSN do último DDL registrado em cdc.ddl_history por tabela de origem
+func (s *SQLServer) GetLatestDDLLSNs(ctx context.Context) (map[int]string, static.ErrorUtil) {
+	result := make(map[int]string)
+	err := s.withRetry(ctx, "get_ddl_history", func(ctx context.Context) error {
+		clear(result)
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT source_object_id, MAX(ddl_lsn)
+			FROM cdc.ddl_history
+			GROUP BY source_object_id`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var objectID int
+			var lsn []byte
+			if err := rows.Scan(&objectID, &lsn); err != nil {
+				return err
+			}
+			result[objectID] = LSNToHex(lsn)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to read CDC DDL history")
+		return nil, static.NewErrorUtil("Failed to read CDC DDL history", "SQLSERVER_DDL_HISTORY_FAILED", err, err.Error())
+	}
+	return result, nil
+}
+
+// DDLCommand instrução registrada em cdc.ddl_history
+type DDLCommand struct {
+	LSN     string
+	Command string
+	Time    time.Time
+}
+
+// GetDDLCommands lista os DDLs da tabela de origem com LSN maior que afterLSN, em ordem
+func (s *SQLServer) GetDDLCommands(ctx context.Context, objectID int, afterLSN string) ([]DDLCommand, static.ErrorUtil) {
+	after := zeroLSN
+	if afterLSN != "" {
+		var err error
+		if after, err = LSNFromHex(afterLSN); err != nil {
+			return nil, static.NewErrorUtil("Failed to read CDC DDL history", "SQLSERVER_DDL_HISTORY_FAILED", err, err.Error())
+		}
+	}
+
+	var commands []DDLCommand
+	err := s.withRetry(ctx, "get_ddl_commands", func(ctx context.Context) error {
+		commands = nil
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT ddl_lsn, ddl_command, ddl_time
+			FROM cdc.ddl_history
+			WHERE source_object_id = @p1 AND ddl_lsn > @p2
+			ORDER BY ddl_lsn`, objectID, after)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var cmd DDLCommand
+			var lsn []byte
+			var ddlTime sql.NullTime
+			if err := rows.Scan(&lsn, &cmd.Command, &ddlTime); err != nil {
+				return err
+			}
+			cmd.LSN = LSNToHex(lsn)
+			cmd.Time = ddlTime.Time
+			commands = append(commands, cmd)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Int("object_id", objectID).Msg("Failed to read CDC DDL history")
+		return nil, static.NewErrorUtil("Failed to read CDC DDL history", "SQLSERVER_DDL_HISTORY_FAILED", err, err.Error())
+	}
+	return commands, nil
+}
+
+// GetRowCounts retorna a contagem de linhas (metadados de sys.partitions, sem varrer a tabela) por object_id
+func (s *SQLServer) GetRowCounts(ctx context.Context) (map[int]int64, static.ErrorUtil) {
+	result := make(map[int]int64)
+	err := s.withRetry(ctx, "get_row_counts", func(ctx context.Context) error {
+		clear(result)
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT p.object_id, SUM(p.rows)
+			FROM sys.partitions p
+			WHERE p.index_id IN (0, 1)
+			  AND p.object_id IN (SELECT source_object_id FROM cdc.change_tables)
+			GROUP BY p.object_id`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var objectID int
+			var count int64
+			if err := rows.Scan(&objectID, &count); err != nil {
+				return err
+			}
+			result[objectID] = count
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to read table row counts")
+		return nil, static.NewErrorUtil("Failed to read table row counts", "SQLSERVER_ROW_COUNTS_FAILED", err, err.Error())
+	}
+	return result, nil
+}
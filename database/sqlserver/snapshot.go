@@ -0,0 +1,107 @@
+// database/sqlserver/snapshot.go
+package sqlserver
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SnapshotChunkQuery parâmetros de leitura de um bloco de snapshot por keyset
+type SnapshotChunkQuery struct {
+	Schema     string
+	Table      string
+	Columns    []string      // colunas selecionadas (capturadas)
+	KeyColumns []string      // ordenação e paginação
+	AfterKey   []interface{} // valores da chave da última linha lida (nil = início)
+	Filter     string        // predicado SQL opcional aplicado no WHERE
+	Limit      int
+}
+
+// ReadSnapshotChunk lê até Limit linhas com chave maior que AfterKey, em ordem de chave
+func (s *SQLServer) ReadSnapshotChunk(ctx context.Context, q SnapshotChunkQuery) ([]map[string]interface{}, static.ErrorUtil) {
+	quoted := make([]string, len(q.Columns))
+	for i, column := range q.Columns {
+		quoted[i] = QuoteIdentifier(column)
+	}
+	orderBy := make([]string, len(q.KeyColumns))
+	for i, column := range q.KeyColumns {
+		orderBy[i] = QuoteIdentifier(column)
+	}
+
+	args := []interface{}{q.Limit}
+	var where []string
+	if q.Filter != "" {
+		where = append(where, "("+q.Filter+")")
+	}
+	if q.AfterKey != nil {
+		where = append(where, keysetCondition(orderBy, q.AfterKey, &args))
+	}
+
+	query := "SELECT TOP (@p1) " + strings.Join(quoted, ", ") +
+		" FROM " + QuoteIdentifier(q.Schema) + "." + QuoteIdentifier(q.Table)
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY " + strings.Join(orderBy, ", ")
+
+	var result []map[string]interface{}
+	err := s.withRetry(ctx, "read_snapshot_chunk", func(ctx context.Context) error {
+		result = nil
+		rows, err := s.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		columnTypes, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			values := make([]interface{}, len(columnTypes))
+			pointers := make([]interface{}, len(columnTypes))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err := rows.Scan(pointers...); err != nil {
+				return err
+			}
+			row := make(map[string]interface{}, len(columnTypes))
+			for i, ct := range columnTypes {
+				row[ct.Name()] = normalizeValue(ct.DatabaseTypeName(), values[i])
+			}
+			result = append(result, row)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Str("table", q.Schema+"."+q.Table).Msg("Failed to read snapshot chunk")
+		return nil, static.NewErrorUtil("Failed to read snapshot chunk", "SQLSERVER_SNAPSHOT_READ_FAILED", err, err.Error())
+	}
+	return result, nil
+}
+
+// keysetCondition monta (k1 > @a1) OR (k1 = @a1 AND k2 > @a2) OR ... para
+// paginação por chave; columns já delimitadas, parâmetros anexados a args
+func keysetCondition(columns []string, after []interface{}, args *[]interface{}) string {
+	var alternatives []string
+	for i := range columns {
+		var terms []string
+		for j := 0; j <= i; j++ {
+			op := "="
+			if j == i {
+				op = ">"
+			}
+			*args = append(*args, after[j])
+			terms = append(terms, columns[j]+" "+op+" @p"+strconv.Itoa(len(*args)))
+		}
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	return "(" + strings.Join(alternatives, " OR ") + ")"
+}
@@ -0,0 +1,207 @@
+// database/sqlserver/temporal.go
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"go-cdc/internal/schema"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Colunas auxiliares da leitura de histórico temporal
+const (
+	temporalAtColumn = "__go_cdc_at"
+	temporalOpColumn = "__go_cdc_op"
+)
+
+// Operações derivadas das versões de uma tabela temporal
+const (
+	TemporalInsert = "c"
+	TemporalDelete = "d"
+	TemporalUpdate = "u"
+)
+
+// GetTemporalInfo retorna a tabela de histórico e as colunas de período (nil se a tabela não for system-versioned)
+func (s *SQLServer) GetTemporalInfo(ctx context.Context, objectID int) (*schema.Temporal, static.ErrorUtil) {
+	var info *schema.Temporal
+	err := s.withRetry(ctx, "get_temporal_info", func(ctx context.Context) error {
+		var historySchema, historyTable, periodStart, periodEnd string
+		err := s.db.QueryRowContext(ctx, `
+			SELECT hs.name, ht.name, sc.name, ec.name
+			FROM sys.tables t
+			JOIN sys.tables ht ON ht.object_id = t.history_table_id
+			JOIN sys.schemas hs ON hs.schema_id = ht.schema_id
+			JOIN sys.periods p ON p.object_id = t.object_id
+			JOIN sys.columns sc ON sc.object_id = t.object_id AND sc.column_id = p.start_column_id
+			JOIN sys.columns ec ON ec.object_id = t.object_id AND ec.column_id = p.end_column_id
+			WHERE t.object_id = @p1 AND t.temporal_type = 2`, objectID).
+			Scan(&historySchema, &historyTable, &periodStart, &periodEnd)
+		if err == sql.ErrNoRows {
+			info = nil
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		info = &schema.Temporal{
+			HistoryTable: historySchema + "." + historyTable,
+			PeriodStart:  periodStart,
+			PeriodEnd:    periodEnd,
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Int("object_id", objectID).Msg("Failed to read temporal table metadata")
+		return nil, static.NewErrorUtil("Failed to read temporal table metadata", "SQLSERVER_TEMPORAL_INFO_FAILED", err, err.Error())
+	}
+	return info, nil
+}
+
+// GetCDCHistoryBoundary retorna, em UTC, o instante da alteração mais antiga
+// ainda disponível na capture instance. Versões temporais anteriores a ele não
+// estão no CDC. lsn_time_mapping usa o horário local do servidor, convertido
+// pelo deslocamento atual. Sem alterações disponíveis retorna o instante atual.
+func (s *SQLServer) GetCDCHistoryBoundary(ctx context.Context, captureInstance string) (time.Time, static.ErrorUtil) {
+	var boundary time.Time
+	err := s.withRetry(ctx, "get_cdc_history_boundary", func(ctx context.Context) error {
+		return s.db.QueryRowContext(ctx, `
+			SELECT ISNULL(
+				DATEADD(minute, DATEDIFF(minute, SYSDATETIME(), SYSUTCDATETIME()),
+				        sys.fn_cdc_map_lsn_to_time(sys.fn_cdc_get_min_lsn(@p1))),
+				SYSUTCDATETIME())`, captureInstance).Scan(&boundary)
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Str("capture_instance", captureInstance).Msg("Failed to read CDC history boundary")
+		return time.Time{}, static.NewErrorUtil("Failed to read CDC history boundary", "SQLSERVER_CDC_BOUNDARY_FAILED", err, err.Error())
+	}
+	return boundary.UTC(), nil
+}
+
+// TemporalHistoryQuery parâmetros de leitura do histórico de uma tabela temporal
+type TemporalHistoryQuery struct {
+	Schema      string
+	Table       string
+	Columns     []string
+	KeyColumns  []string
+	PeriodStart string
+	PeriodEnd   string
+	Before      time.Time     // apenas alterações anteriores a este instante (UTC)
+	After       []interface{} // posição da última alteração lida: at, op, chave...
+	Limit       int
+}
+
+// TemporalChange alteração reconstruída a partir das versões da linha
+type TemporalChange struct {
+	Op  string // TemporalInsert, TemporalUpdate ou TemporalDelete
+	At  time.Time
+	Row map[string]interface{}
+}
+
+// ReadTemporalHistoryChunk reconstrói as alterações a partir de FOR SYSTEM_TIME ALL.
+// Cada versão gera um insert (sem versão anterior contígua) ou update no início
+// do período; uma versão encerrada sem sucessora contígua gera um delete no fim
+// do período. As alterações vêm em ordem de tempo e continuam após After.
+func (s *SQLServer) ReadTemporalHistoryChunk(ctx context.Context, q TemporalHistoryQuery) ([]TemporalChange, static.ErrorUtil) {
+	quote := func(names []string) []string {
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = QuoteIdentifier(name)
+		}
+		return quoted
+	}
+	columns := quote(q.Columns)
+	keys := quote(q.KeyColumns)
+	start, end := QuoteIdentifier(q.PeriodStart), QuoteIdentifier(q.PeriodEnd)
+	partition := "PARTITION BY " + strings.Join(keys, ", ") + " ORDER BY " + start
+
+	selectList := strings.Join(columns, ", ")
+	for _, period := range []string{q.PeriodStart, q.PeriodEnd} {
+		if !containsFold(q.Columns, period) {
+			selectList += ", " + QuoteIdentifier(period)
+		}
+	}
+
+	args := []interface{}{q.Limit, q.Before}
+	where := ""
+	if q.After != nil {
+		order := append([]string{temporalAtColumn, temporalOpColumn}, keys...)
+		where = " WHERE " + keysetCondition(order, q.After, &args)
+	}
+
+	query := `
+		WITH versions AS (
+			SELECT ` + selectList + `,
+			       LAG(` + end + `) OVER (` + partition + `) AS __go_cdc_prev_end,
+			       LEAD(` + start + `) OVER (` + partition + `) AS __go_cdc_next_start
+			FROM ` + QuoteIdentifier(q.Schema) + `.` + QuoteIdentifier(q.Table) + ` FOR SYSTEM_TIME ALL
+		), changes AS (
+			SELECT versions.*, ` + start + ` AS ` + temporalAtColumn + `,
+			       CASE WHEN __go_cdc_prev_end = ` + start + ` THEN 'u' ELSE 'c' END AS ` + temporalOpColumn + `
+			FROM versions WHERE ` + start + ` < @p2
+			UNION ALL
+			SELECT versions.*, ` + end + `, 'd'
+			FROM versions
+			WHERE ` + end + ` < @p2 AND (__go_cdc_next_start IS NULL OR __go_cdc_next_start > ` + end + `)
+		)
+		SELECT TOP (@p1) * FROM changes` + where + `
+		ORDER BY ` + temporalAtColumn + `, ` + temporalOpColumn + `, ` + strings.Join(keys, ", ")
+
+	var changes []TemporalChange
+	err := s.withRetry(ctx, "read_temporal_history", func(ctx context.Context) error {
+		changes = nil
+		rows, err := s.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		columnTypes, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			values := make([]interface{}, len(columnTypes))
+			pointers := make([]interface{}, len(columnTypes))
+			for i := range values {
+				pointers[i] = &values[i]
+			}
+			if err := rows.Scan(pointers...); err != nil {
+				return err
+			}
+
+			change := TemporalChange{Row: make(map[string]interface{}, len(q.Columns))}
+			for i, ct := range columnTypes {
+				switch name := ct.Name(); {
+				case name == temporalAtColumn:
+					change.At, _ = values[i].(time.Time)
+				case name == temporalOpColumn:
+					change.Op, _ = values[i].(string)
+				case containsFold(q.Columns, name):
+					change.Row[name] = normalizeValue(ct.DatabaseTypeName(), values[i])
+				}
+			}
+			changes = append(changes, change)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Str("table", q.Schema+"."+q.Table).Msg("Failed to read temporal history")
+		return nil, static.NewErrorUtil("Failed to read temporal history", "SQLSERVER_TEMPORAL_READ_FAILED", err, err.Error())
+	}
+	return changes, nil
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
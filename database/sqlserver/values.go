@@ -0,0 +1,28 @@
+// database/sqlserver/values.go
+package sqlserver
+
+import (
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+// normalizeValue converte valores retornados pelo driver para tipos serializáveis.
+// decimal/money chegam como []byte textual e uniqueidentifier na ordem de bytes do SQL Server.
+func normalizeValue(databaseType string, value interface{}) interface{} {
+	raw, ok := value.([]byte)
+	if !ok {
+		return value
+	}
+
+	switch databaseType {
+	case "DECIMAL", "NUMERIC", "MONEY", "SMALLMONEY":
+		return string(raw)
+	case "UNIQUEIDENTIFIER":
+		var uid mssql.UniqueIdentifier
+		if err := uid.Scan(raw); err == nil {
+			return uid.String()
+		}
+	}
+
+	// copia: o driver pode reutilizar o buffer entre linhas
+	return append([]byte(nil), raw...)
+}
@@ -0,0 +1,336 @@
+// internal/admin/cdc.go
+package admin
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-cdc/database"
+	"go-cdc/database/sqlserver"
+	"go-cdc/internal/config"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// adminTimeout tempo máximo para cada comando administrativo
+const adminTimeout = 5 * time.Minute
+
+const cdcUsage = `Usage: go-cdc cdc <command> [flags]
+
+Commands:
+  enable   Enable CDC on the database (if needed) and on a table
+  disable  Disable CDC on a table or on the whole database
+  status   Show CDC status of the database and capture instances
+
+Run "go-cdc cdc <command> -h" for command flags.
+`
+
+// RunCDC executa "go-cdc cdc <enable|disable|status>" e retorna o exit code
+func RunCDC(args []string, cfg *config.Config) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, cdcUsage)
+		return 2
+	}
+
+	var errCmd static.ErrorUtil
+	switch args[0] {
+	case "enable":
+		errCmd = runEnable(args[1:], cfg, os.Stdout)
+	case "disable":
+		errCmd = runDisable(args[1:], cfg, os.Stdout)
+	case "status":
+		errCmd = runStatus(args[1:], cfg, os.Stdout)
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stdout, cdcUsage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "unknown cdc command %q\n\n%s", args[0], cdcUsage)
+		return 2
+	}
+
+	if errCmd != nil {
+		if errCmd.Code() == errHelp.Code() {
+			return 0
+		}
+		if errCmd.Code() == errUsage.Code() {
+			return 2
+		}
+		log.Error().Err(errCmd).Str("code", errCmd.Code()).Msg("CDC admin command failed")
+		return 1
+	}
+	return 0
+}
+
+var (
+	errUsage = static.NewErrorUtil("Invalid command usage", "ADMIN_USAGE", nil, "")
+	errHelp  = static.NewErrorUtil("Help requested", "ADMIN_HELP", nil, "")
+)
+
+// parseFlags trata -h/-help como sucesso (o flag package já imprimiu o uso)
+func parseFlags(fs *flag.FlagSet, args []string) static.ErrorUtil {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errHelp
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// primaryConfig copia a configuração sem ApplicationIntent=ReadOnly: as
+// procedures sp_cdc_* falham em conexões roteadas para um secundário
+func primaryConfig(cfg *config.Config) *config.Config {
+	adminCfg := *cfg
+	adminCfg.DBReadFromSecondary = false
+	return &adminCfg
+}
+
+func runEnable(args []string, cfg *config.Config, out io.Writer) static.ErrorUtil {
+	fs := flag.NewFlagSet("cdc enable", flag.ContinueOnError)
+	table := fs.String("table", "", "source table as schema.table (required unless -db-only)")
+	role := fs.String("role", "", "gating role for change data access (empty = no gating role)")
+	filegroup := fs.String("filegroup", "", "filegroup for the change table")
+	captureInstance := fs.String("capture-instance", "", "capture instance name (default schema_table)")
+	index := fs.String("index", "", "unique index used to identify rows (required for tables without primary key and net changes)")
+	columns := fs.String("columns", "", "comma separated captured column list (default all columns)")
+	netChanges := fs.Bool("net-changes", false, "enable net changes support")
+	dbOnly := fs.Bool("db-only", false, "only enable CDC on the database")
+	dryRun := fs.Bool("dry-run", false, "print the T-SQL instead of executing it")
+	if errParse := parseFlags(fs, args); errParse != nil {
+		return errParse
+	}
+
+	var statements []string
+	statements = append(statements, enableDatabaseSQL())
+
+	if !*dbOnly {
+		schemaName, tableName, ok := splitTableName(*table)
+		if !ok {
+			fmt.Fprintln(os.Stderr, "-table is required in the form schema.table")
+			fs.Usage()
+			return errUsage
+		}
+		statements = append(statements, enableTableSQL(enableTableOptions{
+			Schema:          schemaName,
+			Table:           tableName,
+			Role:            *role,
+			Filegroup:       *filegroup,
+			CaptureInstance: *captureInstance,
+			Index:           *index,
+			Columns:         *columns,
+			NetChanges:      *netChanges,
+		}))
+	}
+
+	return execute(cfg, statements, *dryRun, out)
+}
+
+func runDisable(args []string, cfg *config.Config, out io.Writer) static.ErrorUtil {
+	fs := flag.NewFlagSet("cdc disable", flag.ContinueOnError)
+	table := fs.String("table", "", "source table as schema.table")
+	captureInstance := fs.String("capture-instance", "all", "capture instance to disable (all = every instance of the table)")
+	wholeDatabase := fs.Bool("database", false, "disable CDC on the whole database (drops all capture instances)")
+	dryRun := fs.Bool("dry-run", false, "print the T-SQL instead of executing it")
+	if errParse := parseFlags(fs, args); errParse != nil {
+		return errParse
+	}
+
+	var statements []string
+	switch {
+	case *wholeDatabase:
+		statements = append(statements, "EXEC sys.sp_cdc_disable_db;")
+	default:
+		schemaName, tableName, ok := splitTableName(*table)
+		if !ok {
+			fmt.Fprintln(os.Stderr, "-table is required in the form schema.table (or use -database)")
+			fs.Usage()
+			return errUsage
+		}
+		statements = append(statements, fmt.Sprintf(
+			"EXEC sys.sp_cdc_disable_table @source_schema = %s, @source_name = %s, @capture_instance = %s;",
+			quoteLiteral(schemaName), quoteLiteral(tableName), quoteLiteral(*captureInstance)))
+	}
+
+	return execute(cfg, statements, *dryRun, out)
+}
+
+func runStatus(args []string, cfg *config.Config, out io.Writer) static.ErrorUtil {
+	fs := flag.NewFlagSet("cdc status", flag.ContinueOnError)
+	if errParse := parseFlags(fs, args); errParse != nil {
+		return errParse
+	}
+
+	dbManager, errDb := database.Init(primaryConfig(cfg))
+	if errDb != nil {
+		return errDb
+	}
+	defer dbManager.Close()
+	sqlServer := dbManager.GetSQLServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), adminTimeout)
+	defer cancel()
+
+	enabled, errEnabled := sqlServer.IsCDCEnabled(ctx)
+	if errEnabled != nil {
+		return errEnabled
+	}
+	replica, errReplica := sqlServer.GetReplicaInfo(ctx)
+	if errReplica != nil {
+		return errReplica
+	}
+
+	fmt.Fprintf(out, "Server:       %s\n", replica.ServerName)
+	fmt.Fprintf(out, "Database:     %s\n", replica.DatabaseName)
+	fmt.Fprintf(out, "CDC enabled:  %t\n", enabled)
+	if !enabled {
+		return nil
+	}
+
+	maxLSN, errMax := sqlServer.GetMaxLSN(ctx)
+	if errMax != nil {
+		return errMax
+	}
+	fmt.Fprintf(out, "Max LSN:      %s\n\n", maxLSN)
+
+	instances, errList := sqlServer.ListCaptureInstances(ctx)
+	if errList != nil {
+		return errList
+	}
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tCAPTURE INSTANCE\tINDEX\tNET CHANGES\tSTART LSN\tMIN LSN")
+	for i := range instances {
+		ci := &instances[i]
+		minLSN, errMin := sqlServer.GetMinLSN(ctx, ci.Name)
+		if errMin != nil {
+			return errMin
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n",
+			ci.FullTableName(), ci.Name, valueOrDash(ci.IndexName), ci.SupportsNetChanges, ci.StartLSN, valueOrDash(minLSN))
+	}
+	if err := tw.Flush(); err != nil {
+		return static.NewErrorUtil("Failed to write CDC status", "ADMIN_OUTPUT_FAILED", err, err.Error())
+	}
+	return nil
+}
+
+// execute imprime (dry-run) ou executa as instruções em ordem
+func execute(cfg *config.Config, statements []string, dryRun bool, out io.Writer) static.ErrorUtil {
+	if dryRun {
+		fmt.Fprintln(out, "-- go-cdc dry-run: statements are not executed")
+		if cfg.DBName != "" {
+			fmt.Fprintf(out, "USE %s;\nGO\n", sqlserver.QuoteIdentifier(cfg.DBName))
+		}
+		for _, stmt := range statements {
+			fmt.Fprintln(out, stmt)
+			fmt.Fprintln(out, "GO")
+		}
+		return nil
+	}
+
+	dbManager, errDb := database.Init(primaryConfig(cfg))
+	if errDb != nil {
+		return errDb
+	}
+	defer dbManager.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), adminTimeout)
+	defer cancel()
+
+	for _, stmt := range statements {
+		log.Info().Str("statement", stmt).Msg("Executing CDC admin statement")
+		if errExec := dbManager.GetSQLServer().Exec(ctx, stmt); errExec != nil {
+			return errExec
+		}
+	}
+	fmt.Fprintln(out, "OK")
+	return nil
+}
+
+type enableTableOptions struct {
+	Schema          string
+	Table           string
+	Role            string
+	Filegroup       string
+	CaptureInstance string
+	Index           string
+	Columns         string
+	NetChanges      bool
+}
+
+// enableDatabaseSQL habilita CDC no banco apenas se ainda não estiver habilitado
+func enableDatabaseSQL() string {
+	return "IF (SELECT is_cdc_enabled FROM sys.databases WHERE database_id = DB_ID()) = 0\n" +
+		"    EXEC sys.sp_cdc_enable_db;"
+}
+
+func enableTableSQL(opts enableTableOptions) string {
+	// @role_name é obrigatório: NULL desabilita o gating role
+	role := "NULL"
+	if opts.Role != "" {
+		role = quoteLiteral(opts.Role)
+	}
+
+	params := []string{
+		"@source_schema = " + quoteLiteral(opts.Schema),
+		"@source_name = " + quoteLiteral(opts.Table),
+		"@role_name = " + role,
+	}
+	if opts.Filegroup != "" {
+		params = append(params, "@filegroup_name = "+quoteLiteral(opts.Filegroup))
+	}
+	if opts.CaptureInstance != "" {
+		params = append(params, "@capture_instance = "+quoteLiteral(opts.CaptureInstance))
+	}
+	if opts.Index != "" {
+		params = append(params, "@index_name = "+quoteLiteral(opts.Index))
+	}
+	if opts.Columns != "" {
+		var quoted []string
+		for _, column := range config.SplitList(opts.Columns) {
+			quoted = append(quoted, sqlserver.QuoteIdentifier(column))
+		}
+		params = append(params, "@captured_column_list = "+quoteLiteral(strings.Join(quoted, ", ")))
+	}
+	if opts.NetChanges {
+		params = append(params, "@supports_net_changes = 1")
+	}
+
+	return "EXEC sys.sp_cdc_enable_table\n    " + strings.Join(params, ",\n    ") + ";"
+}
+
+// quoteLiteral gera um literal N'...' escapando aspas simples
+func quoteLiteral(value string) string {
+	return "N'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// splitTableName separa "schema.tabela"; sem schema assume dbo
+func splitTableName(name string) (string, string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", "", false
+	}
+	schemaName, tableName, found := strings.Cut(name, ".")
+	if !found {
+		return "dbo", name, true
+	}
+	if schemaName == "" || tableName == "" {
+		return "", "", false
+	}
+	return schemaName, tableName, true
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,80 @@
+// internal/blob/blob.go
+package blob
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Store armazena valores grandes fora da mensagem e devolve uma referência
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) (string, static.ErrorUtil)
+}
+
+// FileStore grava os valores em um diretório local (ou volume montado)
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore cria o store no diretório informado (construtor)
+func NewFileStore(dir string) (*FileStore, static.ErrorUtil) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Error().Caller().Err(err).Str("blob_dir", dir).Msg("Failed to create blob directory")
+		return nil, static.NewErrorUtil("Failed to create blob directory", "BLOB_STORE_INIT_FAILED", err, err.Error())
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, static.NewErrorUtil("Failed to resolve blob directory", "BLOB_STORE_INIT_FAILED", err, err.Error())
+	}
+	return &FileStore{dir: abs}, nil
+}
+
+// Put grava data em <dir>/<key[:2]>/<key>. Chaves são endereçadas por conteúdo,
+// então um arquivo existente não é reescrito.
+func (f *FileStore) Put(ctx context.Context, key string, data []byte) (string, static.ErrorUtil) {
+	if len(key) < 2 {
+		return "", static.NewErrorUtil("Invalid blob key", "BLOB_STORE_PUT_FAILED", nil, key)
+	}
+
+	path := filepath.Join(f.dir, key[:2], key)
+	ref := "file://" + path
+
+	if _, err := os.Stat(path); err == nil {
+		return ref, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", static.NewErrorUtil("Failed to stat blob", "BLOB_STORE_PUT_FAILED", err, err.Error())
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", static.NewErrorUtil("Failed to create blob directory", "BLOB_STORE_PUT_FAILED", err, err.Error())
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".tmp-*")
+	if err != nil {
+		return "", static.NewErrorUtil("Failed to write blob", "BLOB_STORE_PUT_FAILED", err, err.Error())
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", static.NewErrorUtil("Failed to write blob", "BLOB_STORE_PUT_FAILED", err, err.Error())
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", static.NewErrorUtil("Failed to write blob", "BLOB_STORE_PUT_FAILED", err, err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		return "", static.NewErrorUtil("Failed to write blob", "BLOB_STORE_PUT_FAILED", err, err.Error())
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", static.NewErrorUtil("Failed to write blob", "BLOB_STORE_PUT_FAILED", err, err.Error())
+	}
+
+	return ref, nil
+}
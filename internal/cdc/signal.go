@@ -0,0 +1,135 @@
+// internal/cdc/signal.go
+package cdc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"go-cdc/internal/event"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Tipos de sinal aceitos na coluna type da tabela de sinais
+const (
+	SignalExecuteSnapshot = "execute-snapshot"
+	SignalStopSnapshot    = "stop-snapshot"
+	SignalPause           = "pause"
+	SignalResume          = "resume"
+	SignalLog             = "log"
+)
+
+// signal linha inserida na tabela de sinais (colunas id, type, data)
+type signal struct {
+	ID       string
+	Type     string
+	Data     string
+	Position event.Position
+}
+
+// snapshotSignalData formato de data em execute-snapshot e stop-snapshot:
+//
+//	{"data-collections": ["dbo.Orders"],
+//	 "additional-conditions": [{"data-collection": "dbo.Orders", "filter": "Region = 'EU'"}]}
+type snapshotSignalData struct {
+	DataCollections      []string `json:"data-collections"`
+	AdditionalConditions []struct {
+		DataCollection string `json:"data-collection"`
+		Filter         string `json:"filter"`
+	} `json:"additional-conditions"`
+}
+
+// logSignalData formato de data em log
+type logSignalData struct {
+	Message string `json:"message"`
+}
+
+// isSignalTable indica se a tabela é a tabela de sinais configurada
+func (s *Stream) isSignalTable(table string) bool {
+	return s.signalTable != "" && strings.ToLower(table) == s.signalTable
+}
+
+// splitSignals extrai os sinais do lote (apenas inserts na tabela de sinais).
+// Um pause interrompe o lote: eventos de dados posteriores a ele não são lidos
+// neste ciclo e serão relidos após o resume. Retorna os eventos mantidos
+// (incluindo as linhas de sinal, para o cálculo das posições) e os sinais em ordem.
+func (s *Stream) splitSignals(events []event.Event) ([]event.Event, []signal) {
+	if s.signalTable == "" {
+		return events, nil
+	}
+
+	var signals []signal
+	for i := range events {
+		evt := &events[i]
+		if evt.Op != event.OpInsert || !s.isSignalTable(evt.FullTableName()) {
+			continue
+		}
+
+		sig := signal{
+			ID:       fmt.Sprint(evt.After["id"]),
+			Type:     strings.ToLower(strings.TrimSpace(fmt.Sprint(evt.After["type"]))),
+			Position: evt.Position,
+		}
+		if data, ok := evt.After["data"].(string); ok {
+			sig.Data = data
+		}
+		signals = append(signals, sig)
+
+		if sig.Type == SignalPause {
+			kept := events[:i+1]
+			for _, rest := range events[i+1:] {
+				// eventos gerados (schema, truncate) não são relidos e não podem ser perdidos
+				if !rest.FromLog() {
+					kept = append(kept, rest)
+				}
+			}
+			return kept, signals
+		}
+	}
+	return events, signals
+}
+
+// applySignals executa os sinais depois que o lote que os contém foi confirmado,
+// então um sinal nunca é aplicado duas vezes quando o lote é relido
+func (s *Stream) applySignals(signals []signal) {
+	for _, sig := range signals {
+		logger := log.With().Str("signal_id", sig.ID).Str("signal_type", sig.Type).Str("position", sig.Position.String()).Logger()
+
+		switch sig.Type {
+		case SignalExecuteSnapshot, SignalStopSnapshot:
+			var data snapshotSignalData
+			if sig.Data != "" {
+				if err := json.Unmarshal([]byte(sig.Data), &data); err != nil {
+					logger.Error().Err(err).Msg("Invalid signal data, ignoring signal")
+					continue
+				}
+			}
+			if sig.Type == SignalExecuteSnapshot {
+				filters := make(map[string]string, len(data.AdditionalConditions))
+				for _, condition := range data.AdditionalConditions {
+					filters[strings.ToLower(condition.DataCollection)] = condition.Filter
+				}
+				for _, table := range data.DataCollections {
+					s.scheduleSnapshot(table, filters[strings.ToLower(table)], sig.ID)
+				}
+			} else {
+				s.stopSnapshots(data.DataCollections)
+			}
+		case SignalPause:
+			s.paused = true
+			logger.Info().Msg("CDC stream paused by signal")
+		case SignalResume:
+			s.paused = false
+			logger.Info().Msg("CDC stream resumed by signal")
+		case SignalLog:
+			var data logSignalData
+			if err := json.Unmarshal([]byte(sig.Data), &data); err != nil || data.Message == "" {
+				data.Message = sig.Data
+			}
+			logger.Info().Msg(data.Message)
+		default:
+			logger.Warn().Msg("Unknown signal type, ignoring signal")
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// internal/cdc/snapshot.go
+package cdc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go-cdc/database/sqlserver"
+	"go-cdc/internal/event"
+	"go-cdc/internal/predicate"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// snapshotTask snapshot sob demanda de uma tabela, lido em blocos por chave.
+// Os blocos só são lidos com o stream em dia com o log: alterações posteriores
+// à leitura de um bloco chegam depois dele, então o consumidor converge para o
+// estado atual aplicando os eventos em ordem. O progresso fica apenas em memória.
+type snapshotTask struct {
+	table    string // schema.tabela em minúsculas
+	filter   string
+	signalID string
+	lastKey  []interface{}
+	rows     int
+}
+
+// scheduleSnapshot enfileira o snapshot de uma tabela (substitui um snapshot em andamento da mesma tabela)
+func (s *Stream) scheduleSnapshot(table string, filter string, signalID string) {
+	table = strings.ToLower(strings.TrimSpace(table))
+	if s.findInstance(table) == nil {
+		log.Warn().Str("table", table).Str("signal_id", signalID).Msg("Snapshot requested for a table not captured by this stream, ignoring")
+		return
+	}
+
+	// o filtro vai para o WHERE do SELECT: apenas o subconjunto validado é aceito
+	if filter != "" {
+		if _, err := predicate.Parse(filter); err != nil {
+			log.Error().Err(err).Str("table", table).Str("signal_id", signalID).Msg("Invalid snapshot filter, ignoring snapshot request")
+			return
+		}
+	}
+
+	s.stopSnapshots([]string{table})
+	s.snapshots = append(s.snapshots, &snapshotTask{table: table, filter: filter, signalID: signalID})
+	log.Info().Str("table", table).Str("filter", filter).Str("signal_id", signalID).Msg("Snapshot scheduled")
+}
+
+// stopSnapshots cancela os snapshots das tabelas informadas (todas se vazio)
+func (s *Stream) stopSnapshots(tables []string) {
+	stop := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		stop[strings.ToLower(strings.TrimSpace(table))] = true
+	}
+
+	kept := s.snapshots[:0]
+	for _, task := range s.snapshots {
+		if len(stop) == 0 || stop[task.table] {
+			log.Info().Str("table", task.table).Int("rows", task.rows).Msg("Snapshot stopped")
+			continue
+		}
+		kept = append(kept, task)
+	}
+	s.snapshots = kept
+}
+
+// findInstance retorna a capture instance ativa da tabela
+func (s *Stream) findInstance(table string) *sqlserver.CaptureInstance {
+	for i := range s.instances {
+		if strings.ToLower(s.instances[i].FullTableName()) == table {
+			return &s.instances[i]
+		}
+	}
+	return nil
+}
+
+// snapshotChunk lê e entrega o próximo bloco do primeiro snapshot da fila.
+// toLSN é a posição atribuída às linhas lidas: o log já foi processado até ele.
+func (s *Stream) snapshotChunk(ctx context.Context, handler Handler, toLSN string) static.ErrorUtil {
+	task := s.snapshots[0]
+	ci := s.findInstance(task.table)
+	var keyColumns []string
+	if ci != nil {
+		keyColumns = s.keys[ci.Name]
+	}
+	tableSchema, ok := s.history.Latest(task.table)
+	if len(keyColumns) == 0 || !ok {
+		log.Error().Str("table", task.table).Msg("Snapshot requires a captured table with key columns, stopping snapshot")
+		s.snapshots = s.snapshots[1:]
+		return nil
+	}
+
+	columns := make([]string, 0, len(ci.CapturedColumns))
+	for _, col := range ci.CapturedColumns {
+		columns = append(columns, col.Name)
+	}
+
+	chunkSize := s.cfg.CDCSnapshotChunkSize
+	if chunkSize <= 0 {
+		chunkSize = static.APP_GO_CDC_SNAPSHOT_CHUNK_SIZE
+	}
+
+	// o predicado da tabela sempre se aplica, combinado com o filtro do sinal
+	filter := task.filter
+	if rowFilter := s.rowFilters[task.table]; rowFilter != "" {
+		if _, err := predicate.Parse(rowFilter); err != nil {
+			log.Error().Err(err).Str("table", task.table).Msg("Invalid row filter, stopping snapshot")
+			s.snapshots = s.snapshots[1:]
+			return nil
+		}
+		if filter != "" {
+			filter = "(" + rowFilter + ") AND (" + filter + ")"
+		} else {
+			filter = rowFilter
+		}
+	}
+
+	rows, errRead := s.sqlServer.ReadSnapshotChunk(ctx, sqlserver.SnapshotChunkQuery{
+		Schema:     ci.SourceSchema,
+		Table:      ci.SourceTable,
+		Columns:    columns,
+		KeyColumns: keyColumns,
+		AfterKey:   task.lastKey,
+		Filter:     filter,
+		Limit:      chunkSize,
+	})
+	if errRead != nil {
+		return errRead
+	}
+
+	now := time.Now().UTC()
+	events := make([]event.Event, len(rows))
+	for i, row := range rows {
+		events[i] = event.Event{
+			Op:              event.OpRead,
+			Database:        s.replica.DatabaseName,
+			Schema:          ci.SourceSchema,
+			Table:           ci.SourceTable,
+			CaptureInstance: ci.Name,
+			Position:        event.Position{LSN: toLSN},
+			CommitTime:      now,
+			After:           row,
+			TableSchema:     tableSchema,
+		}
+		events[i].SetKey(keyColumns)
+		formatPeriods(&events[i])
+	}
+
+	// chave da última linha antes do handler, cujas transformações alteram as linhas no próprio lote
+	var lastKey []interface{}
+	if len(rows) > 0 {
+		last := rows[len(rows)-1]
+		lastKey = make([]interface{}, len(keyColumns))
+		for i, column := range keyColumns {
+			lastKey[i] = last[column]
+		}
+	}
+
+	if len(events) > 0 {
+		if errHandler := handler(ctx, events); errHandler != nil {
+			return errHandler
+		}
+	}
+
+	task.rows += len(rows)
+	if lastKey != nil {
+		task.lastKey = lastKey
+	}
+	if len(rows) < chunkSize {
+		log.Info().Str("table", task.table).Int("rows", task.rows).Str("signal_id", task.signalID).Msg("Snapshot completed")
+		s.snapshots = s.snapshots[1:]
+	}
+	return nil
+}
@@ -0,0 +1,615 @@
+// internal/cdc/stream.go
+package cdc
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"go-cdc/database/sqlserver"
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/offset"
+	"go-cdc/internal/retry"
+	"go-cdc/internal/schema"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Handler recebe um lote de eventos ordenado por posição. As posições só são
+// confirmadas no offset store quando o handler retorna sem erro.
+type Handler func(ctx context.Context, events []event.Event) static.ErrorUtil
+
+// readThroughSeqVal seqval da marca d'água de leitura: a posição (lsn, máximo)
+// fica depois de qualquer operação do LSN, então a próxima leitura começa no
+// LSN seguinte
+const readThroughSeqVal = "ffffffffffffffffffff"
+
+// CommitFunc é chamada depois que as posições foram persistidas no offset store
+type CommitFunc func(ctx context.Context, positions map[string]event.Position)
+
+// Stream faz polling das change tables e entrega os eventos ao handler
+type Stream struct {
+	cfg       *config.Config
+	sqlServer *sqlserver.SQLServer
+	offsets   offset.Store
+	history   schema.History
+
+	tables       map[string]bool
+	keyOverrides map[string][]string
+	rowFilters   map[string]string // predicado por tabela, aplicado também nos snapshots
+	batchSize    int
+	instances    []sqlserver.CaptureInstance
+	keys         map[string][]string // colunas de chave por capture instance
+	ddlLSNs      map[int]string      // último DDL visto por object_id de origem
+	pending      []event.Event       // eventos de schema aguardando entrega
+	backfilled   map[string]bool     // capture instances com backfill temporal concluído ou ignorado
+	positions    map[string]event.Position
+	replica      *sqlserver.ReplicaInfo
+
+	signalTable string          // schema.tabela em minúsculas; vazio = sinais desabilitados
+	paused      bool            // pausado por sinal: apenas a tabela de sinais é lida
+	snapshots   []*snapshotTask // snapshots sob demanda, executados em ordem
+	truncate    truncateDetector
+	onCommit    []CommitFunc
+
+	// verifyContinuity força a verificação dos LSNs armazenados antes da próxima leitura
+	verifyContinuity bool
+}
+
+// NewStream cria o stream com as dependências injetadas
+func NewStream(cfg *config.Config, sqlServer *sqlserver.SQLServer, offsets offset.Store, history schema.History) *Stream {
+	tables := make(map[string]bool)
+	for _, table := range config.SplitList(cfg.CDCTables) {
+		tables[strings.ToLower(table)] = true
+	}
+
+	keyOverrides := make(map[string][]string)
+	for table, columns := range config.ParseTableMap(cfg.CDCTableKeyOverrides) {
+		keyOverrides[table] = config.SplitList(columns)
+	}
+
+	// mínimo de 2 linhas para que as imagens anterior/posterior de um update caibam no lote
+	batchSize := cfg.CDCBatchSize
+	if batchSize < 2 {
+		batchSize = 2
+	}
+
+	return &Stream{
+		cfg:              cfg,
+		sqlServer:        sqlServer,
+		offsets:          offsets,
+		history:          history,
+		ddlLSNs:          make(map[int]string),
+		backfilled:       make(map[string]bool),
+		tables:           tables,
+		keyOverrides:     keyOverrides,
+		rowFilters:       config.ParseTableMapQuoted(cfg.CDCRowFilters),
+		batchSize:        batchSize,
+		signalTable:      strings.ToLower(strings.TrimSpace(cfg.CDCSignalTable)),
+		truncate:         newTruncateDetector(cfg),
+		verifyContinuity: true,
+	}
+}
+
+// OnCommit registra uma função chamada a cada confirmação de posições
+func (s *Stream) OnCommit(fn CommitFunc) {
+	s.onCommit = append(s.onCommit, fn)
+}
+
+// notifyCommit repassa uma cópia das posições confirmadas aos interessados
+func (s *Stream) notifyCommit(ctx context.Context) {
+	for _, fn := range s.onCommit {
+		positions := make(map[string]event.Position, len(s.positions))
+		for name, position := range s.positions {
+			positions[name] = position
+		}
+		fn(ctx, positions)
+	}
+}
+
+// Run executa o loop de polling até o contexto ser cancelado ou ocorrer um erro fatal
+func (s *Stream) Run(ctx context.Context, handler Handler) static.ErrorUtil {
+	positions, errLoad := s.offsets.Load()
+	if errLoad != nil {
+		return errLoad
+	}
+	s.positions = positions
+
+	interval := time.Duration(s.cfg.CDCPollIntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = static.APP_GO_CDC_POLL_INTERVAL_MS * time.Millisecond
+	}
+	log.Info().Dur("poll_interval", interval).Int("stored_offsets", len(positions)).Msg("CDC stream started")
+
+	maxBackoff := time.Duration(s.cfg.CDCStreamMaxBackoffSeconds) * time.Second
+	if maxBackoff < interval {
+		maxBackoff = interval
+	}
+	backoff := retry.NewBackoff(interval, maxBackoff)
+	failures := 0
+
+	for {
+		wait := interval
+
+		errPoll := s.poll(ctx, handler)
+		switch {
+		case ctx.Err() != nil:
+			// cancelamento durante o ciclo: encerra sem tratar como falha
+		case errPoll == nil:
+			failures = 0
+		case errPoll.Code() == static.ErrLSNNotReachable.Code():
+			return errPoll
+		case sqlserver.ClassifyError(errPoll) == sqlserver.ErrorFatal:
+			log.Error().Err(errPoll).Str("code", errPoll.Code()).Msg("Fatal database error, stopping CDC stream")
+			return errPoll
+		default:
+			wait = backoff.Duration(failures)
+			failures++
+			log.Error().
+				Err(errPoll).
+				Str("code", errPoll.Code()).
+				Str("error_class", sqlserver.ClassifyError(errPoll).String()).
+				Int("consecutive_failures", failures).
+				Dur("backoff", wait).
+				Msg("CDC poll failed, resuming from last committed LSN after backoff")
+			s.verifyContinuity = true
+		}
+
+		if retry.Sleep(ctx, wait) != nil {
+			log.Info().Msg("CDC stream stopped")
+			return nil
+		}
+	}
+}
+
+// poll executa um ciclo de leitura de todas as capture instances
+func (s *Stream) poll(ctx context.Context, handler Handler) static.ErrorUtil {
+	if errReplica := s.checkReplica(ctx); errReplica != nil {
+		return errReplica
+	}
+
+	if s.verifyContinuity {
+		if errDiscover := s.discover(ctx); errDiscover != nil {
+			return errDiscover
+		}
+		ready, errVerify := s.verifyPositions(ctx)
+		if errVerify != nil || !ready {
+			return errVerify
+		}
+		s.verifyContinuity = false
+	}
+
+	counts, errCounts := s.rowCountsDue(ctx)
+	if errCounts != nil {
+		return errCounts
+	}
+
+	toLSN, errMax := s.sqlServer.GetMaxLSN(ctx)
+	if errMax != nil || toLSN == "" {
+		return errMax
+	}
+
+	if errDDL := s.checkDDL(ctx, toLSN); errDDL != nil {
+		return errDDL
+	}
+
+	var events []event.Event
+	var cutoff string          // menor LSN final entre os lotes truncados
+	var readInstances []string // capture instances lidas neste ciclo
+
+	for i := range s.instances {
+		ci := &s.instances[i]
+		if s.paused && !s.isSignalTable(ci.FullTableName()) {
+			continue
+		}
+		if s.cfg.TemporalBackfill && s.positions[ci.Name].IsZero() && !s.backfilled[ci.Name] && !s.isInternalTable(ci.FullTableName()) {
+			if errBackfill := s.backfillTemporal(ctx, handler, ci); errBackfill != nil {
+				return errBackfill
+			}
+		}
+		after := s.positions[ci.Name]
+
+		minLSN, errMin := s.sqlServer.GetMinLSN(ctx, ci.Name)
+		if errMin != nil {
+			return errMin
+		}
+		if minLSN == "" {
+			continue
+		}
+		fromLSN := readFrom(after, minLSN)
+
+		// Réplica atrás da posição já processada (redo lag): nunca pular adiante
+		if fromLSN > toLSN {
+			log.Warn().
+				Str("capture_instance", ci.Name).
+				Str("stored_lsn", fromLSN).
+				Str("replica_max_lsn", toLSN).
+				Msg("Replica is behind the stored LSN, waiting for redo to catch up")
+			continue
+		}
+
+		batch, truncated, errRead := s.sqlServer.ReadChanges(ctx, ci, s.replica.DatabaseName, after, fromLSN, toLSN, s.batchSize)
+		if errRead != nil {
+			return errRead
+		}
+		readInstances = append(readInstances, ci.Name)
+		for j := range batch {
+			batch[j].SetKey(s.keys[ci.Name])
+			batch[j].TableSchema, _ = s.history.SchemaAt(ci.FullTableName(), batch[j].Position.LSN)
+			formatPeriods(&batch[j])
+		}
+		if truncated && len(batch) > 0 {
+			if last := batch[len(batch)-1].Position.LSN; cutoff == "" || last < cutoff {
+				cutoff = last
+			}
+		}
+		events = append(events, batch...)
+	}
+
+	// Mantém a ordem global por LSN: eventos com LSN além do ponto onde algum
+	// lote foi truncado ficam para o próximo ciclo. Os lotes truncados terminam
+	// no fim de um LSN, então cada transação de origem é entregue inteira.
+	if cutoff != "" {
+		kept := events[:0]
+		for _, evt := range events {
+			if evt.Position.LSN <= cutoff {
+				kept = append(kept, evt)
+			}
+		}
+		events = kept
+	}
+	// todas as capture instances lidas estão completas até aqui, tenham ou não eventos
+	through := toLSN
+	if cutoff != "" {
+		through = cutoff
+	}
+
+	events = append(events, s.pending...)
+	if len(events) == 0 {
+		if errCommit := s.commit(ctx, nil, readInstances, through); errCommit != nil {
+			return errCommit
+		}
+		return s.afterCommit(ctx, handler, cutoff == "", toLSN, counts)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Position.Compare(events[j].Position) < 0
+	})
+
+	events, signals := s.splitSignals(events)
+	events = s.expandAuditTruncates(events)
+
+	// posições são calculadas sobre todos os eventos lidos, inclusive os das tabelas
+	// internas e os descartados pelas transformações do pipeline
+	read := events
+	events = make([]event.Event, 0, len(read))
+	for _, evt := range read {
+		if !s.isInternalTable(evt.FullTableName()) {
+			events = append(events, evt)
+		}
+	}
+	if len(events) > 0 {
+		if errHandler := handler(ctx, events); errHandler != nil {
+			return errHandler
+		}
+	}
+
+	if errCommit := s.commit(ctx, read, readInstances, through); errCommit != nil {
+		return errCommit
+	}
+	s.pending = nil
+	s.trackRowCounts(read)
+	s.applySignals(signals)
+	s.resnapshotTruncated(read)
+
+	log.Debug().Int("events", len(events)).Str("to_lsn", toLSN).Msg("CDC batch committed")
+	return s.afterCommit(ctx, handler, cutoff == "" && len(signals) == 0, toLSN, counts)
+}
+
+// commit persiste as posições do ciclo. As posições em memória só avançam
+// depois de persistidas: após qualquer falha o próximo ciclo recomeça do
+// último LSN confirmado.
+func (s *Stream) commit(ctx context.Context, read []event.Event, instances []string, through string) static.ErrorUtil {
+	committed := commitPositions(s.positions, read, instances, through)
+	if len(read) == 0 && maps.Equal(committed, s.positions) {
+		return nil
+	}
+	if errSave := s.offsets.Save(committed); errSave != nil {
+		return errSave
+	}
+	s.positions = committed
+	s.notifyCommit(ctx)
+	return nil
+}
+
+// commitPositions calcula as posições após um ciclo: a do último evento lido
+// do log de cada capture instance ou, se maior, a marca d'água de leitura até
+// through. Sem a marca d'água uma tabela sem alterações por mais tempo que a
+// retenção do CDC ficaria com a posição abaixo do min_lsn e seria dada como
+// perdida, embora nada tenha sido perdido.
+func commitPositions(positions map[string]event.Position, read []event.Event, instances []string, through string) map[string]event.Position {
+	committed := make(map[string]event.Position, len(positions))
+	for ci, position := range positions {
+		committed[ci] = position
+	}
+	for _, evt := range read {
+		// eventos gerados podem estar à frente dos dados ainda não lidos
+		if !evt.FromLog() {
+			continue
+		}
+		if evt.Position.Compare(committed[evt.CaptureInstance]) > 0 {
+			committed[evt.CaptureInstance] = evt.Position
+		}
+	}
+	watermark := event.Position{LSN: through, SeqVal: readThroughSeqVal}
+	for _, ci := range instances {
+		if watermark.Compare(committed[ci]) > 0 {
+			committed[ci] = watermark
+		}
+	}
+	return committed
+}
+
+// readFrom LSN inicial da leitura: a posição armazenada, nunca abaixo do
+// min_lsn (fn_cdc_get_all_changes rejeita um LSN inicial menor)
+func readFrom(after event.Position, minLSN string) string {
+	if after.LSN < minLSN {
+		return minLSN
+	}
+	return after.LSN
+}
+
+// afterCommit executa as tarefas que dependem do stream estar em dia com o log:
+// verificação de contagem de linhas e o próximo bloco de snapshot
+func (s *Stream) afterCommit(ctx context.Context, handler Handler, caughtUp bool, toLSN string, counts map[int]int64) static.ErrorUtil {
+	if !caughtUp || s.paused {
+		return nil
+	}
+	s.checkRowCounts(counts, toLSN)
+	if len(s.snapshots) == 0 {
+		return nil
+	}
+	return s.snapshotChunk(ctx, handler, toLSN)
+}
+
+// checkReplica detecta troca de réplica (failover do AG) entre ciclos
+func (s *Stream) checkReplica(ctx context.Context) static.ErrorUtil {
+	replica, errReplica := s.sqlServer.GetReplicaInfo(ctx)
+	if errReplica != nil {
+		return errReplica
+	}
+
+	if s.replica == nil || s.replica.ServerName != replica.ServerName {
+		if s.replica != nil {
+			log.Warn().
+				Str("previous_server", s.replica.ServerName).
+				Str("current_server", replica.ServerName).
+				Msg("Replica changed (failover), verifying LSN continuity")
+			s.verifyContinuity = true
+		}
+		if s.cfg.DBReadFromSecondary && !replica.IsReadOnly() {
+			log.Warn().Str("server", replica.ServerName).Msg("Read from secondary requested but connected to a read-write replica")
+		}
+		log.Info().
+			Str("server", replica.ServerName).
+			Str("database", replica.DatabaseName).
+			Str("updateability", replica.Updateability).
+			Msg("Connected to replica")
+	}
+
+	s.replica = replica
+	return nil
+}
+
+// discover carrega as capture instances das tabelas configuradas
+func (s *Stream) discover(ctx context.Context) static.ErrorUtil {
+	all, errList := s.sqlServer.ListCaptureInstances(ctx)
+	if errList != nil {
+		return errList
+	}
+
+	seen := make(map[string]bool)
+	var instances []sqlserver.CaptureInstance
+	for _, ci := range all {
+		table := strings.ToLower(ci.FullTableName())
+		// as tabelas de sinais e de auditoria são sempre lidas, mesmo fora da lista de tabelas
+		if seen[table] || (len(s.tables) > 0 && !s.tables[table] && !s.isInternalTable(table)) {
+			continue
+		}
+		seen[table] = true
+		instances = append(instances, ci)
+	}
+
+	for table := range s.tables {
+		if !seen[table] {
+			log.Warn().Str("table", table).Msg("Configured table has no CDC capture instance")
+		}
+	}
+	if s.signalTable != "" && !seen[s.signalTable] {
+		log.Warn().Str("table", s.signalTable).Msg("Signal table has no CDC capture instance; signals will not be received")
+	}
+
+	keys := make(map[string][]string, len(instances))
+	for i := range instances {
+		ci := &instances[i]
+		table := strings.ToLower(ci.FullTableName())
+
+		captured, errCaptured := s.sqlServer.GetCapturedColumns(ctx, ci.Name)
+		if errCaptured != nil {
+			return errCaptured
+		}
+		ci.CapturedColumns = captured
+
+		if override, ok := s.keyOverrides[table]; ok {
+			keys[ci.Name] = override
+			log.Info().Str("table", ci.FullTableName()).Strs("key_columns", override).Msg("Using configured key columns")
+			continue
+		}
+
+		columns, source, errKey := s.sqlServer.GetKeyColumns(ctx, ci)
+		if errKey != nil {
+			return errKey
+		}
+		if source == sqlserver.KeySourceNone {
+			log.Warn().Str("table", ci.FullTableName()).Msg("Table has no primary key or unique index; configure APP_GO_CDC_TABLE_KEY_OVERRIDES for stable event keys")
+			continue
+		}
+		keys[ci.Name] = columns
+		log.Info().Str("table", ci.FullTableName()).Str("key_source", string(source)).Strs("key_columns", columns).Msg("Key columns discovered")
+	}
+
+	s.instances = instances
+	s.keys = keys
+	log.Info().Int("capture_instances", len(instances)).Msg("CDC capture instances discovered")
+
+	for i := range instances {
+		if errSchema := s.refreshSchema(ctx, &instances[i], ""); errSchema != nil {
+			return errSchema
+		}
+	}
+	return nil
+}
+
+// checkDDL registra uma nova versão de schema para tabelas com DDL novo em cdc.ddl_history
+func (s *Stream) checkDDL(ctx context.Context, maxLSN string) static.ErrorUtil {
+	ddlLSNs, errDDL := s.sqlServer.GetLatestDDLLSNs(ctx)
+	if errDDL != nil {
+		return errDDL
+	}
+
+	for i := range s.instances {
+		ci := &s.instances[i]
+		ddlLSN, ok := ddlLSNs[ci.SourceObjectID]
+		if !ok || ddlLSN == s.ddlLSNs[ci.SourceObjectID] {
+			continue
+		}
+		// na primeira verificação após o start, DDLs posteriores à posição armazenada são novos
+		previous, seen := s.ddlLSNs[ci.SourceObjectID]
+		if seen {
+			log.Info().Str("table", ci.FullTableName()).Str("ddl_lsn", ddlLSN).Msg("DDL change detected")
+		} else {
+			previous = s.positions[ci.Name].LSN
+		}
+		if errTruncate := s.detectDDLTruncates(ctx, ci, previous, ddlLSN, maxLSN); errTruncate != nil {
+			return errTruncate
+		}
+
+		if ddlLSN > maxLSN {
+			ddlLSN = maxLSN
+		}
+		if errSchema := s.refreshSchema(ctx, ci, ddlLSN); errSchema != nil {
+			return errSchema
+		}
+	}
+
+	s.ddlLSNs = ddlLSNs
+	return nil
+}
+
+// refreshSchema lê a definição atual da tabela e registra uma nova versão no
+// histórico se ela mudou. A versão inicial de uma capture instance vale desde
+// o start_lsn; mudanças posteriores valem a partir do LSN do DDL.
+func (s *Stream) refreshSchema(ctx context.Context, ci *sqlserver.CaptureInstance, ddlLSN string) static.ErrorUtil {
+	columns, errColumns := s.sqlServer.GetTableColumns(ctx, ci.SourceObjectID)
+	if errColumns != nil {
+		return errColumns
+	}
+
+	captured := make(map[string]bool, len(ci.CapturedColumns))
+	for _, col := range ci.CapturedColumns {
+		captured[col.Name] = true
+	}
+	var uncaptured []string
+	for i := range columns {
+		columns[i].Captured = captured[columns[i].Name]
+		if !columns[i].Captured {
+			uncaptured = append(uncaptured, columns[i].Name)
+		}
+	}
+
+	temporal, errTemporal := s.sqlServer.GetTemporalInfo(ctx, ci.SourceObjectID)
+	if errTemporal != nil {
+		return errTemporal
+	}
+
+	version := schema.TableSchema{
+		Table:             ci.FullTableName(),
+		CaptureInstance:   ci.Name,
+		ValidFrom:         ci.StartLSN,
+		Columns:           columns,
+		UncapturedColumns: uncaptured,
+		Temporal:          temporal,
+	}
+	latest, ok := s.history.Latest(version.Table)
+	if ok && latest.CaptureInstance == ci.Name && ddlLSN > latest.ValidFrom {
+		version.ValidFrom = ddlLSN
+	}
+
+	recorded, errRecord := s.history.Record(version)
+	if errRecord != nil || !recorded {
+		return errRecord
+	}
+	if len(uncaptured) > 0 {
+		log.Warn().
+			Str("table", ci.FullTableName()).
+			Str("capture_instance", ci.Name).
+			Strs("uncaptured_columns", uncaptured).
+			Msg("Source columns not in the capture instance will be absent from events")
+	}
+	for _, key := range s.keys[ci.Name] {
+		if !captured[key] {
+			log.Warn().Str("table", ci.FullTableName()).Str("key_column", key).Msg("Key column is not captured; event keys will be incomplete")
+		}
+	}
+
+	s.pending = append(s.pending, event.Event{
+		Op:              event.OpSchema,
+		Database:        s.replica.DatabaseName,
+		Schema:          ci.SourceSchema,
+		Table:           ci.SourceTable,
+		CaptureInstance: ci.Name,
+		Position:        event.Position{LSN: version.ValidFrom},
+		KeyColumns:      s.keys[ci.Name],
+		TableSchema:     &version,
+		SchemaChange:    &version,
+	})
+	return nil
+}
+
+// verifyPositions garante que cada LSN armazenado ainda é alcançável na réplica
+// atual. Retorna ready=false enquanto a réplica estiver atrasada.
+func (s *Stream) verifyPositions(ctx context.Context) (bool, static.ErrorUtil) {
+	for _, ci := range s.instances {
+		position, ok := s.positions[ci.Name]
+		if !ok || position.IsZero() {
+			continue
+		}
+
+		status, errCheck := s.sqlServer.CheckLSNReachable(ctx, ci.Name, position.LSN)
+		if errCheck != nil {
+			return false, errCheck
+		}
+
+		switch status {
+		case sqlserver.LSNAhead:
+			log.Warn().
+				Str("capture_instance", ci.Name).
+				Str("stored_lsn", position.LSN).
+				Str("server", s.replica.ServerName).
+				Msg("Stored LSN not yet available on this replica, waiting")
+			return false, nil
+		case sqlserver.LSNLost:
+			log.Error().
+				Str("capture_instance", ci.Name).
+				Str("stored_lsn", position.LSN).
+				Str("server", s.replica.ServerName).
+				Msg("Stored LSN is older than the CDC retention window")
+			return false, static.NewErrorUtil(static.ErrLSNNotReachable.Error(), static.ErrLSNNotReachable.Code(), nil, "capture_instance="+ci.Name+" lsn="+position.LSN)
+		}
+	}
+
+	return true, nil
+}
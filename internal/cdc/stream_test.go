@@ -0,0 +1,65 @@
+package cdc
+
+import (
+	"fmt"
+	"testing"
+
+	"go-cdc/internal/event"
+)
+
+func lsn(n int) string {
+	return fmt.Sprintf("%020x", n)
+}
+
+// Tabela sem alterações por mais tempo que a retenção: a posição avança com a
+// marca d'água de leitura e a próxima leitura começa no min_lsn movido pela limpeza
+func TestCommitPositionsQuietTable(t *testing.T) {
+	positions := map[string]event.Position{
+		"dbo_Orders":  {LSN: lsn(10), SeqVal: lsn(3)},
+		"dbo_Devices": {LSN: lsn(10), SeqVal: lsn(1)},
+		"dbo_Paused":  {LSN: lsn(10), SeqVal: lsn(1)},
+	}
+	read := []event.Event{
+		{Op: event.OpUpdate, CaptureInstance: "dbo_Orders", Position: event.Position{LSN: lsn(40), SeqVal: lsn(2)}},
+		{Op: event.OpUpdate, CaptureInstance: "dbo_Orders", Position: event.Position{LSN: lsn(60), SeqVal: lsn(1)}},
+		// gerado pelo snapshot: não avança a posição
+		{Op: event.OpRead, CaptureInstance: "dbo_Devices", Position: event.Position{LSN: lsn(500)}},
+	}
+
+	committed := commitPositions(positions, read, []string{"dbo_Orders", "dbo_Devices"}, lsn(100))
+
+	watermark := event.Position{LSN: lsn(100), SeqVal: readThroughSeqVal}
+	if committed["dbo_Devices"] != watermark {
+		t.Errorf("quiet table = %v, want %v", committed["dbo_Devices"], watermark)
+	}
+	if committed["dbo_Orders"] != watermark {
+		t.Errorf("table read through = %v, want %v", committed["dbo_Orders"], watermark)
+	}
+	// não lida no ciclo (pausada ou réplica atrasada): mantém a posição
+	if committed["dbo_Paused"] != positions["dbo_Paused"] {
+		t.Errorf("unread table = %v", committed["dbo_Paused"])
+	}
+	if positions["dbo_Devices"].LSN != lsn(10) {
+		t.Errorf("input positions modified: %v", positions)
+	}
+
+	// a limpeza moveu o min_lsn para 80: a marca d'água continua alcançável
+	if got := readFrom(committed["dbo_Devices"], lsn(80)); got != lsn(100) {
+		t.Errorf("readFrom(watermark) = %s", got)
+	}
+	if got := readFrom(positions["dbo_Devices"], lsn(80)); got != lsn(80) {
+		t.Errorf("readFrom(stale position) = %s, want the min_lsn", got)
+	}
+	if got := readFrom(event.Position{}, lsn(80)); got != lsn(80) {
+		t.Errorf("readFrom(zero) = %s, want the min_lsn", got)
+	}
+}
+
+// A marca d'água nunca recua uma posição já à frente do ponto lido
+func TestCommitPositionsKeepsNewerPosition(t *testing.T) {
+	ahead := event.Position{LSN: lsn(150), SeqVal: lsn(1)}
+	committed := commitPositions(map[string]event.Position{"dbo_Orders": ahead}, nil, []string{"dbo_Orders"}, lsn(100))
+	if committed["dbo_Orders"] != ahead {
+		t.Errorf("committed = %v, want %v", committed["dbo_Orders"], ahead)
+	}
+}
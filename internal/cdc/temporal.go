@@ -0,0 +1,192 @@
+// internal/cdc/temporal.go
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-cdc/database/sqlserver"
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// zeroSeqVal seqval usado na posição gravada ao fim do backfill temporal
+const zeroSeqVal = "00000000000000000000"
+
+// historicalLSN LSN das alterações reconstruídas do histórico temporal: anterior
+// a qualquer LSN do log, com a ordem da alteração no backfill no seqval
+const historicalLSN = "00000000000000000000"
+
+// historicalPosition posição da n-ésima alteração (a partir de 1) do backfill.
+// A leitura segue a ordem (instante, operação, chave), então um backfill
+// refeito gera as mesmas posições e os sinks que versionam por posição
+// (elasticsearch, clickhouse) e a ordem do gRPC continuam válidos.
+func historicalPosition(n int) event.Position {
+	return event.Position{LSN: historicalLSN, SeqVal: fmt.Sprintf("%020x", n)}
+}
+
+// formatPeriods serializa as colunas de período (datetime2 em UTC) em ISO-8601
+// com a precisão da coluna, ex.: 2024-05-01T10:00:00.1234567Z. Sem isso o JSON
+// de time.Time descarta zeros à direita e o fim aberto 9999-12-31 23:59:59.9999999
+// perde a forma reconhecível.
+func formatPeriods(evt *event.Event) {
+	if evt.TableSchema == nil || evt.TableSchema.Temporal == nil {
+		return
+	}
+	for _, col := range evt.TableSchema.Columns {
+		if col.GeneratedAlways == "" {
+			continue
+		}
+		layout := "2006-01-02T15:04:05"
+		if col.Scale > 0 {
+			layout += "." + strings.Repeat("0", col.Scale)
+		}
+		layout += "Z"
+		for _, row := range []map[string]interface{}{evt.Before, evt.After} {
+			if t, ok := row[col.Name].(time.Time); ok {
+				row[col.Name] = t.UTC().Format(layout)
+			}
+		}
+	}
+}
+
+// backfillTemporal entrega as alterações de uma tabela temporal anteriores à
+// alteração mais antiga disponível no CDC e grava a posição inicial da capture
+// instance, para que o backfill não se repita após um restart. Dentro do
+// processo a capture instance fica marcada em s.backfilled em toda saída sem
+// erro (inclusive quando o backfill não se aplica), evitando repeti-lo a cada
+// ciclo enquanto a posição continuar vazia. O progresso fica em memória: uma
+// falha no meio recomeça o backfill do início no próximo ciclo.
+func (s *Stream) backfillTemporal(ctx context.Context, handler Handler, ci *sqlserver.CaptureInstance) static.ErrorUtil {
+	tableSchema, ok := s.history.Latest(ci.FullTableName())
+	keyColumns := s.keys[ci.Name]
+	if !ok || tableSchema.Temporal == nil {
+		s.backfilled[ci.Name] = true
+		return nil
+	}
+	for _, key := range keyColumns {
+		if !tableSchema.IsCaptured(key) {
+			keyColumns = nil
+		}
+	}
+	if len(keyColumns) == 0 {
+		log.Warn().Str("table", ci.FullTableName()).Msg("Temporal backfill requires captured key columns, skipping")
+		s.backfilled[ci.Name] = true
+		return nil
+	}
+
+	minLSN, errMin := s.sqlServer.GetMinLSN(ctx, ci.Name)
+	if errMin != nil {
+		return errMin
+	}
+	boundary, errBoundary := s.sqlServer.GetCDCHistoryBoundary(ctx, ci.Name)
+	if errBoundary != nil {
+		return errBoundary
+	}
+
+	columns := make([]string, 0, len(ci.CapturedColumns))
+	for _, col := range ci.CapturedColumns {
+		columns = append(columns, col.Name)
+	}
+	chunkSize := s.cfg.CDCSnapshotChunkSize
+	if chunkSize <= 0 {
+		chunkSize = static.APP_GO_CDC_SNAPSHOT_CHUNK_SIZE
+	}
+
+	log.Info().
+		Str("table", ci.FullTableName()).
+		Str("history_table", tableSchema.Temporal.HistoryTable).
+		Time("before", boundary).
+		Msg("Temporal history backfill started")
+
+	query := sqlserver.TemporalHistoryQuery{
+		Schema:      ci.SourceSchema,
+		Table:       ci.SourceTable,
+		Columns:     columns,
+		KeyColumns:  keyColumns,
+		PeriodStart: tableSchema.Temporal.PeriodStart,
+		PeriodEnd:   tableSchema.Temporal.PeriodEnd,
+		Before:      boundary,
+		Limit:       chunkSize,
+	}
+	total := 0
+	for {
+		changes, errRead := s.sqlServer.ReadTemporalHistoryChunk(ctx, query)
+		if errRead != nil {
+			return errRead
+		}
+		if len(changes) == 0 {
+			break
+		}
+
+		events := make([]event.Event, len(changes))
+		for i, change := range changes {
+			evt := event.Event{
+				Database:        s.replica.DatabaseName,
+				Schema:          ci.SourceSchema,
+				Table:           ci.SourceTable,
+				CaptureInstance: ci.Name,
+				Position:        historicalPosition(total + i + 1),
+				CommitTime:      change.At.UTC(),
+				Historical:      true,
+				TableSchema:     tableSchema,
+			}
+			switch change.Op {
+			case sqlserver.TemporalInsert:
+				evt.Op, evt.After = event.OpInsert, change.Row
+			case sqlserver.TemporalUpdate:
+				// a imagem anterior não é reconstruída: After traz a versão completa
+				evt.Op, evt.After = event.OpUpdate, change.Row
+			case sqlserver.TemporalDelete:
+				evt.Op, evt.Before = event.OpDelete, change.Row
+			}
+			evt.SetKey(keyColumns)
+			events[i] = evt
+		}
+
+		// posição da última alteração antes do handler, cujas transformações alteram as linhas
+		last := changes[len(changes)-1]
+		after := []interface{}{last.At, last.Op}
+		for _, column := range keyColumns {
+			after = append(after, last.Row[column])
+		}
+
+		for i := range events {
+			formatPeriods(&events[i])
+		}
+		if errHandler := handler(ctx, events); errHandler != nil {
+			return errHandler
+		}
+
+		total += len(changes)
+		query.After = after
+		if len(changes) < chunkSize {
+			break
+		}
+	}
+
+	log.Info().Str("table", ci.FullTableName()).Int("changes", total).Msg("Temporal history backfill completed")
+
+	// Sem LSN mínimo (change table ainda sem dados) não há marco a gravar: o
+	// backfill não se repete neste processo, mas é refeito após um restart se a
+	// capture instance continuar sem posição
+	s.backfilled[ci.Name] = true
+	if minLSN == "" {
+		return nil
+	}
+	committed := make(map[string]event.Position, len(s.positions)+1)
+	for name, position := range s.positions {
+		committed[name] = position
+	}
+	committed[ci.Name] = event.Position{LSN: minLSN, SeqVal: zeroSeqVal}
+	if errSave := s.offsets.Save(committed); errSave != nil {
+		return errSave
+	}
+	s.positions = committed
+	s.notifyCommit(ctx)
+	return nil
+}
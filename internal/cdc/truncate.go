@@ -0,0 +1,294 @@
+// internal/cdc/truncate.go
+package cdc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go-cdc/database/sqlserver"
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Métodos de detecção de truncate (APP_GO_CDC_TRUNCATE_DETECTION)
+const (
+	TruncateDetectDDL      = "ddl"      // ALTER TABLE ... SWITCH registrado em cdc.ddl_history
+	TruncateDetectRowCount = "rowcount" // queda da contagem de linhas maior que a explicada pelos deletes
+	TruncateDetectAudit    = "audit"    // inserts em uma tabela de auditoria habilitada para CDC
+)
+
+// rowCountCheckInterval intervalo mínimo entre leituras de sys.partitions
+const rowCountCheckInterval = time.Minute
+
+// switchPattern ALTER TABLE ... SWITCH no início de uma instrução, já sem
+// comentários, identificadores delimitados e literais (ver stripSQL). Só o
+// SWITCH move linhas sem registrá-las no CDC: TRUNCATE TABLE é rejeitado em
+// tabelas habilitadas e nunca aparece em cdc.ddl_history.
+var switchPattern = regexp.MustCompile(`(?is)^\s*ALTER\s+TABLE\s+[\w#@$]+(\s*\.\s*[\w#@$]*)*\s+SWITCH\b`)
+
+// truncateDetector configuração e estado da detecção de truncates
+type truncateDetector struct {
+	ddl        bool
+	rowCount   bool
+	auditTable string // schema.tabela em minúsculas
+	dropPct    int
+	resnapshot bool
+
+	// contagem de linhas por capture instance: base lida em sys.partitions e
+	// saldo de inserts - deletes entregues desde então
+	baselines      map[string]int64
+	net            map[string]int64
+	lastCountCheck time.Time
+
+	ddlChecked map[int]string // último DDL verificado por object_id
+}
+
+func newTruncateDetector(cfg *config.Config) truncateDetector {
+	methods := config.SplitList(strings.ToLower(cfg.TruncateDetection))
+	d := truncateDetector{
+		ddl:        slices.Contains(methods, TruncateDetectDDL),
+		rowCount:   slices.Contains(methods, TruncateDetectRowCount),
+		dropPct:    cfg.TruncateRowCountDropPct,
+		resnapshot: cfg.TruncateResnapshot,
+		baselines:  make(map[string]int64),
+		net:        make(map[string]int64),
+		ddlChecked: make(map[int]string),
+	}
+	if slices.Contains(methods, TruncateDetectAudit) {
+		d.auditTable = strings.ToLower(strings.TrimSpace(cfg.TruncateAuditTable))
+		if d.auditTable == "" {
+			log.Warn().Msg("Truncate audit detection enabled without APP_GO_CDC_TRUNCATE_AUDIT_TABLE, ignoring")
+		}
+	}
+	if d.dropPct <= 0 || d.dropPct > 100 {
+		d.dropPct = static.APP_GO_CDC_TRUNCATE_ROWCOUNT_DROP_PCT
+	}
+	return d
+}
+
+// isInternalTable tabelas de controle lidas pelo stream mas nunca entregues ao handler
+func (s *Stream) isInternalTable(table string) bool {
+	return s.isSignalTable(table) || (s.truncate.auditTable != "" && strings.ToLower(table) == s.truncate.auditTable)
+}
+
+// truncateEvent cria o evento de truncate da capture instance na posição informada
+func (s *Stream) truncateEvent(ci *sqlserver.CaptureInstance, position event.Position, commitTime time.Time) event.Event {
+	tableSchema, _ := s.history.Latest(ci.FullTableName())
+	return event.Event{
+		Op:              event.OpTruncate,
+		Database:        s.replica.DatabaseName,
+		Schema:          ci.SourceSchema,
+		Table:           ci.SourceTable,
+		CaptureInstance: ci.Name,
+		Position:        position,
+		CommitTime:      commitTime,
+		KeyColumns:      s.keys[ci.Name],
+		TableSchema:     tableSchema,
+	}
+}
+
+// detectDDLTruncates procura ALTER TABLE ... SWITCH no histórico de DDL da
+// tabela entre afterLSN e ddlLSN (último DDL da tabela)
+func (s *Stream) detectDDLTruncates(ctx context.Context, ci *sqlserver.CaptureInstance, afterLSN string, ddlLSN string, maxLSN string) static.ErrorUtil {
+	// um ciclo que falhou depois desta verificação não gera eventos duplicados
+	afterLSN = max(afterLSN, s.truncate.ddlChecked[ci.SourceObjectID])
+	if !s.truncate.ddl || afterLSN == "" || afterLSN >= ddlLSN {
+		return nil
+	}
+
+	commands, errDDL := s.sqlServer.GetDDLCommands(ctx, ci.SourceObjectID, afterLSN)
+	if errDDL != nil {
+		return errDDL
+	}
+	for _, cmd := range commands {
+		if !isPartitionSwitch(cmd.Command) {
+			continue
+		}
+		lsn := min(cmd.LSN, maxLSN)
+		log.Warn().Str("table", ci.FullTableName()).Str("ddl_lsn", cmd.LSN).Msg("Partition switch detected, emitting truncate event")
+		s.pending = append(s.pending, s.truncateEvent(ci, event.Position{LSN: lsn}, cmd.Time))
+	}
+	s.truncate.ddlChecked[ci.SourceObjectID] = ddlLSN
+	return nil
+}
+
+// isPartitionSwitch indica se algum comando do DDL é um ALTER TABLE ... SWITCH
+func isPartitionSwitch(command string) bool {
+	for _, stmt := range strings.Split(stripSQL(command), ";") {
+		if switchPattern.MatchString(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripSQL remove os comentários e troca identificadores delimitados e
+// literais por um marcador, para que palavras dentro deles (ex.: uma coluna
+// [Switch]) não sejam lidas como instruções
+func stripSQL(sql string) string {
+	var b strings.Builder
+	for i := 0; i < len(sql); {
+		switch {
+		case strings.HasPrefix(sql[i:], "--"):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				end = len(sql) - i
+			}
+			i += end
+			b.WriteByte(' ')
+		case strings.HasPrefix(sql[i:], "/*"):
+			// comentários de bloco do T-SQL podem ser aninhados
+			depth := 0
+			for i < len(sql) {
+				if strings.HasPrefix(sql[i:], "/*") {
+					depth++
+					i += 2
+				} else if strings.HasPrefix(sql[i:], "*/") {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+			b.WriteByte(' ')
+		case sql[i] == '[':
+			i = skipQuoted(sql, i, ']')
+			b.WriteByte('x')
+		case sql[i] == '"' || sql[i] == '\'':
+			i = skipQuoted(sql, i, sql[i])
+			b.WriteByte('x')
+		default:
+			b.WriteByte(sql[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// skipQuoted retorna o índice seguinte ao delimitador que fecha o trecho
+// iniciado em start; o delimitador duplicado é um escape
+func skipQuoted(sql string, start int, closing byte) int {
+	for i := start + 1; i < len(sql); i++ {
+		if sql[i] != closing {
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == closing {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(sql)
+}
+
+// expandAuditTruncates gera um evento de truncate logo após cada insert na tabela
+// de auditoria (coluna table_name com schema.tabela), na mesma posição do log
+func (s *Stream) expandAuditTruncates(events []event.Event) []event.Event {
+	if s.truncate.auditTable == "" {
+		return events
+	}
+
+	var expanded []event.Event
+	for _, evt := range events {
+		expanded = append(expanded, evt)
+		if evt.Op != event.OpInsert || strings.ToLower(evt.FullTableName()) != s.truncate.auditTable {
+			continue
+		}
+
+		table := strings.ToLower(strings.TrimSpace(fmt.Sprint(evt.After["table_name"])))
+		ci := s.findInstance(table)
+		if ci == nil {
+			log.Warn().Str("table", table).Str("position", evt.Position.String()).Msg("Truncate audit row for a table not captured by this stream, ignoring")
+			continue
+		}
+		log.Warn().Str("table", ci.FullTableName()).Str("position", evt.Position.String()).Msg("Truncate recorded in audit table, emitting truncate event")
+		expanded = append(expanded, s.truncateEvent(ci, evt.Position, evt.CommitTime))
+	}
+	return expanded
+}
+
+// rowCountsDue lê as contagens de linhas quando a detecção por contagem está
+// ativa e o intervalo passou. Deve ser chamado antes de obter o LSN máximo do
+// ciclo, para que os eventos lidos no ciclo cubram as alterações já contadas.
+func (s *Stream) rowCountsDue(ctx context.Context) (map[int]int64, static.ErrorUtil) {
+	if !s.truncate.rowCount || time.Since(s.truncate.lastCountCheck) < rowCountCheckInterval {
+		return nil, nil
+	}
+	s.truncate.lastCountCheck = time.Now()
+	return s.sqlServer.GetRowCounts(ctx)
+}
+
+// trackRowCounts acumula o saldo de linhas dos eventos confirmados
+func (s *Stream) trackRowCounts(events []event.Event) {
+	if !s.truncate.rowCount {
+		return
+	}
+	for _, evt := range events {
+		switch evt.Op {
+		case event.OpInsert:
+			s.truncate.net[evt.CaptureInstance]++
+		case event.OpDelete:
+			s.truncate.net[evt.CaptureInstance]--
+		}
+	}
+}
+
+// checkRowCounts compara a contagem atual com a esperada (base + saldo de
+// eventos). Uma queda acima de APP_GO_CDC_TRUNCATE_ROWCOUNT_DROP_PCT indica
+// linhas removidas fora do log (truncate com CDC desabilitado, SWITCH, recarga).
+// É uma heurística: só é avaliada com o stream em dia com o log.
+func (s *Stream) checkRowCounts(counts map[int]int64, toLSN string) {
+	if counts == nil {
+		return
+	}
+
+	for i := range s.instances {
+		ci := &s.instances[i]
+		if s.isInternalTable(ci.FullTableName()) {
+			continue
+		}
+		actual, ok := counts[ci.SourceObjectID]
+		if !ok {
+			continue
+		}
+
+		baseline, known := s.truncate.baselines[ci.Name]
+		expected := baseline + s.truncate.net[ci.Name]
+		s.truncate.baselines[ci.Name] = actual
+		s.truncate.net[ci.Name] = 0
+		if !known || expected <= 0 {
+			continue
+		}
+
+		if actual*100 < expected*int64(100-s.truncate.dropPct) {
+			log.Warn().
+				Str("table", ci.FullTableName()).
+				Int64("expected_rows", expected).
+				Int64("actual_rows", actual).
+				Msg("Row count dropped without matching deletes, emitting truncate event")
+			s.pending = append(s.pending, s.truncateEvent(ci, event.Position{LSN: toLSN}, time.Now().UTC()))
+		}
+	}
+}
+
+// resnapshotTruncated agenda um novo snapshot das tabelas truncadas já entregues
+func (s *Stream) resnapshotTruncated(events []event.Event) {
+	if !s.truncate.resnapshot {
+		return
+	}
+	for _, evt := range events {
+		if evt.Op == event.OpTruncate {
+			s.scheduleSnapshot(evt.FullTableName(), "", "truncate@"+evt.Position.String())
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cdc
+
+import "testing"
+
+func TestIsPartitionSwitch(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    bool
+	}{
+		{"switch out", "ALTER TABLE dbo.Orders SWITCH PARTITION 2 TO dbo.OrdersArchive PARTITION 2", true},
+		{"switch in", "alter table staging.Orders switch to dbo.Orders partition 3", true},
+		{"quoted names", "ALTER TABLE [dbo].[Order Lines]\n\tSWITCH TO [archive].[Order Lines]", true},
+		{"database qualified", "ALTER TABLE Sales..Orders SWITCH TO Sales.dbo.OrdersArchive", true},
+		{"after leading comment", "/* arquivamento */ ALTER TABLE dbo.Orders SWITCH TO dbo.OrdersArchive", true},
+		{"second statement", "SET XACT_ABORT ON; ALTER TABLE dbo.Orders SWITCH TO dbo.OrdersArchive", true},
+
+		// coluna chamada Switch: um ADD comum não emite truncate
+		{"add switch column", "ALTER TABLE dbo.Devices ADD [Switch] bit NULL", false},
+		{"add quoted switch column", `ALTER TABLE dbo.Devices ADD "Switch" bit NULL`, false},
+		{"unquoted column named switch", "ALTER TABLE dbo.Devices ALTER COLUMN Switch int", false},
+		{"line comment", "ALTER TABLE dbo.Orders ADD Notes nvarchar(max) -- truncate antes de recarregar", false},
+		{"nested block comment", "ALTER TABLE dbo.Orders /* ver /* SWITCH */ e TRUNCATE */ ADD Notes int", false},
+		{"literal", "ALTER TABLE dbo.Orders ADD CONSTRAINT DF_Status DEFAULT N'SWITCH' FOR Status", false},
+		{"truncate", "TRUNCATE TABLE dbo.Orders", false},
+		{"escaped bracket", "ALTER TABLE dbo.Devices ADD [a]] SWITCH] bit", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPartitionSwitch(tt.command); got != tt.want {
+				t.Errorf("isPartitionSwitch(%q) = %v, want %v", tt.command, got, tt.want)
+			}
+		})
+	}
+}
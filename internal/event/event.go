@@ -0,0 +1,142 @@
+// internal/event/event.go
+package event
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go-cdc/internal/schema"
+)
+
+// Operation tipo da alteração capturada
+type Operation string
+
+const (
+	OpInsert Operation = "insert"
+	OpUpdate Operation = "update"
+	OpDelete Operation = "delete"
+	// OpSchema nova versão de schema registrada para a tabela (sem dados de linha)
+	OpSchema Operation = "schema"
+	// OpRead linha lida por um snapshot (não vem do log, não avança posições)
+	OpRead Operation = "read"
+	// OpTruncate tabela truncada ou recarregada em massa: o consumidor deve descartar sua cópia
+	OpTruncate Operation = "truncate"
+)
+
+// Position identifica de forma ordenável uma alteração no log do SQL Server.
+// LSN e SeqVal são binary(10) codificados em hex com largura fixa, então a
+// comparação lexicográfica equivale à comparação binária.
+type Position struct {
+	LSN    string `json:"lsn"`    // __$start_lsn (LSN de commit da transação)
+	SeqVal string `json:"seqval"` // __$seqval (ordem da operação dentro da transação)
+}
+
+// IsZero indica se a posição nunca foi definida
+func (p Position) IsZero() bool {
+	return p.LSN == "" && p.SeqVal == ""
+}
+
+// Compare retorna -1, 0 ou 1 comparando p com other
+func (p Position) Compare(other Position) int {
+	if c := strings.Compare(p.LSN, other.LSN); c != 0 {
+		return c
+	}
+	return strings.Compare(p.SeqVal, other.SeqVal)
+}
+
+func (p Position) String() string {
+	return p.LSN + ":" + p.SeqVal
+}
+
+// Event representa uma linha alterada em uma tabela capturada
+type Event struct {
+	Op              Operation              `json:"op"`
+	Database        string                 `json:"database"`
+	Schema          string                 `json:"schema"`
+	Table           string                 `json:"table"`
+	CaptureInstance string                 `json:"capture_instance"`
+	Position        Position               `json:"position"`
+	CommitTime      time.Time              `json:"commit_time"`
+	KeyColumns      []string               `json:"key_columns,omitempty"`
+	Key             map[string]interface{} `json:"key,omitempty"`
+	Before          map[string]interface{} `json:"before,omitempty"`
+	After           map[string]interface{} `json:"after,omitempty"`
+
+	// UpdatedColumns colunas alteradas em um update (de __$update_mask)
+	UpdatedColumns []string `json:"updated_columns,omitempty"`
+	// UnchangedColumns colunas LOB não alteradas no update, cujo valor o CDC não
+	// retorna (ausentes em After, não devem ser interpretadas como NULL)
+	UnchangedColumns []string `json:"unchanged_columns,omitempty"`
+	// TruncatedColumns colunas cujo valor foi truncado pela política de LOB
+	TruncatedColumns []string `json:"truncated_columns,omitempty"`
+
+	// Historical alteração reconstruída do histórico de uma tabela temporal,
+	// anterior ao CDC (LSN zero e a ordem no histórico em SeqVal, CommitTime =
+	// início/fim do período)
+	Historical bool `json:"historical,omitempty"`
+
+	// TableSchema versão do schema válida na posição do evento
+	TableSchema *schema.TableSchema `json:"-"`
+	// SchemaChange preenchido apenas em eventos OpSchema
+	SchemaChange *schema.TableSchema `json:"schema_change,omitempty"`
+}
+
+// FullTableName retorna schema.tabela
+func (e *Event) FullTableName() string {
+	return e.Schema + "." + e.Table
+}
+
+// FromLog indica eventos lidos das change tables. Eventos de schema, truncate,
+// snapshot e histórico temporal são gerados pelo go-cdc e não definem posição no log.
+func (e *Event) FromLog() bool {
+	return !e.Historical && (e.Op == OpInsert || e.Op == OpUpdate || e.Op == OpDelete)
+}
+
+// Row retorna a imagem atual da linha (After), ou Before para deletes
+func (e *Event) Row() map[string]interface{} {
+	if e.After != nil {
+		return e.After
+	}
+	return e.Before
+}
+
+// SetKey preenche a chave do evento com as colunas informadas, em ordem
+func (e *Event) SetKey(columns []string) {
+	if len(columns) == 0 {
+		return
+	}
+	row := e.Row()
+	e.KeyColumns = columns
+	e.Key = make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		e.Key[column] = row[column]
+	}
+}
+
+// MessageKey serializa a chave como objeto JSON respeitando a ordem das colunas,
+// garantindo bytes estáveis para particionamento. Retorna nil se não houver chave.
+func (e *Event) MessageKey() []byte {
+	if len(e.KeyColumns) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range e.KeyColumns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(column)
+		value, err := json.Marshal(e.Key[column])
+		if err != nil {
+			value = []byte("null")
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
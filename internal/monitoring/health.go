@@ -18,6 +18,7 @@ type HealthChecker interface {
 type HealthMonitor struct {
 	cfg           *config.Config
 	healthChecker HealthChecker
+	checkType     string
 }
 
 // NewHealthMonitor cria um monitor com todas as dependências injetadas
@@ -25,11 +26,19 @@ func NewHealthMonitor(cfg *config.Config, checker HealthChecker) *HealthMonitor
 	return &HealthMonitor{
 		cfg:           cfg,
 		healthChecker: checker,
+		checkType:     "database",
 	}
 }
 
+// WithCheckType define o rótulo do check nos logs (padrão: database)
+func (h *HealthMonitor) WithCheckType(checkType string) *HealthMonitor {
+	h.checkType = checkType
+	return h
+}
+
 func (h *HealthMonitor) Start(ctx context.Context) {
 	log.Info().
+		Str("check_type", h.checkType).
 		Dur("interval", time.Duration(h.cfg.HealthCheckIntervalSeconds)*time.Second).
 		Msg("Health monitor started")
 
@@ -60,7 +69,7 @@ func (h *HealthMonitor) check(ctx context.Context) {
 	}
 
 	logEvent.
-		Str("check_type", "database").
+		Str("check_type", h.checkType).
 		Str("db_host", h.cfg.DBHost).
 		Str("db_name", h.cfg.DBName).
 		Float64("duration_seconds", duration.Seconds()).
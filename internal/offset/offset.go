@@ -0,0 +1,102 @@
+// internal/offset/offset.go
+package offset
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Store persiste a última posição confirmada de cada capture instance
+type Store interface {
+	Load() (map[string]event.Position, static.ErrorUtil)
+	Save(positions map[string]event.Position) static.ErrorUtil
+}
+
+// FileStore grava as posições em um arquivo JSON com escrita atômica
+type FileStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileStore cria um store baseado em arquivo (construtor)
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load lê as posições salvas; arquivo inexistente significa início do zero
+func (f *FileStore) Load() (map[string]event.Position, static.ErrorUtil) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	positions := make(map[string]event.Position)
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Info().Str("offset_file", f.path).Msg("No stored offsets found, starting from the beginning")
+		return positions, nil
+	}
+	if err != nil {
+		log.Error().Caller().Err(err).Str("offset_file", f.path).Msg("Failed to read offsets file")
+		return nil, static.NewErrorUtil("Failed to read offsets file", "OFFSET_LOAD_FAILED", err, err.Error())
+	}
+
+	if err := json.Unmarshal(data, &positions); err != nil {
+		log.Error().Caller().Err(err).Str("offset_file", f.path).Msg("Failed to decode offsets file")
+		return nil, static.NewErrorUtil("Failed to decode offsets file", "OFFSET_DECODE_FAILED", err, err.Error())
+	}
+
+	return positions, nil
+}
+
+// Save grava em arquivo temporário, faz fsync e renomeia sobre o arquivo final
+func (f *FileStore) Save(positions map[string]event.Position) static.ErrorUtil {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.MarshalIndent(positions, "", "  ")
+	if err != nil {
+		return static.NewErrorUtil("Failed to encode offsets", "OFFSET_SAVE_FAILED", err, err.Error())
+	}
+
+	if err := writeFileAtomic(f.path, data); err != nil {
+		log.Error().Caller().Err(err).Str("offset_file", f.path).Msg("Failed to save offsets")
+		return static.NewErrorUtil("Failed to save offsets", "OFFSET_SAVE_FAILED", err, err.Error())
+	}
+
+	return nil
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,127 @@
+// internal/pipeline/runner.go
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"go-cdc/internal/cdc"
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/sink"
+	"go-cdc/internal/transform"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// closeTimeout limita o flush final dos sinks no shutdown
+const closeTimeout = 30 * time.Second
+
+// Source origem dos eventos (implementada por cdc.Stream)
+type Source interface {
+	Run(ctx context.Context, handler cdc.Handler) static.ErrorUtil
+	OnCommit(fn cdc.CommitFunc)
+}
+
+// Runner conecta a origem, as transformações e os sinks
+type Runner struct {
+	cfg        *config.Config
+	source     Source
+	transforms transform.Chain
+	sinks      []sink.Sink
+}
+
+// NewRunner cria o pipeline com todas as dependências injetadas
+func NewRunner(cfg *config.Config, source Source, transforms transform.Chain, sinks []sink.Sink) *Runner {
+	r := &Runner{
+		cfg:        cfg,
+		source:     source,
+		transforms: transforms,
+		sinks:      sinks,
+	}
+	source.OnCommit(r.ack)
+	return r
+}
+
+// Run executa a origem até o contexto ser cancelado ou ocorrer um erro fatal e
+// fecha os sinks ao final
+func (r *Runner) Run(ctx context.Context) static.ErrorUtil {
+	names := make([]string, len(r.sinks))
+	for i, s := range r.sinks {
+		names[i] = s.Name()
+	}
+	log.Info().Strs("sinks", names).Int("transforms", len(r.transforms)).Msg("Pipeline started")
+
+	errRun := r.source.Run(ctx, r.handle)
+	r.close()
+
+	if errRun != nil {
+		log.Error().Err(errRun).Str("code", errRun.Code()).Msg("Pipeline stopped with error")
+		return errRun
+	}
+	log.Info().Msg("Pipeline stopped")
+	return nil
+}
+
+// handle aplica as transformações e entrega o lote a todos os sinks. Só retorna
+// sem erro quando todos confirmaram (Flush), liberando a origem para gravar os offsets.
+func (r *Runner) handle(ctx context.Context, events []event.Event) static.ErrorUtil {
+	events, errTransform := r.transforms.Apply(ctx, events)
+	if errTransform != nil {
+		return errTransform
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	for _, s := range r.sinks {
+		if errWrite := s.Write(ctx, events); errWrite != nil {
+			log.Error().Err(errWrite).Str("sink", s.Name()).Int("events", len(events)).Msg("Sink write failed")
+			return errWrite
+		}
+	}
+	for _, s := range r.sinks {
+		if errFlush := s.Flush(ctx); errFlush != nil {
+			log.Error().Err(errFlush).Str("sink", s.Name()).Msg("Sink flush failed")
+			return errFlush
+		}
+	}
+	return nil
+}
+
+// ack repassa aos sinks as posições persistidas pela origem
+func (r *Runner) ack(ctx context.Context, positions map[string]event.Position) {
+	for _, s := range r.sinks {
+		if errAck := s.Ack(ctx, positions); errAck != nil {
+			log.Warn().Err(errAck).Str("sink", s.Name()).Msg("Sink ack failed")
+		}
+	}
+}
+
+// HealthCheck implementa monitoring.HealthChecker verificando todos os sinks
+func (r *Runner) HealthCheck(ctx context.Context, cfg *config.Config) static.ErrorUtil {
+	for _, s := range r.sinks {
+		if errHealth := s.HealthCheck(ctx); errHealth != nil {
+			log.Error().Err(errHealth).Str("sink", s.Name()).Msg("Sink health check failed")
+			return errHealth
+		}
+	}
+	return nil
+}
+
+// close faz o flush final e fecha os sinks com um contexto próprio, já que o
+// contexto do pipeline está cancelado no shutdown
+func (r *Runner) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+
+	for _, s := range r.sinks {
+		if errFlush := s.Flush(ctx); errFlush != nil {
+			log.Error().Err(errFlush).Str("sink", s.Name()).Msg("Final sink flush failed")
+		}
+		if errClose := s.Close(); errClose != nil {
+			log.Error().Err(errClose).Str("sink", s.Name()).Msg("Failed to close sink")
+		}
+	}
+}
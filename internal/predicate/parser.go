@@ -0,0 +1,327 @@
+// internal/predicate/parser.go
+package predicate
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokKeyword
+	tokNumber
+	tokString
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string // keywords em maiúsculas; strings sem aspas
+	pos  int
+}
+
+var keywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true,
+	"IN": true, "LIKE": true, "BETWEEN": true,
+}
+
+// lex divide o predicado em tokens. Qualquer construção fora do subconjunto
+// (subconsultas, funções, comentários, ';') é rejeitada aqui ou no parser.
+func lex(raw string) ([]token, error) {
+	runes := []rune(raw)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		char := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(char):
+			i++
+			continue
+		case char == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: start})
+			i++
+		case char == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: start})
+			i++
+		case char == ',':
+			tokens = append(tokens, token{kind: tokComma, text: ",", pos: start})
+			i++
+		case char == '[':
+			// identificador delimitado: ]] representa ]
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated identifier at position %d", start)
+				}
+				if runes[i] == ']' {
+					if i+1 < len(runes) && runes[i+1] == ']' {
+						sb.WriteRune(']')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: sb.String(), pos: start})
+		case char == '\'' || ((char == 'N' || char == 'n') && i+1 < len(runes) && runes[i+1] == '\''):
+			if char != '\'' {
+				i++
+			}
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("unterminated string at position %d", start)
+				}
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						sb.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokString, text: sb.String(), pos: start})
+		case unicode.IsDigit(char) || ((char == '-' || char == '.') && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: string(runes[start:i]), pos: start})
+		case unicode.IsLetter(char) || char == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			word := string(runes[start:i])
+			if keywords[strings.ToUpper(word)] {
+				tokens = append(tokens, token{kind: tokKeyword, text: strings.ToUpper(word), pos: start})
+			} else {
+				tokens = append(tokens, token{kind: tokIdent, text: word, pos: start})
+			}
+		case strings.ContainsRune("=<>!", char):
+			i++
+			if i < len(runes) && strings.ContainsRune("=>", runes[i]) {
+				i++
+			}
+			op := string(runes[start:i])
+			switch op {
+			case "=", "<>", "!=", "<", "<=", ">", ">=":
+			default:
+				return nil, fmt.Errorf("invalid operator %q at position %d", op, start)
+			}
+			tokens = append(tokens, token{kind: tokOperator, text: op, pos: start})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", char, start)
+		}
+	}
+
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) done() bool {
+	return p.peek().kind == tokEOF
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if tok := p.peek(); tok.kind == tokKeyword && tok.text == keyword {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) unexpected() error {
+	tok := p.peek()
+	if tok.kind == tokEOF {
+		return fmt.Errorf("unexpected end of predicate")
+	}
+	return fmt.Errorf("unexpected %q at position %d", tok.text, tok.pos)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.acceptKeyword("NOT") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokRParen {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return inner, nil
+	}
+	return p.parseCondition()
+}
+
+func (p *parser) parseCondition() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind == tokOperator {
+		p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: tok.text, left: left, right: right}, nil
+	}
+
+	if p.acceptKeyword("IS") {
+		negate := p.acceptKeyword("NOT")
+		if !p.acceptKeyword("NULL") {
+			return nil, p.unexpected()
+		}
+		return isNullNode{operand: left, negate: negate}, nil
+	}
+
+	negate := p.acceptKeyword("NOT")
+	switch {
+	case p.acceptKeyword("IN"):
+		if p.next().kind != tokLParen {
+			return nil, fmt.Errorf("IN requires a parenthesized list")
+		}
+		var list []operand
+		for {
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			if p.peek().kind == tokComma {
+				p.next()
+				continue
+			}
+			if p.next().kind != tokRParen {
+				return nil, fmt.Errorf("missing closing parenthesis in IN list")
+			}
+			break
+		}
+		return inNode{operand: left, list: list, negate: negate}, nil
+	case p.acceptKeyword("LIKE"):
+		tok := p.next()
+		if tok.kind != tokString {
+			return nil, fmt.Errorf("LIKE requires a string pattern")
+		}
+		return likeNode{operand: left, pattern: tok.text, negate: negate}, nil
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptKeyword("AND") {
+			return nil, p.unexpected()
+		}
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		var between node = andNode{
+			compareNode{op: ">=", left: left, right: low},
+			compareNode{op: "<=", left: left, right: high},
+		}
+		if negate {
+			between = notNode{between}
+		}
+		return between, nil
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokIdent:
+		p.next()
+		return operand{column: tok.text}, nil
+	case tokString:
+		p.next()
+		return operand{value: tok.text}, nil
+	case tokNumber:
+		p.next()
+		value, ok := new(big.Rat).SetString(tok.text)
+		if !ok {
+			return operand{}, fmt.Errorf("invalid number %q at position %d", tok.text, tok.pos)
+		}
+		return operand{value: value}, nil
+	case tokKeyword:
+		if tok.text == "NULL" {
+			p.next()
+			return operand{}, nil
+		}
+	}
+	return operand{}, p.unexpected()
+}
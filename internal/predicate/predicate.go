@@ -0,0 +1,344 @@
+// internal/predicate/predicate.go
+package predicate
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Predicate expressão booleana no subconjunto de T-SQL aceito em filtros de linha:
+//
+//	coluna = | <> | != | < | <= | > | >= valor
+//	coluna IS [NOT] NULL, coluna [NOT] IN (v1, v2), coluna [NOT] LIKE 'a%'
+//	coluna [NOT] BETWEEN v1 AND v2, combinados com AND, OR, NOT e parênteses
+//
+// O mesmo texto é avaliado em memória sobre os eventos e enviado ao SQL Server
+// nos SELECTs de snapshot, então a avaliação segue a semântica do SQL Server:
+// lógica de três valores com NULL e comparação de texto sem diferenciar
+// maiúsculas nem espaços à direita (collation padrão *_CI_AS).
+type Predicate struct {
+	raw  string
+	root node
+}
+
+// Parse valida e compila o predicado
+func Parse(raw string) (*Predicate, error) {
+	tokens, err := lex(raw)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+	return &Predicate{raw: strings.TrimSpace(raw), root: root}, nil
+}
+
+// String retorna o texto original, usado no WHERE do SQL Server
+func (p *Predicate) String() string {
+	return p.raw
+}
+
+// Match indica se a linha satisfaz o predicado (UNKNOWN conta como falso, como no WHERE)
+func (p *Predicate) Match(row map[string]interface{}) bool {
+	return p.root.eval(row) == truthTrue
+}
+
+// truth valor da lógica de três valores do SQL
+type truth int
+
+const (
+	truthFalse truth = iota
+	truthTrue
+	truthUnknown
+)
+
+func truthOf(b bool) truth {
+	if b {
+		return truthTrue
+	}
+	return truthFalse
+}
+
+func (t truth) not() truth {
+	switch t {
+	case truthTrue:
+		return truthFalse
+	case truthFalse:
+		return truthTrue
+	}
+	return truthUnknown
+}
+
+type node interface {
+	eval(row map[string]interface{}) truth
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(row map[string]interface{}) truth {
+	l, r := n.left.eval(row), n.right.eval(row)
+	switch {
+	case l == truthFalse || r == truthFalse:
+		return truthFalse
+	case l == truthTrue && r == truthTrue:
+		return truthTrue
+	}
+	return truthUnknown
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(row map[string]interface{}) truth {
+	l, r := n.left.eval(row), n.right.eval(row)
+	switch {
+	case l == truthTrue || r == truthTrue:
+		return truthTrue
+	case l == truthFalse && r == truthFalse:
+		return truthFalse
+	}
+	return truthUnknown
+}
+
+type notNode struct{ inner node }
+
+func (n notNode) eval(row map[string]interface{}) truth {
+	return n.inner.eval(row).not()
+}
+
+// operand coluna ou literal
+type operand struct {
+	column string
+	value  interface{} // string, *big.Rat ou nil (NULL)
+}
+
+func (o operand) resolve(row map[string]interface{}) interface{} {
+	if o.column == "" {
+		return o.value
+	}
+	if value, ok := row[o.column]; ok {
+		return value
+	}
+	// identificadores não diferenciam maiúsculas no SQL Server
+	for name, value := range row {
+		if strings.EqualFold(name, o.column) {
+			return value
+		}
+	}
+	return nil
+}
+
+type compareNode struct {
+	op          string
+	left, right operand
+}
+
+func (n compareNode) eval(row map[string]interface{}) truth {
+	c, ok := compare(n.left.resolve(row), n.right.resolve(row))
+	if !ok {
+		return truthUnknown
+	}
+	switch n.op {
+	case "=":
+		return truthOf(c == 0)
+	case "<>", "!=":
+		return truthOf(c != 0)
+	case "<":
+		return truthOf(c < 0)
+	case "<=":
+		return truthOf(c <= 0)
+	case ">":
+		return truthOf(c > 0)
+	case ">=":
+		return truthOf(c >= 0)
+	}
+	return truthUnknown
+}
+
+type isNullNode struct {
+	operand operand
+	negate  bool
+}
+
+func (n isNullNode) eval(row map[string]interface{}) truth {
+	return truthOf((n.operand.resolve(row) == nil) != n.negate)
+}
+
+type inNode struct {
+	operand operand
+	list    []operand
+	negate  bool
+}
+
+func (n inNode) eval(row map[string]interface{}) truth {
+	value := n.operand.resolve(row)
+	result := truthFalse
+	for _, item := range n.list {
+		c, ok := compare(value, item.resolve(row))
+		if !ok {
+			result = truthUnknown
+			continue
+		}
+		if c == 0 {
+			result = truthTrue
+			break
+		}
+	}
+	if n.negate {
+		return result.not()
+	}
+	return result
+}
+
+type likeNode struct {
+	operand operand
+	pattern string
+	negate  bool
+}
+
+func (n likeNode) eval(row map[string]interface{}) truth {
+	value := n.operand.resolve(row)
+	if value == nil {
+		return truthUnknown
+	}
+	text, ok := value.(string)
+	if !ok {
+		text = fmt.Sprint(value)
+	}
+	matched := like([]rune(strings.ToLower(text)), []rune(strings.ToLower(n.pattern)))
+	return truthOf(matched != n.negate)
+}
+
+// like implementa % e _ (sem classes [..] nem ESCAPE)
+func like(text []rune, pattern []rune) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '%':
+			for len(pattern) > 0 && pattern[0] == '%' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(text); i++ {
+				if like(text[i:], pattern) {
+					return true
+				}
+			}
+			return false
+		case '_':
+			if len(text) == 0 {
+				return false
+			}
+		default:
+			if len(text) == 0 || text[0] != pattern[0] {
+				return false
+			}
+		}
+		text, pattern = text[1:], pattern[1:]
+	}
+	return len(text) == 0
+}
+
+// Formatos aceitos em literais de data comparados com colunas de data/hora
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// compare retorna -1, 0 ou 1; ok=false quando algum lado é NULL ou os tipos não são comparáveis
+func compare(a interface{}, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+
+	if ta, isTime := a.(time.Time); isTime {
+		return compareTime(ta, b)
+	}
+	if tb, isTime := b.(time.Time); isTime {
+		c, ok := compareTime(tb, a)
+		return -c, ok
+	}
+
+	ra, aNumeric := toRat(a)
+	rb, bNumeric := toRat(b)
+	_, aString := a.(string)
+	_, bString := b.(string)
+	switch {
+	case aNumeric && bNumeric && !(aString && bString):
+		return ra.Cmp(rb), true
+	case aString && bString:
+		return compareText(a.(string), b.(string)), true
+	}
+	return 0, false
+}
+
+func compareTime(t time.Time, other interface{}) (int, bool) {
+	switch v := other.(type) {
+	case time.Time:
+		return t.Compare(v), true
+	case string:
+		for _, layout := range timeLayouts {
+			if parsed, err := time.Parse(layout, v); err == nil {
+				return t.Compare(parsed), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// compareText compara como a collation padrão: sem diferenciar maiúsculas e ignorando espaços à direita
+func compareText(a string, b string) int {
+	return strings.Compare(
+		strings.ToLower(strings.TrimRightFunc(a, unicode.IsSpace)),
+		strings.ToLower(strings.TrimRightFunc(b, unicode.IsSpace)),
+	)
+}
+
+// toRat converte tipos numéricos (e decimais normalizados como texto) para comparação exata
+func toRat(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case *big.Rat:
+		return n, true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case uint8:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case float64:
+		r := new(big.Rat)
+		if r.SetFloat64(n) == nil {
+			return nil, false
+		}
+		return r, true
+	case float32:
+		r := new(big.Rat)
+		if r.SetFloat64(float64(n)) == nil {
+			return nil, false
+		}
+		return r, true
+	case bool:
+		if n {
+			return big.NewRat(1, 1), true
+		}
+		return new(big.Rat), true
+	case string:
+		return new(big.Rat).SetString(strings.TrimSpace(n))
+	}
+	return nil, false
+}
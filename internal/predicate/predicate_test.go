@@ -0,0 +1,143 @@
+package predicate
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	row := map[string]interface{}{
+		"TenantId":  int64(42),
+		"Status":    "ACTIVE  ",
+		"Amount":    big.NewRat(1999, 100), // decimal 19.99
+		"Price":     "10.50",               // decimal normalizado como texto
+		"Name":      "O'Brien",
+		"Region":    nil,
+		"Deleted":   false,
+		"CreatedAt": time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		predicate string
+		want      bool
+	}{
+		// comparações
+		{"TenantId = 42", true},
+		{"tenantid = 42", true},
+		{"[TenantId] <> 42", false},
+		{"TenantId != 41", true},
+		{"Amount > 19.98 AND Amount < 20", true},
+		{"Amount >= 19.99 AND Amount <= 19.99", true},
+		{"Price = 10.5", true},
+		{"Status = 'active'", true},
+		{"Status = N'ACTIVE'", true},
+		{"Name = 'O''Brien'", true},
+		{"Deleted = 0", true},
+		{"CreatedAt >= '2024-05-01' AND CreatedAt < '2024-05-02T00:00:00Z'", true},
+		{"TenantId = 'abc'", false},
+
+		// precedência: NOT > AND > OR
+		{"TenantId = 1 OR TenantId = 42 AND Status = 'ACTIVE'", true},
+		{"TenantId = 42 OR TenantId = 1 AND Status = 'X'", true},
+		{"(TenantId = 42 OR TenantId = 1) AND Status = 'X'", false},
+		{"NOT TenantId = 1 AND Status = 'X'", false},
+		{"NOT (TenantId = 1 AND Status = 'X')", true},
+		{"NOT NOT TenantId = 42", true},
+
+		// NULL: lógica de três valores, UNKNOWN não satisfaz o WHERE
+		{"Region IS NULL", true},
+		{"Region IS NOT NULL", false},
+		{"Missing IS NULL", true},
+		{"Region = 'EU'", false},
+		{"Region <> 'EU'", false},
+		{"NOT Region = 'EU'", false},
+		{"Region = NULL", false},
+		{"Region = 'EU' OR TenantId = 42", true},
+		{"Region = 'EU' OR TenantId = 1", false},
+		{"NOT (Region = 'EU' AND TenantId = 1)", true},
+		{"NOT (Region = 'EU' OR TenantId = 1)", false},
+
+		// IN
+		{"TenantId IN (1, 42, 7)", true},
+		{"TenantId NOT IN (1, 2)", true},
+		{"Status IN ('active', 'pending')", true},
+		{"TenantId IN (1, NULL)", false},
+		{"TenantId NOT IN (1, NULL)", false},
+		{"TenantId IN (42, NULL)", true},
+		{"Region IN ('EU')", false},
+		{"Region NOT IN ('EU')", false},
+
+		// LIKE
+		{"Name LIKE 'o''%'", true},
+		{"Name LIKE '%bri%'", true},
+		{"Name LIKE 'O_Brien'", true},
+		{"Name LIKE 'O_Bri'", false},
+		{"Name NOT LIKE 'X%'", true},
+		{"Region LIKE '%'", false},
+		{"Region NOT LIKE 'EU'", false},
+		{"TenantId LIKE '4%'", true},
+
+		// BETWEEN
+		{"TenantId BETWEEN 40 AND 42", true},
+		{"TenantId BETWEEN 43 AND 50", false},
+		{"TenantId NOT BETWEEN 43 AND 50", true},
+		{"TenantId BETWEEN 40 AND 50 AND Status = 'ACTIVE'", true},
+		{"Region BETWEEN 'A' AND 'Z'", false},
+		{"Region NOT BETWEEN 'A' AND 'Z'", false},
+		{"CreatedAt BETWEEN '2024-01-01' AND '2024-12-31'", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.predicate, func(t *testing.T) {
+			p, err := Parse(tt.predicate)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := p.Match(row); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"TenantId",
+		"TenantId = ",
+		"TenantId = 1 AND",
+		"(TenantId = 1",
+		"TenantId = 1)",
+		"TenantId = 1; DROP TABLE Orders",
+		"TenantId = 1 -- comment",
+		"Status = 'open",
+		"[Status = 'x'",
+		"TenantId IN 1, 2",
+		"TenantId IN (1, 2",
+		"Name LIKE Other",
+		"TenantId BETWEEN 1 OR 2",
+		"TenantId IS 1",
+		"LEN(Name) > 3",
+		"TenantId = (SELECT 1)",
+		"TenantId == 1",
+	}
+
+	for _, raw := range tests {
+		t.Run(raw, func(t *testing.T) {
+			if p, err := Parse(raw); err == nil {
+				t.Fatalf("expected error, parsed %q", p.String())
+			}
+		})
+	}
+}
+
+func TestStringKeepsSQLText(t *testing.T) {
+	p, err := Parse("  Status = 'a;b' AND [Order Id] > 10  ")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := p.String(); got != "Status = 'a;b' AND [Order Id] > 10" {
+		t.Errorf("String = %q", got)
+	}
+}
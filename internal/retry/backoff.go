@@ -0,0 +1,47 @@
+// internal/retry/backoff.go
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff calcula esperas exponenciais limitadas com jitter
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+// NewBackoff cria um backoff exponencial (multiplicador 2)
+func NewBackoff(initial time.Duration, max time.Duration) Backoff {
+	return Backoff{Initial: initial, Max: max, Multiplier: 2}
+}
+
+// Duration retorna a espera para a tentativa (0 = primeira repetição),
+// com jitter de até 20% para evitar reconexões sincronizadas entre pods
+func (b Backoff) Duration(attempt int) time.Duration {
+	d := float64(b.Initial)
+	for i := 0; i < attempt && d < float64(b.Max); i++ {
+		d *= b.Multiplier
+	}
+	if d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	jitter := d * 0.2 * rand.Float64()
+	return time.Duration(d - jitter)
+}
+
+// Sleep aguarda d ou até o contexto ser cancelado
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,173 @@
+// internal/schema/history.go
+package schema
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// History guarda as versões de schema de cada tabela ao longo do log
+type History interface {
+	// Record persiste a versão se ela difere da mais recente da tabela
+	Record(version TableSchema) (bool, static.ErrorUtil)
+	// SchemaAt retorna a versão válida para a tabela no LSN informado
+	SchemaAt(table string, lsn string) (*TableSchema, bool)
+	// Latest retorna a versão mais recente da tabela
+	Latest(table string) (*TableSchema, bool)
+}
+
+// FileHistory armazena as versões em um arquivo JSON Lines append-only
+type FileHistory struct {
+	path     string
+	mu       sync.RWMutex
+	versions map[string][]*TableSchema // ordenadas por ValidFrom
+}
+
+// NewFileHistory carrega o histórico existente do arquivo (construtor)
+func NewFileHistory(path string) (*FileHistory, static.ErrorUtil) {
+	h := &FileHistory{
+		path:     path,
+		versions: make(map[string][]*TableSchema),
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		log.Error().Caller().Err(err).Str("schema_history_file", path).Msg("Failed to open schema history")
+		return nil, static.NewErrorUtil("Failed to open schema history", "SCHEMA_HISTORY_LOAD_FAILED", err, err.Error())
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var version TableSchema
+		if err := json.Unmarshal(line, &version); err != nil {
+			log.Error().Caller().Err(err).Str("schema_history_file", path).Msg("Failed to decode schema history entry")
+			return nil, static.NewErrorUtil("Failed to decode schema history", "SCHEMA_HISTORY_DECODE_FAILED", err, err.Error())
+		}
+		h.insert(version)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, static.NewErrorUtil("Failed to read schema history", "SCHEMA_HISTORY_LOAD_FAILED", err, err.Error())
+	}
+
+	log.Info().Str("schema_history_file", path).Int("tables", len(h.versions)).Msg("Schema history loaded")
+	return h, nil
+}
+
+func historyKey(table string) string {
+	return strings.ToLower(table)
+}
+
+// insert guarda a versão em um ponteiro próprio: os sinks indexam caches pelos
+// ponteiros devolvidos por SchemaAt/Latest, que não podem mudar de conteúdo
+// quando a lista é reordenada
+func (h *FileHistory) insert(version TableSchema) {
+	key := historyKey(version.Table)
+	versions := append(h.versions[key], &version)
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].ValidFrom < versions[j].ValidFrom
+	})
+	h.versions[key] = versions
+}
+
+// Record acrescenta a versão ao arquivo (com fsync) e ao índice em memória
+func (h *FileHistory) Record(version TableSchema) (bool, static.ErrorUtil) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if versions := h.versions[historyKey(version.Table)]; len(versions) > 0 {
+		latest := versions[len(versions)-1]
+		if latest.SameDefinition(&version) && latest.CaptureInstance == version.CaptureInstance {
+			return false, nil
+		}
+	}
+
+	line, err := json.Marshal(version)
+	if err != nil {
+		return false, static.NewErrorUtil("Failed to encode schema version", "SCHEMA_HISTORY_SAVE_FAILED", err, err.Error())
+	}
+	if err := appendLine(h.path, line); err != nil {
+		log.Error().Caller().Err(err).Str("schema_history_file", h.path).Msg("Failed to save schema version")
+		return false, static.NewErrorUtil("Failed to save schema version", "SCHEMA_HISTORY_SAVE_FAILED", err, err.Error())
+	}
+
+	h.insert(version)
+	log.Info().
+		Str("table", version.Table).
+		Str("capture_instance", version.CaptureInstance).
+		Str("valid_from_lsn", version.ValidFrom).
+		Int("columns", len(version.Columns)).
+		Msg("Schema version recorded")
+	return true, nil
+}
+
+// SchemaAt retorna a última versão com ValidFrom <= lsn. LSNs anteriores à
+// primeira versão conhecida usam a primeira versão.
+func (h *FileHistory) SchemaAt(table string, lsn string) (*TableSchema, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	versions := h.versions[historyKey(table)]
+	if len(versions) == 0 {
+		return nil, false
+	}
+
+	idx := sort.Search(len(versions), func(i int) bool {
+		return versions[i].ValidFrom > lsn
+	})
+	if idx == 0 {
+		return versions[0], true
+	}
+	return versions[idx-1], true
+}
+
+// Latest retorna a versão mais recente da tabela
+func (h *FileHistory) Latest(table string) (*TableSchema, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	versions := h.versions[historyKey(table)]
+	if len(versions) == 0 {
+		return nil, false
+	}
+	return versions[len(versions)-1], true
+}
+
+func appendLine(path string, line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
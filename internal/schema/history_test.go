@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFileHistoryPointersStableAcrossInserts(t *testing.T) {
+	h, errUtil := NewFileHistory(filepath.Join(t.TempDir(), "history.jsonl"))
+	if errUtil != nil {
+		t.Fatalf("NewFileHistory: %v", errUtil)
+	}
+
+	record := func(validFrom string, column string) {
+		t.Helper()
+		version := TableSchema{
+			Table:           "dbo.Orders",
+			CaptureInstance: "dbo_Orders",
+			ValidFrom:       validFrom,
+			Columns:         []Column{{Name: column, Type: "int", Ordinal: 1, Captured: true}},
+		}
+		if _, errUtil := h.Record(version); errUtil != nil {
+			t.Fatalf("Record: %v", errUtil)
+		}
+	}
+
+	record("00000000000000000020", "v2")
+	latest, ok := h.Latest("dbo.orders")
+	if !ok || latest.Columns[0].Name != "v2" {
+		t.Fatalf("Latest = %+v", latest)
+	}
+
+	// versão mais antiga entra antes na lista ordenada
+	record("00000000000000000010", "v1")
+	record("00000000000000000030", "v3")
+
+	if latest.Columns[0].Name != "v2" || latest.ValidFrom != "00000000000000000020" {
+		t.Fatalf("pointer returned by Latest changed to %+v", latest)
+	}
+	at, _ := h.SchemaAt("dbo.Orders", "00000000000000000025")
+	if at != latest {
+		t.Fatalf("SchemaAt returned a different pointer for the same version")
+	}
+	if first, _ := h.SchemaAt("dbo.Orders", "00000000000000000001"); first.Columns[0].Name != "v1" {
+		t.Fatalf("SchemaAt before first version = %+v", first)
+	}
+
+	reloaded, errUtil := NewFileHistory(h.path)
+	if errUtil != nil {
+		t.Fatalf("reload: %v", errUtil)
+	}
+	if last, _ := reloaded.Latest("dbo.Orders"); last.Columns[0].Name != "v3" {
+		t.Fatalf("reloaded Latest = %+v", last)
+	}
+}
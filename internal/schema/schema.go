@@ -0,0 +1,105 @@
+// internal/schema/schema.go
+package schema
+
+// Column definição de uma coluna da tabela de origem
+type Column struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	MaxLength int    `json:"max_length"`
+	Precision int    `json:"precision"`
+	Scale     int    `json:"scale"`
+	Nullable  bool   `json:"nullable"`
+	Ordinal   int    `json:"ordinal"`
+	Collation string `json:"collation,omitempty"`
+	// Captured indica se a coluna está no @captured_column_list da capture instance.
+	// Colunas não capturadas nunca aparecem nos eventos (ausência != NULL).
+	Captured bool `json:"captured"`
+
+	// Metadados de sys.columns: colunas calculadas, identity e rowversion não
+	// aceitam valores em INSERT/UPDATE no destino
+	Computed           bool   `json:"computed,omitempty"`
+	Persisted          bool   `json:"persisted,omitempty"`
+	ComputedDefinition string `json:"computed_definition,omitempty"`
+	Sparse             bool   `json:"sparse,omitempty"`
+	Identity           bool   `json:"identity,omitempty"`
+	IdentitySeed       string `json:"identity_seed,omitempty"`
+	IdentityIncrement  string `json:"identity_increment,omitempty"`
+	RowGUIDCol         bool   `json:"rowguidcol,omitempty"`
+	Default            string `json:"default,omitempty"` // expressão do default constraint, ex.: (getdate())
+	// GeneratedAlways row_start/row_end nas colunas de período de tabelas temporais
+	GeneratedAlways string `json:"generated_always,omitempty"`
+}
+
+// Insertable indica se o valor da coluna pode ser gravado explicitamente no
+// destino (identity exige IDENTITY_INSERT; calculadas e rowversion nunca)
+func (c *Column) Insertable() bool {
+	return !c.Computed && !c.Identity && !c.IsRowVersion()
+}
+
+// IsRowVersion indica rowversion (TYPE_NAME retorna timestamp)
+func (c *Column) IsRowVersion() bool {
+	return c.Type == "timestamp" || c.Type == "rowversion"
+}
+
+// TableSchema versão do schema de uma tabela, válida a partir de ValidFrom
+type TableSchema struct {
+	Table           string   `json:"table"` // schema.tabela
+	CaptureInstance string   `json:"capture_instance"`
+	ValidFrom       string   `json:"valid_from_lsn"`
+	Columns         []Column `json:"columns"`
+	// UncapturedColumns colunas da tabela de origem fora da capture instance
+	UncapturedColumns []string `json:"uncaptured_columns,omitempty"`
+	// Temporal preenchido para tabelas system-versioned
+	Temporal *Temporal `json:"temporal,omitempty"`
+}
+
+// Temporal tabela de histórico e colunas de período de uma tabela system-versioned
+type Temporal struct {
+	HistoryTable string `json:"history_table"` // schema.tabela
+	PeriodStart  string `json:"period_start"`
+	PeriodEnd    string `json:"period_end"`
+}
+
+// Column retorna a definição da coluna pelo nome
+func (t *TableSchema) Column(name string) (*Column, bool) {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i], true
+		}
+	}
+	return nil, false
+}
+
+// IsCaptured indica se a coluna existe na capture instance
+func (t *TableSchema) IsCaptured(name string) bool {
+	col, ok := t.Column(name)
+	return ok && col.Captured
+}
+
+// CapturedColumns retorna apenas as colunas presentes na capture instance
+func (t *TableSchema) CapturedColumns() []Column {
+	var columns []Column
+	for _, col := range t.Columns {
+		if col.Captured {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// SameDefinition indica se as duas versões têm as mesmas colunas
+// (ignora LSN de validade e capture instance)
+func (t *TableSchema) SameDefinition(other *TableSchema) bool {
+	if other == nil || len(t.Columns) != len(other.Columns) {
+		return false
+	}
+	if (t.Temporal == nil) != (other.Temporal == nil) || (t.Temporal != nil && *t.Temporal != *other.Temporal) {
+		return false
+	}
+	for i := range t.Columns {
+		if t.Columns[i] != other.Columns[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,436 @@
+// internal/sink/apply.go
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/schema"
+	"go-cdc/static"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "github.com/microsoft/go-mssqldb"
+	"github.com/rs/zerolog/log"
+	_ "modernc.org/sqlite"
+)
+
+// Apply replica as alterações em outro banco (Postgres, SQL Server, MySQL ou
+// SQLite). Inserts, updates e snapshots viram upserts pela chave primária e
+// deletes viram DELETE pela chave, então reaplicar um lote após falha é
+// idempotente. Os eventos de cada transação de origem (mesmo LSN de commit)
+// são aplicados em uma única transação no destino; Write só retorna após o
+// COMMIT. Com APP_GO_CDC_APPLY_AUTO_CREATE as tabelas são criadas a partir do
+// schema de origem e colunas novas são adicionadas (colunas removidas na
+// origem são mantidas no destino).
+type Apply struct {
+	db            *sql.DB
+	dialect       applyDialect
+	tableTemplate string
+	tableMap      map[string]string // schema.tabela em minúsculas -> destino
+	autoCreate    bool
+
+	targets       map[string]applyTarget         // schema.tabela de origem -> destino
+	ensured       map[*schema.TableSchema]bool   // versões de schema já criadas/alteradas
+	hasIdentity   map[string]bool                // destino SQL Server com coluna identity
+	targetColumns map[string]map[string]struct{} // destino -> colunas existentes (minúsculas)
+	warned        map[string]bool
+}
+
+// applyTarget nome da tabela de destino
+type applyTarget struct {
+	schema string // vazio quando o banco não usa schemas
+	name   string // nome qualificado e entre delimitadores
+}
+
+// NewApply cria o sink (construtor)
+func NewApply(ctx context.Context, cfg *config.Config) (*Apply, static.ErrorUtil) {
+	dialect, ok := newApplyDialect(cfg.ApplyDriver)
+	if !ok {
+		return nil, static.NewErrorUtil("Unsupported apply driver", "APPLY_CONFIG_INVALID", nil, "APP_GO_CDC_APPLY_DRIVER="+cfg.ApplyDriver)
+	}
+	if cfg.ApplyDSN == "" {
+		return nil, static.NewErrorUtil("Apply DSN not configured", "APPLY_CONFIG_INVALID", nil, "APP_GO_CDC_APPLY_DSN")
+	}
+
+	db, err := sql.Open(dialect.driverName(), cfg.ApplyDSN)
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to open apply database")
+		return nil, static.NewErrorUtil("Failed to open apply database", "APPLY_CONFIG_INVALID", err, err.Error())
+	}
+	maxOpen := cfg.ApplyMaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = static.APP_GO_CDC_APPLY_MAX_OPEN_CONNS
+	}
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxOpen)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		log.Error().Caller().Err(err).Str("driver", cfg.ApplyDriver).Msg("Apply database unreachable")
+		return nil, static.NewErrorUtil("Apply database unreachable", "SINK_UNHEALTHY", err, err.Error())
+	}
+
+	a := &Apply{
+		db:            db,
+		dialect:       dialect,
+		tableTemplate: cfg.ApplyTableTemplate,
+		tableMap:      config.ParseTableMap(cfg.ApplyTableMap),
+		autoCreate:    cfg.ApplyAutoCreate,
+		targets:       make(map[string]applyTarget),
+		ensured:       make(map[*schema.TableSchema]bool),
+		hasIdentity:   make(map[string]bool),
+		targetColumns: make(map[string]map[string]struct{}),
+		warned:        make(map[string]bool),
+	}
+	if a.tableTemplate == "" {
+		a.tableTemplate = static.APP_GO_CDC_APPLY_TABLE_TEMPLATE
+	}
+	return a, nil
+}
+
+func (a *Apply) Name() string {
+	return TypeApply
+}
+
+// target resolve a tabela de destino: APP_GO_CDC_APPLY_TABLE_MAP tem precedência
+// sobre o template. No SQLite o schema é descartado.
+func (a *Apply) target(evt *event.Event) applyTarget {
+	table := evt.FullTableName()
+	if target, ok := a.targets[table]; ok {
+		return target
+	}
+
+	resolved, ok := a.tableMap[strings.ToLower(table)]
+	if !ok {
+		resolved = resolveTableTemplate(a.tableTemplate, evt, nil)
+	}
+	var target applyTarget
+	schemaName, tableName, qualified := strings.Cut(resolved, ".")
+	if !qualified {
+		tableName, schemaName = schemaName, ""
+	}
+	if !a.dialect.usesSchemas() {
+		schemaName = ""
+	}
+	target.name = a.dialect.quote(tableName)
+	if schemaName != "" {
+		target.schema = schemaName
+		target.name = a.dialect.quote(schemaName) + "." + target.name
+	}
+	a.targets[table] = target
+	return target
+}
+
+func (a *Apply) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	for start := 0; start < len(events); {
+		end := start + 1
+		for end < len(events) && events[end].Position.LSN == events[start].Position.LSN {
+			end++
+		}
+		if errApply := a.applyTransaction(ctx, events[start:end]); errApply != nil {
+			return errApply
+		}
+		start = end
+	}
+	return nil
+}
+
+// applyTransaction aplica os eventos de uma transação de origem em uma transação
+// do destino. DDL da criação automática roda antes, fora da transação (o MySQL
+// faz commit implícito em DDL).
+func (a *Apply) applyTransaction(ctx context.Context, events []event.Event) static.ErrorUtil {
+	if a.autoCreate {
+		for i := range events {
+			if err := a.ensureTable(ctx, &events[i]); err != nil {
+				log.Error().Err(err).Str("table", events[i].FullTableName()).Msg("Failed to create apply target table")
+				return static.NewErrorUtil("Failed to create apply target table", "SINK_WRITE_FAILED", err, err.Error())
+			}
+		}
+	}
+
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return static.NewErrorUtil("Failed to begin apply transaction", "SINK_WRITE_FAILED", err, err.Error())
+	}
+	for i := range events {
+		if err := a.applyEvent(ctx, tx, &events[i]); err != nil {
+			_ = tx.Rollback()
+			log.Error().
+				Err(err).
+				Str("table", events[i].FullTableName()).
+				Str("op", string(events[i].Op)).
+				Str("position", events[i].Position.String()).
+				Msg("Failed to apply event")
+			return static.NewErrorUtil("Failed to apply event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Error().Err(err).Str("lsn", events[0].Position.LSN).Msg("Failed to commit apply transaction")
+		return static.NewErrorUtil("Failed to commit apply transaction", "SINK_WRITE_FAILED", err, err.Error())
+	}
+	return nil
+}
+
+func (a *Apply) applyEvent(ctx context.Context, tx *sql.Tx, evt *event.Event) error {
+	switch evt.Op {
+	case event.OpTruncate:
+		_, err := tx.ExecContext(ctx, "DELETE FROM "+a.target(evt).name)
+		return err
+	case event.OpInsert, event.OpRead, event.OpUpdate, event.OpDelete:
+	default:
+		return nil
+	}
+
+	if len(evt.KeyColumns) == 0 {
+		// sem chave não há como aplicar de forma idempotente
+		if table := evt.FullTableName(); !a.warned[table] {
+			a.warned[table] = true
+			log.Warn().Str("table", table).Msg("Table without primary key, skipping apply")
+		}
+		return nil
+	}
+	target := a.target(evt)
+
+	if evt.Op == event.OpDelete {
+		return a.deleteRow(ctx, tx, target, evt.KeyColumns, evt.Key)
+	}
+	if evt.Op == event.OpUpdate && evt.Before != nil {
+		// update da chave primária: remove a linha com a chave antiga
+		for _, column := range evt.KeyColumns {
+			if !applyValuesEqual(evt.Before[column], evt.After[column]) {
+				if err := a.deleteRow(ctx, tx, target, evt.KeyColumns, evt.Before); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+
+	columns := a.columns(evt)
+	args := make([]interface{}, len(columns))
+	for i, column := range columns {
+		args[i] = applyValue(evt.After[column])
+	}
+	statement := a.dialect.upsert(target.name, columns, evt.KeyColumns)
+
+	identity, err := a.targetHasIdentity(ctx, tx, evt, target)
+	if err != nil {
+		return err
+	}
+	if identity {
+		// o valor da coluna identity vem da origem
+		if _, err := tx.ExecContext(ctx, "SET IDENTITY_INSERT "+target.name+" ON"); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, statement, args...)
+	if identity {
+		if _, errOff := tx.ExecContext(ctx, "SET IDENTITY_INSERT "+target.name+" OFF"); err == nil {
+			err = errOff
+		}
+	}
+	return err
+}
+
+func (a *Apply) deleteRow(ctx context.Context, tx *sql.Tx, target applyTarget, keys []string, row map[string]interface{}) error {
+	conditions := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+	for i, column := range keys {
+		conditions[i] = a.dialect.quote(column) + " = " + a.dialect.placeholder(i+1)
+		args[i] = applyValue(row[column])
+	}
+	_, err := tx.ExecContext(ctx, "DELETE FROM "+target.name+" WHERE "+strings.Join(conditions, " AND "), args...)
+	return err
+}
+
+// columns colunas gravadas no upsert, na ordem do schema: calculadas e
+// rowversion ficam de fora, assim como LOBs ausentes do update
+func (a *Apply) columns(evt *event.Event) []string {
+	unchanged := make(map[string]bool, len(evt.UnchangedColumns))
+	for _, column := range evt.UnchangedColumns {
+		unchanged[column] = true
+	}
+
+	var columns []string
+	if evt.TableSchema != nil {
+		for _, col := range evt.TableSchema.CapturedColumns() {
+			if _, present := evt.After[col.Name]; present && applyWritable(&col) && !unchanged[col.Name] {
+				columns = append(columns, col.Name)
+			}
+		}
+		return columns
+	}
+	for column := range evt.After {
+		if !unchanged[column] {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// applyWritable colunas replicadas; identity é gravada com o valor da origem
+func applyWritable(col *schema.Column) bool {
+	return col.Insertable() || (col.Identity && !col.Computed)
+}
+
+// targetHasIdentity no SQL Server, indica se é preciso IDENTITY_INSERT para
+// gravar a coluna identity no destino (tabelas criadas pelo sink não têm identity)
+func (a *Apply) targetHasIdentity(ctx context.Context, tx *sql.Tx, evt *event.Event, target applyTarget) (bool, error) {
+	if _, sqlServer := a.dialect.(sqlServerDialect); !sqlServer {
+		return false, nil
+	}
+	if identity, ok := a.hasIdentity[target.name]; ok {
+		return identity, nil
+	}
+	if evt.TableSchema != nil {
+		sourceIdentity := false
+		for _, col := range evt.TableSchema.Columns {
+			sourceIdentity = sourceIdentity || col.Identity
+		}
+		if !sourceIdentity {
+			a.hasIdentity[target.name] = false
+			return false, nil
+		}
+	}
+	var identity sql.NullInt64
+	if err := tx.QueryRowContext(ctx, "SELECT OBJECTPROPERTY(OBJECT_ID(@p1), 'TableHasIdentity')", target.name).Scan(&identity); err != nil {
+		return false, err
+	}
+	a.hasIdentity[target.name] = identity.Valid && identity.Int64 == 1
+	return a.hasIdentity[target.name], nil
+}
+
+// ensureTable cria a tabela de destino e adiciona colunas novas, uma vez por
+// versão de schema. Sem chave conhecida a criação fica para o primeiro evento
+// de dados.
+func (a *Apply) ensureTable(ctx context.Context, evt *event.Event) error {
+	ts := evt.TableSchema
+	if evt.SchemaChange != nil {
+		ts = evt.SchemaChange
+	}
+	if ts == nil || a.ensured[ts] {
+		return nil
+	}
+	target := a.target(evt)
+
+	columns, err := a.loadTargetColumns(ctx, target)
+	if err != nil {
+		return err
+	}
+	if columns == nil {
+		if len(evt.KeyColumns) == 0 {
+			return nil
+		}
+		if target.schema != "" {
+			if statement := a.dialect.createSchema(target.schema); statement != "" {
+				if _, err := a.db.ExecContext(ctx, statement); err != nil {
+					return err
+				}
+			}
+		}
+		keys := make(map[string]bool, len(evt.KeyColumns))
+		for _, column := range evt.KeyColumns {
+			keys[column] = true
+		}
+		var definitions []string
+		for _, col := range ts.CapturedColumns() {
+			if !applyWritable(&col) {
+				continue
+			}
+			definition := a.dialect.quote(col.Name) + " " + a.dialect.columnType(col)
+			if keys[col.Name] {
+				definition += " NOT NULL"
+			}
+			definitions = append(definitions, definition)
+		}
+		if _, err := a.db.ExecContext(ctx, a.dialect.createTable(target.name, definitions, evt.KeyColumns)); err != nil {
+			return err
+		}
+		log.Info().Str("table", evt.FullTableName()).Str("target", target.name).Msg("Apply target table created")
+	} else {
+		for _, col := range ts.CapturedColumns() {
+			if _, exists := columns[strings.ToLower(col.Name)]; exists || !applyWritable(&col) {
+				continue
+			}
+			definition := a.dialect.quote(col.Name) + " " + a.dialect.columnType(col)
+			if _, err := a.db.ExecContext(ctx, a.dialect.addColumn(target.name, definition)); err != nil {
+				return err
+			}
+			log.Info().Str("table", evt.FullTableName()).Str("column", col.Name).Msg("Apply target column added")
+		}
+	}
+
+	delete(a.targetColumns, target.name)
+	delete(a.hasIdentity, target.name)
+	a.ensured[ts] = true
+	return nil
+}
+
+// loadTargetColumns colunas da tabela de destino (nil se a tabela não existe).
+// A consulta sem linhas funciona em todos os bancos; erro é tratado como
+// tabela inexistente e o CREATE TABLE idempotente revela falhas reais.
+func (a *Apply) loadTargetColumns(ctx context.Context, target applyTarget) (map[string]struct{}, error) {
+	if columns, ok := a.targetColumns[target.name]; ok {
+		return columns, nil
+	}
+	rows, err := a.db.QueryContext(ctx, "SELECT * FROM "+target.name+" WHERE 1 = 0")
+	if err != nil {
+		return nil, nil
+	}
+	defer rows.Close()
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		columns[strings.ToLower(name)] = struct{}{}
+	}
+	a.targetColumns[target.name] = columns
+	return columns, nil
+}
+
+// applyValue converte valores sem tipo SQL direto (LOBs externalizados, mapas) para JSON
+func applyValue(v interface{}) interface{} {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		return string(encoded)
+	}
+	return v
+}
+
+func applyValuesEqual(a, b interface{}) bool {
+	return redisValue(a) == redisValue(b)
+}
+
+func (a *Apply) Flush(ctx context.Context) static.ErrorUtil {
+	return nil
+}
+
+func (a *Apply) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+func (a *Apply) HealthCheck(ctx context.Context) static.ErrorUtil {
+	if err := a.db.PingContext(ctx); err != nil {
+		return static.NewErrorUtil("Apply database unreachable", "SINK_UNHEALTHY", err, err.Error())
+	}
+	return nil
+}
+
+func (a *Apply) Close() static.ErrorUtil {
+	if err := a.db.Close(); err != nil {
+		return static.NewErrorUtil("Failed to close apply database", "SINK_CLOSE_FAILED", err, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+// internal/sink/apply_dialect.go
+package sink
+
+import (
+	"fmt"
+	"strings"
+
+	"go-cdc/internal/schema"
+)
+
+// Bancos de destino aceitos em APP_GO_CDC_APPLY_DRIVER
+const (
+	ApplyPostgres  = "postgres"
+	ApplySQLServer = "sqlserver"
+	ApplyMySQL     = "mysql"
+	ApplySQLite    = "sqlite"
+)
+
+// applyDialect diferenças de SQL entre os bancos de destino
+type applyDialect interface {
+	// driverName nome do driver registrado em database/sql
+	driverName() string
+	quote(name string) string
+	placeholder(i int) string // i começa em 1
+	// upsert insere ou atualiza a linha pela chave (columns inclui as chaves)
+	upsert(table string, columns []string, keys []string) string
+	// columnType tipo da coluna na criação automática da tabela
+	columnType(col schema.Column) string
+	// createTable CREATE TABLE idempotente
+	createTable(table string, definitions []string, keys []string) string
+	addColumn(table string, definition string) string
+	// createSchema CREATE SCHEMA idempotente ("" quando o banco não usa schemas)
+	createSchema(name string) string
+	// usesSchemas indica se o nome do destino pode ser qualificado por schema
+	usesSchemas() bool
+}
+
+func newApplyDialect(driver string) (applyDialect, bool) {
+	switch strings.ToLower(driver) {
+	case ApplyPostgres, "postgresql", "pgx":
+		return postgresDialect{}, true
+	case ApplySQLServer, "mssql":
+		return sqlServerDialect{}, true
+	case ApplyMySQL, "mariadb":
+		return mysqlDialect{}, true
+	case ApplySQLite, "sqlite3":
+		return sqliteDialect{}, true
+	}
+	return nil, false
+}
+
+// joinMapped aplica fn a cada item e junta com ", "
+func joinMapped(items []string, fn func(i int, item string) string) string {
+	mapped := make([]string, len(items))
+	for i, item := range items {
+		mapped[i] = fn(i, item)
+	}
+	return strings.Join(mapped, ", ")
+}
+
+// nonKeyColumns colunas atualizadas quando a linha já existe
+func nonKeyColumns(columns []string, keys []string) []string {
+	var result []string
+	for _, column := range columns {
+		isKey := false
+		for _, key := range keys {
+			if column == key {
+				isKey = true
+				break
+			}
+		}
+		if !isKey {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+// sqlServerLength tamanho declarado (n ou max); nchar/nvarchar usam 2 bytes por caractere
+func sqlServerLength(col schema.Column) string {
+	if col.MaxLength < 0 {
+		return "max"
+	}
+	length := col.MaxLength
+	if col.Type == "nchar" || col.Type == "nvarchar" {
+		length /= 2
+	}
+	return fmt.Sprint(max(length, 1))
+}
+
+// insertConflictUpsert INSERT ... ON CONFLICT (Postgres e SQLite)
+func insertConflictUpsert(d applyDialect, table string, columns []string, keys []string) string {
+	quote := func(_ int, name string) string { return d.quote(name) }
+	sql := "INSERT INTO " + table + " (" + joinMapped(columns, quote) + ") VALUES (" +
+		joinMapped(columns, func(i int, _ string) string { return d.placeholder(i + 1) }) + ") ON CONFLICT (" +
+		joinMapped(keys, quote) + ")"
+	updates := nonKeyColumns(columns, keys)
+	if len(updates) == 0 {
+		return sql + " DO NOTHING"
+	}
+	return sql + " DO UPDATE SET " + joinMapped(updates, func(_ int, name string) string {
+		return d.quote(name) + " = excluded." + d.quote(name)
+	})
+}
+
+// postgresDialect PostgreSQL via pgx (stdlib)
+type postgresDialect struct{}
+
+func (postgresDialect) driverName() string { return "pgx" }
+
+func (postgresDialect) quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) placeholder(i int) string { return fmt.Sprintf("$%d", i) }
+
+func (d postgresDialect) upsert(table string, columns []string, keys []string) string {
+	return insertConflictUpsert(d, table, columns, keys)
+}
+
+func (postgresDialect) columnType(col schema.Column) string {
+	switch col.Type {
+	case "bit":
+		return "boolean"
+	case "tinyint", "smallint":
+		return "smallint"
+	case "int":
+		return "integer"
+	case "bigint":
+		return "bigint"
+	case "decimal", "numeric":
+		return fmt.Sprintf("numeric(%d,%d)", col.Precision, col.Scale)
+	case "money":
+		return "numeric(19,4)"
+	case "smallmoney":
+		return "numeric(10,4)"
+	case "float":
+		return "double precision"
+	case "real":
+		return "real"
+	case "date":
+		return "date"
+	case "time":
+		return "time"
+	case "datetime", "datetime2", "smalldatetime":
+		return "timestamp"
+	case "datetimeoffset":
+		return "timestamptz"
+	case "uniqueidentifier":
+		return "uuid"
+	case "binary", "varbinary", "image", "hierarchyid", "geography", "geometry":
+		return "bytea"
+	}
+	return "text"
+}
+
+func (d postgresDialect) createTable(table string, definitions []string, keys []string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + " (" + strings.Join(definitions, ", ") +
+		", PRIMARY KEY (" + joinMapped(keys, func(_ int, name string) string { return d.quote(name) }) + "))"
+}
+
+func (postgresDialect) addColumn(table string, definition string) string {
+	return "ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS " + definition
+}
+
+func (d postgresDialect) createSchema(name string) string {
+	return "CREATE SCHEMA IF NOT EXISTS " + d.quote(name)
+}
+
+func (postgresDialect) usesSchemas() bool { return true }
+
+// sqlServerDialect SQL Server via go-mssqldb
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) driverName() string { return "sqlserver" }
+
+func (sqlServerDialect) quote(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func (sqlServerDialect) placeholder(i int) string { return fmt.Sprintf("@p%d", i) }
+
+// upsert MERGE com HOLDLOCK para não haver corrida entre o teste e a inserção
+func (d sqlServerDialect) upsert(table string, columns []string, keys []string) string {
+	quote := func(_ int, name string) string { return d.quote(name) }
+	sql := "MERGE INTO " + table + " WITH (HOLDLOCK) AS target USING (VALUES (" +
+		joinMapped(columns, func(i int, _ string) string { return d.placeholder(i + 1) }) + ")) AS source (" +
+		joinMapped(columns, quote) + ") ON " +
+		strings.ReplaceAll(joinMapped(keys, func(_ int, name string) string {
+			return "target." + d.quote(name) + " = source." + d.quote(name)
+		}), ", ", " AND ")
+	if updates := nonKeyColumns(columns, keys); len(updates) > 0 {
+		sql += " WHEN MATCHED THEN UPDATE SET " + joinMapped(updates, func(_ int, name string) string {
+			return d.quote(name) + " = source." + d.quote(name)
+		})
+	}
+	return sql + " WHEN NOT MATCHED THEN INSERT (" + joinMapped(columns, quote) + ") VALUES (" +
+		joinMapped(columns, func(_ int, name string) string { return "source." + d.quote(name) }) + ");"
+}
+
+func (sqlServerDialect) columnType(col schema.Column) string {
+	switch col.Type {
+	case "char", "varchar", "nchar", "nvarchar", "binary", "varbinary":
+		return col.Type + "(" + sqlServerLength(col) + ")"
+	case "decimal", "numeric":
+		return fmt.Sprintf("%s(%d,%d)", col.Type, col.Precision, col.Scale)
+	case "datetime2", "time", "datetimeoffset":
+		return fmt.Sprintf("%s(%d)", col.Type, col.Scale)
+	case "float":
+		return "float"
+	}
+	return col.Type
+}
+
+func (d sqlServerDialect) createTable(table string, definitions []string, keys []string) string {
+	return "IF OBJECT_ID(N'" + strings.ReplaceAll(table, "'", "''") + "', N'U') IS NULL CREATE TABLE " + table +
+		" (" + strings.Join(definitions, ", ") +
+		", PRIMARY KEY (" + joinMapped(keys, func(_ int, name string) string { return d.quote(name) }) + "))"
+}
+
+func (sqlServerDialect) addColumn(table string, definition string) string {
+	return "ALTER TABLE " + table + " ADD " + definition
+}
+
+func (d sqlServerDialect) createSchema(name string) string {
+	return "IF SCHEMA_ID(N'" + strings.ReplaceAll(name, "'", "''") + "') IS NULL EXEC(N'CREATE SCHEMA " +
+		strings.ReplaceAll(d.quote(name), "'", "''") + "')"
+}
+
+func (sqlServerDialect) usesSchemas() bool { return true }
+
+// mysqlDialect MySQL/MariaDB via go-sql-driver (schema = database)
+type mysqlDialect struct{}
+
+func (mysqlDialect) driverName() string { return "mysql" }
+
+func (mysqlDialect) quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) placeholder(int) string { return "?" }
+
+// upsert usa VALUES(coluna), aceito pelo MySQL 5.7+ e pelo MariaDB
+func (d mysqlDialect) upsert(table string, columns []string, keys []string) string {
+	quote := func(_ int, name string) string { return d.quote(name) }
+	sql := "INSERT INTO " + table + " (" + joinMapped(columns, quote) + ") VALUES (" +
+		joinMapped(columns, func(int, string) string { return "?" }) + ") ON DUPLICATE KEY UPDATE "
+	updates := nonKeyColumns(columns, keys)
+	if len(updates) == 0 {
+		updates = keys[:1]
+	}
+	return sql + joinMapped(updates, func(_ int, name string) string {
+		return d.quote(name) + " = VALUES(" + d.quote(name) + ")"
+	})
+}
+
+func (mysqlDialect) columnType(col schema.Column) string {
+	switch col.Type {
+	case "bit":
+		return "boolean"
+	case "tinyint":
+		return "tinyint unsigned"
+	case "smallint", "int", "bigint", "date":
+		return col.Type
+	case "decimal", "numeric":
+		return fmt.Sprintf("decimal(%d,%d)", col.Precision, col.Scale)
+	case "money":
+		return "decimal(19,4)"
+	case "smallmoney":
+		return "decimal(10,4)"
+	case "float":
+		return "double"
+	case "real":
+		return "float"
+	case "time":
+		return "time(6)"
+	case "datetime", "datetime2", "smalldatetime", "datetimeoffset":
+		return "datetime(6)"
+	case "uniqueidentifier":
+		return "char(36)"
+	case "char", "nchar", "varchar", "nvarchar":
+		// chaves primárias não aceitam TEXT sem prefixo
+		if col.MaxLength > 0 {
+			return "varchar(" + sqlServerLength(col) + ")"
+		}
+		return "longtext"
+	case "binary", "varbinary":
+		if col.MaxLength > 0 {
+			return "varbinary(" + sqlServerLength(col) + ")"
+		}
+		return "longblob"
+	case "image", "hierarchyid", "geography", "geometry":
+		return "longblob"
+	}
+	return "longtext"
+}
+
+func (d mysqlDialect) createTable(table string, definitions []string, keys []string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + " (" + strings.Join(definitions, ", ") +
+		", PRIMARY KEY (" + joinMapped(keys, func(_ int, name string) string { return d.quote(name) }) + "))"
+}
+
+func (mysqlDialect) addColumn(table string, definition string) string {
+	return "ALTER TABLE " + table + " ADD COLUMN " + definition
+}
+
+func (mysqlDialect) createSchema(string) string { return "" }
+
+func (mysqlDialect) usesSchemas() bool { return true }
+
+// sqliteDialect SQLite via modernc.org/sqlite (sem schemas)
+type sqliteDialect struct{}
+
+func (sqliteDialect) driverName() string { return "sqlite" }
+
+func (sqliteDialect) quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) placeholder(int) string { return "?" }
+
+func (d sqliteDialect) upsert(table string, columns []string, keys []string) string {
+	return insertConflictUpsert(d, table, columns, keys)
+}
+
+// columnType usa afinidades do SQLite; decimais ficam como texto para não perder precisão
+func (sqliteDialect) columnType(col schema.Column) string {
+	switch col.Type {
+	case "bit", "tinyint", "smallint", "int", "bigint":
+		return "INTEGER"
+	case "float", "real":
+		return "REAL"
+	case "binary", "varbinary", "image", "hierarchyid", "geography", "geometry":
+		return "BLOB"
+	}
+	return "TEXT"
+}
+
+func (d sqliteDialect) createTable(table string, definitions []string, keys []string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + " (" + strings.Join(definitions, ", ") +
+		", PRIMARY KEY (" + joinMapped(keys, func(_ int, name string) string { return d.quote(name) }) + "))"
+}
+
+func (sqliteDialect) addColumn(table string, definition string) string {
+	return "ALTER TABLE " + table + " ADD COLUMN " + definition
+}
+
+func (sqliteDialect) createSchema(string) string { return "" }
+
+func (sqliteDialect) usesSchemas() bool { return false }
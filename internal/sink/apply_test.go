@@ -0,0 +1,165 @@
+package sink
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/schema"
+)
+
+func newApplyTest(t *testing.T) (*Apply, *sql.DB) {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "target.db")
+	a, errApply := NewApply(context.Background(), &config.Config{ApplyDriver: ApplySQLite, ApplyDSN: dsn, ApplyAutoCreate: true})
+	if errApply != nil {
+		t.Fatalf("NewApply: %v", errApply)
+	}
+	t.Cleanup(func() { a.Close() })
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return a, db
+}
+
+// applyRows linhas de Orders como id -> status|notes
+func applyRows(t *testing.T, db *sql.DB) map[int64]string {
+	t.Helper()
+	rows, err := db.Query(`SELECT "Id", COALESCE("Status", 'NULL'), COALESCE("Notes", 'NULL') FROM "Orders"`)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	defer rows.Close()
+	out := make(map[int64]string)
+	for rows.Next() {
+		var id int64
+		var status, notes string
+		if err := rows.Scan(&id, &status, &notes); err != nil {
+			t.Fatalf("scan: %v", err)
+		}
+		out[id] = status + "|" + notes
+	}
+	return out
+}
+
+func TestApplySQLite(t *testing.T) {
+	a, db := newApplyTest(t)
+	ctx := context.Background()
+	row := func(id int64, status string, notes interface{}) map[string]interface{} {
+		return map[string]interface{}{"Id": id, "Status": status, "Notes": notes, "Total": int64(99)}
+	}
+
+	steps := []struct {
+		name   string
+		events []event.Event
+		want   map[int64]string
+	}{
+		{
+			name: "insert creates the table",
+			events: []event.Event{
+				testOrder(event.OpInsert, testPosition(1, 1), nil, row(1, "new", "first")),
+				testOrder(event.OpInsert, testPosition(1, 2), nil, row(2, "new", nil)),
+			},
+			want: map[int64]string{1: "new|first", 2: "new|NULL"},
+		},
+		{
+			name: "update keeps unchanged LOB columns",
+			events: func() []event.Event {
+				evt := testOrder(event.OpUpdate, testPosition(2, 1), row(1, "new", "first"), map[string]interface{}{"Id": int64(1), "Status": "paid"})
+				evt.UnchangedColumns = []string{"Notes"}
+				return []event.Event{evt}
+			}(),
+			want: map[int64]string{1: "paid|first", 2: "new|NULL"},
+		},
+		{
+			name:   "snapshot read is an upsert",
+			events: []event.Event{testOrder(event.OpRead, testPosition(3, 0), nil, row(2, "shipped", "late"))},
+			want:   map[int64]string{1: "paid|first", 2: "shipped|late"},
+		},
+		{
+			name:   "primary key change moves the row",
+			events: []event.Event{testOrder(event.OpUpdate, testPosition(4, 1), row(1, "paid", "first"), row(10, "paid", "first"))},
+			want:   map[int64]string{2: "shipped|late", 10: "paid|first"},
+		},
+		{
+			name:   "delete",
+			events: []event.Event{testOrder(event.OpDelete, testPosition(5, 1), row(2, "shipped", "late"), nil)},
+			want:   map[int64]string{10: "paid|first"},
+		},
+		{
+			name: "truncate then insert",
+			events: []event.Event{
+				testOrder(event.OpTruncate, testPosition(6, 0), nil, nil),
+				testOrder(event.OpInsert, testPosition(7, 1), nil, row(3, "new", nil)),
+			},
+			want: map[int64]string{3: "new|NULL"},
+		},
+	}
+
+	for _, step := range steps {
+		// cada lote é aplicado duas vezes: a reaplicação após falha é idempotente
+		for range 2 {
+			if errWrite := a.Write(ctx, step.events); errWrite != nil {
+				t.Fatalf("%s: Write: %v", step.name, errWrite)
+			}
+		}
+		got := applyRows(t, db)
+		if fmt.Sprint(got) != fmt.Sprint(step.want) {
+			t.Fatalf("%s: rows = %v, want %v", step.name, got, step.want)
+		}
+	}
+
+	// colunas calculadas não são criadas no destino
+	var computed int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('Orders') WHERE name = 'Total'`).Scan(&computed); err != nil || computed != 0 {
+		t.Errorf("computed column created in target (%d, %v)", computed, err)
+	}
+}
+
+func TestApplyAddsNewColumns(t *testing.T) {
+	a, db := newApplyTest(t)
+	ctx := context.Background()
+
+	if errWrite := a.Write(ctx, []event.Event{testOrder(event.OpInsert, testPosition(1, 1), nil, map[string]interface{}{"Id": int64(1), "Status": "new"})}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	v2 := ordersTestSchemaWith("00000000000000000002", schema.Column{Name: "Channel", Type: "varchar", MaxLength: 10, Captured: true, Nullable: true})
+	evt := testOrder(event.OpInsert, testPosition(2, 1), nil, map[string]interface{}{"Id": int64(2), "Status": "new", "Channel": "web"})
+	evt.TableSchema = v2
+	if errWrite := a.Write(ctx, []event.Event{evt}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	var channel sql.NullString
+	if err := db.QueryRow(`SELECT "Channel" FROM "Orders" WHERE "Id" = 2`).Scan(&channel); err != nil || channel.String != "web" {
+		t.Fatalf("Channel = %v, %v", channel, err)
+	}
+}
+
+func TestApplySourceTransactionIsAtomic(t *testing.T) {
+	a, db := newApplyTest(t)
+	ctx := context.Background()
+
+	events := []event.Event{
+		testOrder(event.OpInsert, testPosition(1, 1), nil, map[string]interface{}{"Id": int64(1), "Status": "new"}),
+		testOrder(event.OpInsert, testPosition(2, 1), nil, map[string]interface{}{"Id": int64(2), "Status": "new"}),
+		// mesma transação de origem (LSN 2): valor que o driver não consegue gravar
+		testOrder(event.OpInsert, testPosition(2, 2), nil, map[string]interface{}{"Id": int64(3), "Status": make(chan int)}),
+	}
+	errWrite := a.Write(ctx, events)
+	if errWrite == nil || errWrite.Code() != "SINK_WRITE_FAILED" {
+		t.Fatalf("expected SINK_WRITE_FAILED, got %v", errWrite)
+	}
+
+	// a transação do LSN 1 foi confirmada, a do LSN 2 inteira foi desfeita
+	if got := applyRows(t, db); fmt.Sprint(got) != fmt.Sprint(map[int64]string{1: "new|NULL"}) {
+		t.Fatalf("rows = %v", got)
+	}
+}
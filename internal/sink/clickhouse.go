@@ -0,0 +1,650 @@
+// internal/sink/clickhouse.go
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/retry"
+	"go-cdc/internal/schema"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Colunas de controle gravadas em cada linha do ClickHouse
+const (
+	ClickHouseColumnOp         = "_op"
+	ClickHouseColumnLSN        = "_lsn"
+	ClickHouseColumnSeqVal     = "_seqval"
+	ClickHouseColumnCommitTime = "_commit_time"
+	ClickHouseColumnVersion    = "_version"
+	ClickHouseColumnDeleted    = "is_deleted"
+)
+
+// ClickHouse grava os eventos com INSERT ... FORMAT JSONEachRow pela interface
+// HTTP em tabelas ReplacingMergeTree(_version, is_deleted) ordenadas pela
+// chave primária. _version é o LSN de commit seguido do seqval (UInt256), então
+// os merges (ou SELECT ... FINAL) mantêm a alteração mais recente de cada chave
+// mesmo com reenvios; deletes gravam a imagem anterior com is_deleted = 1, assim
+// como updates que alteram a chave primária, para a chave anterior.
+// Write acumula as linhas por tabela e Flush envia um INSERT por tabela. Como a
+// linha inteira é substituída, os LOBs não alterados que o CDC omite em updates
+// são preenchidos antes do INSERT com a linha anterior do lote ou, se ela não
+// estiver no lote, com a linha atual do ClickHouse.
+type ClickHouse struct {
+	client        *http.Client
+	url           string
+	username      string
+	password      string
+	database      string
+	tableTemplate string
+	autoCreate    bool
+	maxAttempts   int
+	backoff       retry.Backoff
+
+	mu      sync.Mutex
+	tables  map[string]*clickHouseBatch // tabela de destino -> linhas pendentes
+	order   []string
+	ensured map[*schema.TableSchema]bool // versões de schema já criadas/alteradas
+	warned  map[string]bool
+}
+
+// clickHouseBatch linhas pendentes de uma tabela de destino
+type clickHouseBatch struct {
+	table    string // nome qualificado e entre crases
+	source   string // schema.tabela de origem
+	keys     []string
+	keyTypes []string              // tipos ClickHouse da chave, para a busca dos LOBs
+	schemas  []*schema.TableSchema // versões a garantir antes do INSERT
+	truncate bool                  // TRUNCATE antes das linhas (que são posteriores a ele)
+	rows     []clickHouseRow
+	latest   map[string]int // chave -> última linha do lote com essa chave
+}
+
+// clickHouseRow linha pendente. missing são os LOBs não alterados ainda sem
+// valor, a buscar no ClickHouse pela chave lookup.
+type clickHouseRow struct {
+	values  map[string]interface{}
+	lookup  map[string]interface{}
+	missing []string
+}
+
+// NewClickHouse cria o sink (construtor)
+func NewClickHouse(cfg *config.Config) (*ClickHouse, static.ErrorUtil) {
+	if cfg.ClickHouseURL == "" {
+		return nil, static.NewErrorUtil("ClickHouse URL not configured", "CLICKHOUSE_CONFIG_INVALID", nil, "APP_GO_CDC_CLICKHOUSE_URL")
+	}
+	if _, err := url.Parse(cfg.ClickHouseURL); err != nil {
+		return nil, static.NewErrorUtil("Invalid ClickHouse URL", "CLICKHOUSE_CONFIG_INVALID", err, err.Error())
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.ClickHouseTLSCAFile != "" || cfg.ClickHouseTLSInsecureSkipVerify {
+		tlsConfig, err := buildTLSConfig(cfg.ClickHouseTLSCAFile, "", "", cfg.ClickHouseTLSInsecureSkipVerify)
+		if err != nil {
+			log.Error().Caller().Err(err).Msg("Failed to load ClickHouse TLS configuration")
+			return nil, static.NewErrorUtil("Failed to load ClickHouse TLS configuration", "CLICKHOUSE_CONFIG_INVALID", err, err.Error())
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	timeout := time.Duration(cfg.ClickHouseTimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = static.APP_GO_CDC_CLICKHOUSE_TIMEOUT_MS * time.Millisecond
+	}
+	initial := time.Duration(max(cfg.ClickHouseRetryInitialBackoffMs, 1)) * time.Millisecond
+	maxBackoff := max(time.Duration(cfg.ClickHouseRetryMaxBackoffMs)*time.Millisecond, initial)
+
+	c := &ClickHouse{
+		client:        &http.Client{Transport: transport, Timeout: timeout},
+		url:           strings.TrimRight(cfg.ClickHouseURL, "/") + "/",
+		username:      cfg.ClickHouseUsername,
+		password:      cfg.ClickHousePassword,
+		database:      cfg.ClickHouseDatabase,
+		tableTemplate: cfg.ClickHouseTableTemplate,
+		autoCreate:    cfg.ClickHouseAutoCreate,
+		maxAttempts:   max(cfg.ClickHouseRetryMaxAttempts, 1),
+		backoff:       retry.NewBackoff(initial, maxBackoff),
+		tables:        make(map[string]*clickHouseBatch),
+		ensured:       make(map[*schema.TableSchema]bool),
+		warned:        make(map[string]bool),
+	}
+	if c.database == "" {
+		c.database = static.APP_GO_CDC_CLICKHOUSE_DATABASE
+	}
+	if c.tableTemplate == "" {
+		c.tableTemplate = static.APP_GO_CDC_CLICKHOUSE_TABLE_TEMPLATE
+	}
+	return c, nil
+}
+
+func (c *ClickHouse) Name() string {
+	return TypeClickHouse
+}
+
+// clickHouseQuote delimita identificadores com crases
+func clickHouseQuote(name string) string {
+	return "`" + strings.NewReplacer(`\`, `\\`, "`", "\\`").Replace(name) + "`"
+}
+
+// Table nome qualificado da tabela de destino do evento
+func (c *ClickHouse) Table(evt *event.Event) string {
+	return clickHouseQuote(c.database) + "." + clickHouseQuote(resolveTableTemplate(c.tableTemplate, evt, nil))
+}
+
+// ClickHouseVersion versão da linha: LSN e seqval (binary(10) cada) como um
+// único inteiro de 160 bits, em decimal
+func ClickHouseVersion(pos event.Position) string {
+	digits := fmt.Sprintf("%020s%020s", strings.TrimPrefix(strings.ToLower(pos.LSN), "0x"), strings.TrimPrefix(strings.ToLower(pos.SeqVal), "0x"))
+	version, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return "0"
+	}
+	return version.String()
+}
+
+func (c *ClickHouse) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range events {
+		evt := &events[i]
+		switch evt.Op {
+		case event.OpInsert, event.OpRead, event.OpUpdate, event.OpDelete, event.OpTruncate, event.OpSchema:
+		default:
+			continue
+		}
+		if len(evt.KeyColumns) == 0 && evt.Op != event.OpTruncate && evt.Op != event.OpSchema {
+			// sem chave a ReplacingMergeTree não tem como colapsar as versões
+			if table := evt.FullTableName(); !c.warned[table] {
+				c.warned[table] = true
+				log.Warn().Str("table", table).Msg("Table without primary key, skipping ClickHouse")
+			}
+			continue
+		}
+
+		table := c.Table(evt)
+		batch, ok := c.tables[table]
+		if !ok {
+			batch = &clickHouseBatch{table: table, source: evt.FullTableName(), latest: make(map[string]int)}
+			c.tables[table] = batch
+			c.order = append(c.order, table)
+		}
+		if len(evt.KeyColumns) > 0 {
+			batch.keys = evt.KeyColumns
+			if evt.TableSchema != nil {
+				batch.keyTypes = clickHouseKeyTypes(evt.TableSchema, evt.KeyColumns)
+			}
+		}
+		for _, ts := range []*schema.TableSchema{evt.TableSchema, evt.SchemaChange} {
+			if ts != nil && c.autoCreate && !c.ensured[ts] && !slices.Contains(batch.schemas, ts) {
+				batch.schemas = append(batch.schemas, ts)
+			}
+		}
+
+		switch evt.Op {
+		case event.OpSchema:
+			continue
+		case event.OpTruncate:
+			// linhas anteriores ao truncate não precisam ser enviadas
+			batch.truncate = true
+			batch.rows = nil
+			clear(batch.latest)
+			continue
+		}
+
+		row := clickHouseRow{values: make(map[string]interface{}, len(evt.Row())+6)}
+		for column, value := range evt.Row() {
+			row.values[column] = applyValue(value)
+		}
+		if evt.Op == event.OpUpdate && len(evt.UnchangedColumns) > 0 {
+			// a linha anterior pode mudar de chave: busca pela imagem anterior
+			row.lookup, _ = clickHouseKey(evt.KeyColumns, evt.Before)
+			if row.lookup == nil {
+				row.lookup, _ = clickHouseKey(evt.KeyColumns, evt.After)
+			}
+			_, lookupKey := clickHouseKey(evt.KeyColumns, row.lookup)
+			prev, found := batch.latest[lookupKey]
+			found = found && row.lookup != nil
+			for _, column := range evt.UnchangedColumns {
+				if found {
+					if value, ok := batch.rows[prev].values[column]; ok {
+						row.values[column] = value
+						continue
+					}
+				}
+				row.missing = append(row.missing, column)
+			}
+			if found && batch.rows[prev].lookup != nil {
+				// a linha anterior do lote também aguarda a busca
+				row.lookup = batch.rows[prev].lookup
+			}
+		}
+		row.values[ClickHouseColumnOp] = string(evt.Op)
+		row.values[ClickHouseColumnLSN] = evt.Position.LSN
+		row.values[ClickHouseColumnSeqVal] = evt.Position.SeqVal
+		row.values[ClickHouseColumnCommitTime] = evt.CommitTime.UTC().Format("2006-01-02 15:04:05.000")
+		row.values[ClickHouseColumnVersion] = ClickHouseVersion(evt.Position)
+		row.values[ClickHouseColumnDeleted] = 0
+		if evt.Op == event.OpDelete {
+			row.values[ClickHouseColumnDeleted] = 1
+		}
+		_, key := clickHouseKey(evt.KeyColumns, row.values)
+		batch.latest[key] = len(batch.rows)
+		batch.rows = append(batch.rows, row)
+
+		if evt.Op == event.OpUpdate {
+			// update da chave primária: a chave anterior recebe uma versão removida
+			// na mesma posição, senão o FINAL continuaria retornando a linha antiga
+			if oldKey, old := clickHouseKey(evt.KeyColumns, evt.Before); oldKey != nil && old != key {
+				tombstone := clickHouseRow{values: make(map[string]interface{}, len(evt.Before)+6)}
+				for column, value := range evt.Before {
+					tombstone.values[column] = applyValue(value)
+				}
+				for _, column := range []string{ClickHouseColumnLSN, ClickHouseColumnSeqVal, ClickHouseColumnCommitTime, ClickHouseColumnVersion} {
+					tombstone.values[column] = row.values[column]
+				}
+				tombstone.values[ClickHouseColumnOp] = string(event.OpDelete)
+				tombstone.values[ClickHouseColumnDeleted] = 1
+				batch.rows = append(batch.rows, tombstone)
+			}
+		}
+	}
+	return nil
+}
+
+// clickHouseKey valores da chave na imagem e sua representação para comparação.
+// Retorna nil se a imagem não tiver a chave completa.
+func clickHouseKey(keys []string, image map[string]interface{}) (map[string]interface{}, string) {
+	values := make(map[string]interface{}, len(keys))
+	ordered := make([]interface{}, len(keys))
+	for i, column := range keys {
+		value, ok := image[column]
+		if !ok {
+			return nil, ""
+		}
+		values[column] = applyValue(value)
+		ordered[i] = values[column]
+	}
+	encoded, _ := json.Marshal(ordered)
+	return values, string(encoded)
+}
+
+// clickHouseKeyTypes tipos ClickHouse das colunas da chave; nil se alguma não
+// estiver no schema
+func clickHouseKeyTypes(ts *schema.TableSchema, keys []string) []string {
+	types := make([]string, len(keys))
+	for i, column := range keys {
+		idx := slices.IndexFunc(ts.Columns, func(col schema.Column) bool { return col.Name == column })
+		if idx < 0 {
+			return nil
+		}
+		types[i] = clickHouseType(ts.Columns[idx], false)
+	}
+	return types
+}
+
+// Flush cria/altera as tabelas, aplica os truncates e envia um INSERT por tabela.
+// Os lotes pendentes são descartados no início: em caso de erro o pipeline
+// reenvia os eventos a partir da última posição confirmada.
+func (c *ClickHouse) Flush(ctx context.Context) static.ErrorUtil {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tables, order := c.tables, c.order
+	c.tables, c.order = make(map[string]*clickHouseBatch), nil
+
+	for _, name := range order {
+		batch := tables[name]
+		for _, ts := range batch.schemas {
+			if c.ensured[ts] {
+				continue
+			}
+			if err := c.ensureTable(ctx, batch, ts); err != nil {
+				log.Error().Err(err).Str("table", batch.table).Msg("Failed to create ClickHouse table")
+				return static.NewErrorUtil("Failed to create ClickHouse table", "SINK_FLUSH_FAILED", err, err.Error())
+			}
+		}
+		if batch.truncate {
+			if err := c.exec(ctx, "TRUNCATE TABLE IF EXISTS "+batch.table, nil); err != nil {
+				log.Error().Err(err).Str("table", batch.table).Msg("Failed to truncate ClickHouse table")
+				return static.NewErrorUtil("Failed to truncate ClickHouse table", "SINK_FLUSH_FAILED", err, err.Error())
+			}
+			log.Info().Str("table", batch.source).Str("target", batch.table).Msg("ClickHouse table truncated")
+		}
+		if len(batch.rows) == 0 {
+			continue
+		}
+		if err := c.fetchUnchanged(ctx, batch); err != nil {
+			log.Error().Err(err).Str("table", batch.table).Msg("Failed to fetch unchanged LOB columns from ClickHouse")
+			return static.NewErrorUtil("Failed to fetch unchanged LOB columns from ClickHouse", "SINK_FLUSH_FAILED", err, err.Error())
+		}
+		var body bytes.Buffer
+		for _, row := range batch.rows {
+			line, err := json.Marshal(row.values)
+			if err != nil {
+				return static.NewErrorUtil("Failed to encode event", "SINK_FLUSH_FAILED", err, err.Error())
+			}
+			body.Write(line)
+			body.WriteByte('\n')
+		}
+		if err := c.exec(ctx, "INSERT INTO "+batch.table+" FORMAT JSONEachRow", body.Bytes()); err != nil {
+			log.Error().Err(err).Str("table", batch.table).Int("rows", len(batch.rows)).Msg("Failed to insert into ClickHouse")
+			return static.NewErrorUtil("Failed to insert into ClickHouse", "SINK_FLUSH_FAILED", err, err.Error())
+		}
+		log.Debug().Str("table", batch.table).Int("rows", len(batch.rows)).Msg("ClickHouse rows inserted")
+	}
+	return nil
+}
+
+// ensureTable cria a tabela (ReplacingMergeTree ordenada pela chave) e adiciona
+// as colunas da versão de schema que ainda não existem. Sem chave conhecida a
+// criação fica para o próximo lote com dados.
+func (c *ClickHouse) ensureTable(ctx context.Context, batch *clickHouseBatch, ts *schema.TableSchema) error {
+	if len(batch.keys) == 0 {
+		return nil
+	}
+	keys := make(map[string]bool, len(batch.keys))
+	quotedKeys := make([]string, len(batch.keys))
+	for i, column := range batch.keys {
+		keys[column] = true
+		quotedKeys[i] = clickHouseQuote(column)
+	}
+
+	var definitions []string
+	for _, col := range ts.CapturedColumns() {
+		definition := clickHouseQuote(col.Name) + " " + clickHouseType(col, !keys[col.Name])
+		definitions = append(definitions, definition)
+	}
+	control := []string{
+		clickHouseQuote(ClickHouseColumnOp) + " LowCardinality(String)",
+		clickHouseQuote(ClickHouseColumnLSN) + " String",
+		clickHouseQuote(ClickHouseColumnSeqVal) + " String",
+		clickHouseQuote(ClickHouseColumnCommitTime) + " DateTime64(3, 'UTC')",
+		clickHouseQuote(ClickHouseColumnVersion) + " UInt256",
+		clickHouseQuote(ClickHouseColumnDeleted) + " UInt8",
+	}
+
+	statement := "CREATE TABLE IF NOT EXISTS " + batch.table + " (" + strings.Join(append(definitions, control...), ", ") +
+		") ENGINE = ReplacingMergeTree(" + clickHouseQuote(ClickHouseColumnVersion) + ", " + clickHouseQuote(ClickHouseColumnDeleted) +
+		") ORDER BY (" + strings.Join(quotedKeys, ", ") + ")"
+	if err := c.exec(ctx, statement, nil); err != nil {
+		return err
+	}
+
+	// tabela já existente: adiciona as colunas novas (as removidas na origem ficam)
+	var additions []string
+	for _, definition := range definitions {
+		additions = append(additions, "ADD COLUMN IF NOT EXISTS "+definition)
+	}
+	if len(additions) > 0 {
+		if err := c.exec(ctx, "ALTER TABLE "+batch.table+" "+strings.Join(additions, ", "), nil); err != nil {
+			return err
+		}
+	}
+	c.ensured[ts] = true
+	log.Info().Str("table", batch.source).Str("target", batch.table).Str("valid_from", ts.ValidFrom).Msg("ClickHouse table ensured")
+	return nil
+}
+
+// clickHouseType tipo da coluna no ClickHouse; colunas fora da chave são Nullable
+func clickHouseType(col schema.Column, nullable bool) string {
+	var t string
+	switch col.Type {
+	case "bit":
+		t = "Bool"
+	case "tinyint":
+		t = "UInt8"
+	case "smallint":
+		t = "Int16"
+	case "int":
+		t = "Int32"
+	case "bigint":
+		t = "Int64"
+	case "decimal", "numeric":
+		t = fmt.Sprintf("Decimal(%d, %d)", col.Precision, col.Scale)
+	case "money":
+		t = "Decimal(19, 4)"
+	case "smallmoney":
+		t = "Decimal(10, 4)"
+	case "float":
+		t = "Float64"
+	case "real":
+		t = "Float32"
+	case "date":
+		t = "Date32"
+	case "datetime", "smalldatetime":
+		t = "DateTime64(3, 'UTC')"
+	case "datetime2", "datetimeoffset":
+		t = fmt.Sprintf("DateTime64(%d, 'UTC')", min(col.Scale, 9))
+	case "uniqueidentifier":
+		t = "UUID"
+	default:
+		// textos, time, binários (base64) e tipos espaciais
+		t = "String"
+	}
+	if nullable {
+		return "Nullable(" + t + ")"
+	}
+	return t
+}
+
+// clickHouseLookupTable tabela externa com as chaves das linhas que aguardam LOBs
+const clickHouseLookupTable = "go_cdc_lookup"
+
+// fetchUnchanged preenche os LOBs não alterados que não estavam no lote com a
+// linha atual (não removida) do ClickHouse. As chaves vão como tabela externa
+// junto da consulta, com o índice da linha pendente. Linhas que não existem no
+// destino ficam com o valor padrão.
+func (c *ClickHouse) fetchUnchanged(ctx context.Context, batch *clickHouseBatch) error {
+	var pending []int
+	var columns []string
+	for i, row := range batch.rows {
+		if len(row.missing) == 0 {
+			continue
+		}
+		if row.lookup == nil || batch.keyTypes == nil {
+			c.warnUnchanged(batch, row.missing)
+			continue
+		}
+		pending = append(pending, i)
+		for _, column := range row.missing {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	quotedKeys := make([]string, len(batch.keys))
+	structure := []string{"`_row` UInt32"}
+	for i, column := range batch.keys {
+		quotedKeys[i] = clickHouseQuote(column)
+		structure = append(structure, quotedKeys[i]+" "+batch.keyTypes[i])
+	}
+	selected := []string{"k.`_row`"}
+	for _, column := range columns {
+		selected = append(selected, "t."+clickHouseQuote(column))
+	}
+	keyList := strings.Join(quotedKeys, ", ")
+	query := "SELECT " + strings.Join(selected, ", ") + " FROM " + clickHouseLookupTable + " AS k INNER JOIN (SELECT " + keyList + ", " +
+		strings.Join(clickHouseQuoteAll(columns), ", ") + " FROM " + batch.table + " FINAL WHERE " + clickHouseQuote(ClickHouseColumnDeleted) +
+		" = 0 AND (" + keyList + ") IN (SELECT " + keyList + " FROM " + clickHouseLookupTable + ")) AS t USING (" + keyList + ") FORMAT JSONCompactEachRow"
+
+	var keys bytes.Buffer
+	for _, i := range pending {
+		line := map[string]interface{}{"_row": i}
+		for column, value := range batch.rows[i].lookup {
+			line[column] = value
+		}
+		encoded, err := json.Marshal(line)
+		if err != nil {
+			return err
+		}
+		keys.Write(encoded)
+		keys.WriteByte('\n')
+	}
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(clickHouseLookupTable, clickHouseLookupTable)
+	if err != nil {
+		return err
+	}
+	part.Write(keys.Bytes())
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set(clickHouseLookupTable+"_structure", strings.Join(structure, ", "))
+	params.Set(clickHouseLookupTable+"_format", "JSONEachRow")
+	data, err := c.request(ctx, params, body.Bytes(), form.FormDataContentType())
+	if err != nil {
+		return err
+	}
+
+	found := make(map[int]bool, len(pending))
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		var result []interface{}
+		if err := decoder.Decode(&result); err != nil {
+			return fmt.Errorf("invalid lookup response: %w", err)
+		}
+		idx, ok := result[0].(float64)
+		if !ok || int(idx) < 0 || int(idx) >= len(batch.rows) || len(result) != len(columns)+1 {
+			return fmt.Errorf("invalid lookup row %v", result)
+		}
+		row := &batch.rows[int(idx)]
+		for j, column := range columns {
+			if slices.Contains(row.missing, column) {
+				row.values[column] = result[j+1]
+			}
+		}
+		found[int(idx)] = true
+	}
+	for _, i := range pending {
+		if !found[i] {
+			c.warnUnchanged(batch, batch.rows[i].missing)
+		}
+	}
+	return nil
+}
+
+// warnUnchanged avisa (uma vez por tabela) que LOBs não alterados ficaram sem valor
+func (c *ClickHouse) warnUnchanged(batch *clickHouseBatch, columns []string) {
+	if c.warned[batch.source+"#lob"] {
+		return
+	}
+	c.warned[batch.source+"#lob"] = true
+	log.Warn().Str("table", batch.source).Strs("columns", columns).Msg("Unchanged LOB columns not found in ClickHouse, row will have default values for them")
+}
+
+// clickHouseQuoteAll delimita cada identificador com crases
+func clickHouseQuoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = clickHouseQuote(name)
+	}
+	return quoted
+}
+
+// exec executa a instrução (com body opcional para INSERT)
+func (c *ClickHouse) exec(ctx context.Context, query string, body []byte) error {
+	_, err := c.request(ctx, url.Values{"query": {query}}, body, "")
+	return err
+}
+
+// request envia a requisição com os parâmetros e retorna o corpo da resposta,
+// repetindo em erros de rede e 5xx
+func (c *ClickHouse) request(ctx context.Context, params url.Values, body []byte, contentType string) ([]byte, error) {
+	params.Set("database", c.database)
+	params.Set("date_time_input_format", "best_effort")
+	params.Set("input_format_skip_unknown_fields", "1")
+	endpoint := c.url + "?" + params.Encode()
+
+	var lastErr error
+	for attempt := 0; attempt < c.maxAttempts; attempt++ {
+		if attempt > 0 {
+			wait := c.backoff.Duration(attempt - 1)
+			log.Warn().Err(lastErr).Int("attempt", attempt).Int("max_attempts", c.maxAttempts).Dur("backoff", wait).Msg("ClickHouse request failed, retrying")
+			if err := retry.Sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("User-Agent", static.APP_GO_CDC_NAME)
+		if c.username != "" {
+			req.Header.Set("X-ClickHouse-User", c.username)
+			req.Header.Set("X-ClickHouse-Key", c.password)
+		}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode < 300 {
+			data, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return data, err
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+		lastErr = fmt.Errorf("clickhouse responded with status %d: %s", resp.StatusCode, truncateBody(bytes.TrimSpace(data)))
+		if resp.StatusCode < 500 {
+			return nil, lastErr
+		}
+	}
+	return nil, lastErr
+}
+
+func (c *ClickHouse) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+// HealthCheck usa o endpoint /ping da interface HTTP
+func (c *ClickHouse) HealthCheck(ctx context.Context) static.ErrorUtil {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"ping", nil)
+	if err != nil {
+		return static.NewErrorUtil("ClickHouse unreachable", "SINK_UNHEALTHY", err, err.Error())
+	}
+	resp, err := c.client.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			err = fmt.Errorf("ping responded with status %d", resp.StatusCode)
+		}
+	}
+	if err != nil {
+		return static.NewErrorUtil("ClickHouse unreachable", "SINK_UNHEALTHY", err, err.Error())
+	}
+	return nil
+}
+
+func (c *ClickHouse) Close() static.ErrorUtil {
+	c.client.CloseIdleConnections()
+	return nil
+}
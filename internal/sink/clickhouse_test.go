@@ -0,0 +1,213 @@
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+)
+
+// chServer servidor de teste da interface HTTP. stored são os Notes já gravados
+// no destino, devolvidos na busca dos LOBs não alterados.
+type chServer struct {
+	mu      sync.Mutex
+	queries []string
+	inserts []map[string]interface{}
+	lookups []map[string]interface{}
+	stored  map[float64]string
+}
+
+func (s *chServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("query")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queries = append(s.queries, query)
+
+	switch {
+	case strings.HasPrefix(query, "INSERT"):
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var row map[string]interface{}
+			json.Unmarshal(scanner.Bytes(), &row)
+			s.inserts = append(s.inserts, row)
+		}
+	case strings.HasPrefix(query, "SELECT"):
+		file, _, err := r.FormFile(clickHouseLookupTable)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var key map[string]interface{}
+			json.Unmarshal([]byte(line), &key)
+			s.lookups = append(s.lookups, key)
+			if notes, ok := s.stored[key["Id"].(float64)]; ok {
+				fmt.Fprintf(w, "[%v,%q]\n", key["_row"], notes)
+			}
+		}
+	}
+}
+
+func newClickHouseTest(t *testing.T, srv *chServer) *ClickHouse {
+	t.Helper()
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	c, errCH := NewClickHouse(&config.Config{
+		ClickHouseURL:                   ts.URL,
+		ClickHouseAutoCreate:            true,
+		ClickHouseRetryMaxAttempts:      1,
+		ClickHouseRetryInitialBackoffMs: 1,
+	})
+	if errCH != nil {
+		t.Fatalf("NewClickHouse: %v", errCH)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// chUnchanged update de Status com Notes não alterado (omitido pelo CDC)
+func chUnchanged(lsn int, id int64) event.Event {
+	evt := testOrder(event.OpUpdate, testPosition(lsn, 1), map[string]interface{}{"Id": id, "Status": "new"}, map[string]interface{}{"Id": id, "Status": "paid"})
+	evt.UnchangedColumns = []string{"Notes"}
+	return evt
+}
+
+func TestClickHouseWritesVersionedRows(t *testing.T) {
+	srv := &chServer{stored: map[float64]string{2: "stored"}}
+	c := newClickHouseTest(t, srv)
+	ctx := context.Background()
+
+	events := []event.Event{
+		testOrder(event.OpInsert, testPosition(10, 1), nil, map[string]interface{}{"Id": int64(1), "Status": "new", "Notes": "first"}),
+		chUnchanged(11, 1), // Notes vem da linha anterior do lote
+		chUnchanged(12, 2), // Notes vem do ClickHouse
+		chUnchanged(13, 3), // linha inexistente no destino
+		testOrder(event.OpDelete, testPosition(14, 1), map[string]interface{}{"Id": int64(4), "Status": "new", "Notes": nil}, nil),
+	}
+	if errWrite := c.Write(ctx, events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if errFlush := c.Flush(ctx); errFlush != nil {
+		t.Fatalf("Flush: %v", errFlush)
+	}
+
+	if len(srv.queries) != 4 {
+		t.Fatalf("queries = %q", srv.queries)
+	}
+	wantDDL := "CREATE TABLE IF NOT EXISTS `default`.`dbo_Orders` (`Id` Int32, `Status` Nullable(String), `Notes` Nullable(String), `Total` Nullable(Int32), " +
+		"`_op` LowCardinality(String), `_lsn` String, `_seqval` String, `_commit_time` DateTime64(3, 'UTC'), `_version` UInt256, `is_deleted` UInt8) " +
+		"ENGINE = ReplacingMergeTree(`_version`, `is_deleted`) ORDER BY (`Id`)"
+	if srv.queries[0] != wantDDL {
+		t.Errorf("DDL = %s\nwant  %s", srv.queries[0], wantDDL)
+	}
+	if !strings.HasPrefix(srv.queries[1], "ALTER TABLE `default`.`dbo_Orders` ADD COLUMN IF NOT EXISTS `Id` Int32") ||
+		!strings.Contains(srv.queries[2], "FROM `default`.`dbo_Orders` FINAL WHERE `is_deleted` = 0") ||
+		srv.queries[3] != "INSERT INTO `default`.`dbo_Orders` FORMAT JSONEachRow" {
+		t.Errorf("queries = %q", srv.queries)
+	}
+
+	// só as chaves que não estavam no lote são buscadas
+	if len(srv.lookups) != 2 || srv.lookups[0]["Id"] != float64(2) || srv.lookups[1]["Id"] != float64(3) {
+		t.Errorf("lookups = %v", srv.lookups)
+	}
+
+	if len(srv.inserts) != 5 {
+		t.Fatalf("inserted %d rows, want 5", len(srv.inserts))
+	}
+	for i, row := range srv.inserts {
+		if row[ClickHouseColumnLSN] != events[i].Position.LSN || row[ClickHouseColumnOp] != string(events[i].Op) {
+			t.Errorf("row %d = %v", i, row)
+		}
+	}
+	// _version = LSN * 2^80 + seqval, em decimal
+	if got := srv.inserts[0][ClickHouseColumnVersion]; got != "12089258196146291747061761" {
+		t.Errorf("_version = %v", got)
+	}
+	if got := srv.inserts[4][ClickHouseColumnVersion]; got != "16924961474604808445886465" {
+		t.Errorf("_version = %v", got)
+	}
+	for i, want := range []float64{0, 0, 0, 0, 1} {
+		if srv.inserts[i][ClickHouseColumnDeleted] != want {
+			t.Errorf("row %d is_deleted = %v, want %v", i, srv.inserts[i][ClickHouseColumnDeleted], want)
+		}
+	}
+
+	if got := srv.inserts[1]["Notes"]; got != "first" || srv.inserts[1]["Status"] != "paid" {
+		t.Errorf("row with Notes from the batch = %v", srv.inserts[1])
+	}
+	if got := srv.inserts[2]["Notes"]; got != "stored" {
+		t.Errorf("row with Notes from ClickHouse = %v", srv.inserts[2])
+	}
+	if _, ok := srv.inserts[3]["Notes"]; ok {
+		t.Errorf("row missing in ClickHouse = %v", srv.inserts[3])
+	}
+}
+
+// Update da chave primária: a chave anterior recebe is_deleted = 1 na mesma versão
+func TestClickHouseKeyChangeTombstone(t *testing.T) {
+	srv := &chServer{}
+	c := newClickHouseTest(t, srv)
+	ctx := context.Background()
+
+	moved := testOrder(event.OpUpdate, testPosition(20, 1), testRow(1, "new"), testRow(5, "new"))
+	if errWrite := c.Write(ctx, []event.Event{moved}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if errFlush := c.Flush(ctx); errFlush != nil {
+		t.Fatalf("Flush: %v", errFlush)
+	}
+
+	if len(srv.inserts) != 2 {
+		t.Fatalf("inserted %v, want the new row and the old key tombstone", srv.inserts)
+	}
+	row, tombstone := srv.inserts[0], srv.inserts[1]
+	if row["Id"] != float64(5) || row[ClickHouseColumnDeleted] != float64(0) || row[ClickHouseColumnOp] != string(event.OpUpdate) {
+		t.Errorf("new row = %v", row)
+	}
+	if tombstone["Id"] != float64(1) || tombstone[ClickHouseColumnDeleted] != float64(1) || tombstone[ClickHouseColumnOp] != string(event.OpDelete) {
+		t.Errorf("tombstone = %v", tombstone)
+	}
+	if tombstone[ClickHouseColumnVersion] != row[ClickHouseColumnVersion] || tombstone[ClickHouseColumnLSN] != moved.Position.LSN {
+		t.Errorf("tombstone version = %v, row version = %v", tombstone[ClickHouseColumnVersion], row[ClickHouseColumnVersion])
+	}
+}
+
+func TestClickHouseLookupFailure(t *testing.T) {
+	srv := &chServer{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Query().Get("query"), "SELECT") {
+			http.Error(w, "table is read only", http.StatusBadRequest)
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	c, errCH := NewClickHouse(&config.Config{ClickHouseURL: ts.URL, ClickHouseRetryMaxAttempts: 1})
+	if errCH != nil {
+		t.Fatalf("NewClickHouse: %v", errCH)
+	}
+	defer c.Close()
+
+	ctx := context.Background()
+	if errWrite := c.Write(ctx, []event.Event{chUnchanged(11, 1)}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	// sem os LOBs a linha não é gravada: o lote é reenviado
+	errFlush := c.Flush(ctx)
+	if errFlush == nil || errFlush.Code() != "SINK_FLUSH_FAILED" {
+		t.Fatalf("expected SINK_FLUSH_FAILED, got %v", errFlush)
+	}
+	if len(srv.inserts) != 0 {
+		t.Errorf("inserted %v", srv.inserts)
+	}
+}
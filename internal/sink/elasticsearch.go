@@ -0,0 +1,574 @@
+// internal/sink/elasticsearch.go
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/retry"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// elasticsearchIndexReplacer troca os caracteres proibidos em nomes de índice
+var elasticsearchIndexReplacer = strings.NewReplacer(
+	`\`, "_", "/", "_", "*", "_", "?", "_", `"`, "_", "<", "_", ">", "_", "|", "_", " ", "_", ",", "_", "#", "_", ":", "_",
+)
+
+// elasticsearchMaxIDBytes limite do _id; chaves maiores usam o SHA-256 da chave
+const elasticsearchMaxIDBytes = 512
+
+// Campos com a posição do último evento aplicado ao documento
+const (
+	ElasticsearchFieldLSN    = "_lsn"
+	ElasticsearchFieldSeqVal = "_seqval"
+)
+
+// elasticsearchScript aplica o evento só se a posição for posterior à do
+// documento. LSN e seqval têm largura fixa em hex, então a comparação de texto
+// equivale à ordem do log. Delete de documento inexistente não cria nada.
+const elasticsearchScript = `boolean stale = false;
+if (ctx._source._lsn != null) {
+  int c = ctx._source._lsn.compareTo(params.lsn);
+  if (c == 0 && ctx._source._seqval != null) { c = ctx._source._seqval.compareTo(params.seqval); }
+  stale = c >= 0;
+}
+if (stale) {
+  ctx.op = 'noop';
+} else if (params.delete) {
+  ctx.op = ctx.op == 'create' ? 'noop' : 'delete';
+} else {
+  if (!params.partial) { ctx._source.clear(); }
+  ctx._source.putAll(params.doc);
+  ctx._source._lsn = params.lsn;
+  ctx._source._seqval = params.seqval;
+}`
+
+// Elasticsearch espelha as tabelas em índices do Elasticsearch/OpenSearch pela
+// API _bulk. O _id do documento é a chave primária e toda ação é um scripted
+// upsert que compara a posição do evento (LSN e seqval) com a gravada no
+// documento (_lsn, _seqval): eventos antigos ou reenviados viram noop, então
+// reenvios e escritas fora de ordem não regridem o documento. Inserts, updates
+// e snapshots substituem a linha inteira, updates com LOBs não alterados
+// atualizam só as colunas recebidas e deletes removem o documento; um update
+// da chave primária remove também o documento da chave anterior. Itens
+// recusados com erro definitivo vão para o DLQ (arquivo JSON Lines).
+type Elasticsearch struct {
+	client        *http.Client
+	urls          []string
+	username      string
+	password      string
+	apiKey        string
+	indexTemplate string
+	refresh       string
+	maxBulkBytes  int
+	maxAttempts   int
+	backoff       retry.Backoff
+	dlq           *os.File
+
+	mu      sync.Mutex
+	next    int              // próxima URL (troca após falha de rede ou 5xx)
+	lastErr static.ErrorUtil // resultado do último lote, usado no health check
+	warned  map[string]bool
+}
+
+// ElasticsearchDLQRecord linha gravada no DLQ para cada item recusado
+type ElasticsearchDLQRecord struct {
+	Index       string          `json:"index"`
+	ID          string          `json:"id"`
+	Action      string          `json:"action"`
+	Status      int             `json:"status"`
+	ErrorType   string          `json:"error_type,omitempty"`
+	ErrorReason string          `json:"error_reason,omitempty"`
+	FailedAt    time.Time       `json:"failed_at"`
+	Event       json.RawMessage `json:"event"`
+}
+
+// elasticsearchItem ação do _bulk gerada a partir de um evento
+type elasticsearchItem struct {
+	evt    *event.Event
+	index  string
+	id     string
+	action string
+	lines  []byte // metadados + documento, terminados em \n
+}
+
+type elasticsearchBulkResponse struct {
+	Errors bool                                     `json:"errors"`
+	Items  []map[string]elasticsearchBulkItemResult `json:"items"`
+}
+
+type elasticsearchBulkItemResult struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// NewElasticsearch cria o sink (construtor)
+func NewElasticsearch(cfg *config.Config) (*Elasticsearch, static.ErrorUtil) {
+	var urls []string
+	for _, raw := range config.SplitList(cfg.ElasticsearchURLs) {
+		urls = append(urls, strings.TrimRight(raw, "/"))
+	}
+	if len(urls) == 0 {
+		return nil, static.NewErrorUtil("Elasticsearch URLs not configured", "ELASTICSEARCH_CONFIG_INVALID", nil, "APP_GO_CDC_ELASTICSEARCH_URLS")
+	}
+	switch cfg.ElasticsearchRefresh {
+	case "", "false", "true", "wait_for":
+	default:
+		return nil, static.NewErrorUtil("Invalid Elasticsearch refresh policy", "ELASTICSEARCH_CONFIG_INVALID", nil, cfg.ElasticsearchRefresh)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.ElasticsearchTLSCAFile != "" || cfg.ElasticsearchTLSCertFile != "" || cfg.ElasticsearchTLSInsecureSkipVerify {
+		tlsConfig, err := buildTLSConfig(cfg.ElasticsearchTLSCAFile, cfg.ElasticsearchTLSCertFile, cfg.ElasticsearchTLSKeyFile, cfg.ElasticsearchTLSInsecureSkipVerify)
+		if err != nil {
+			log.Error().Caller().Err(err).Msg("Failed to load Elasticsearch TLS configuration")
+			return nil, static.NewErrorUtil("Failed to load Elasticsearch TLS configuration", "ELASTICSEARCH_CONFIG_INVALID", err, err.Error())
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	timeout := time.Duration(cfg.ElasticsearchTimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = static.APP_GO_CDC_ELASTICSEARCH_TIMEOUT_MS * time.Millisecond
+	}
+	maxBulkBytes := cfg.ElasticsearchMaxBulkBytes
+	if maxBulkBytes <= 0 {
+		maxBulkBytes = static.APP_GO_CDC_ELASTICSEARCH_MAX_BULK_BYTES
+	}
+	initial := time.Duration(max(cfg.ElasticsearchRetryInitialBackoffMs, 1)) * time.Millisecond
+	maxBackoff := max(time.Duration(cfg.ElasticsearchRetryMaxBackoffMs)*time.Millisecond, initial)
+
+	e := &Elasticsearch{
+		client:        &http.Client{Transport: transport, Timeout: timeout},
+		urls:          urls,
+		username:      cfg.ElasticsearchUsername,
+		password:      cfg.ElasticsearchPassword,
+		apiKey:        cfg.ElasticsearchAPIKey,
+		indexTemplate: cfg.ElasticsearchIndexTemplate,
+		refresh:       cfg.ElasticsearchRefresh,
+		maxBulkBytes:  maxBulkBytes,
+		maxAttempts:   max(cfg.ElasticsearchRetryMaxAttempts, 1),
+		backoff:       retry.NewBackoff(initial, maxBackoff),
+		warned:        make(map[string]bool),
+	}
+	if e.indexTemplate == "" {
+		e.indexTemplate = static.APP_GO_CDC_ELASTICSEARCH_INDEX_TEMPLATE
+	}
+	if e.refresh == "" {
+		e.refresh = static.APP_GO_CDC_ELASTICSEARCH_REFRESH
+	}
+
+	if cfg.ElasticsearchDLQFile != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.ElasticsearchDLQFile), 0o755); err != nil {
+			return nil, static.NewErrorUtil("Failed to create Elasticsearch DLQ directory", "ELASTICSEARCH_CONFIG_INVALID", err, err.Error())
+		}
+		dlq, err := os.OpenFile(cfg.ElasticsearchDLQFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			log.Error().Caller().Err(err).Str("path", cfg.ElasticsearchDLQFile).Msg("Failed to open Elasticsearch DLQ")
+			return nil, static.NewErrorUtil("Failed to open Elasticsearch DLQ", "ELASTICSEARCH_CONFIG_INVALID", err, err.Error())
+		}
+		e.dlq = dlq
+	}
+	return e, nil
+}
+
+func (e *Elasticsearch) Name() string {
+	return TypeElasticsearch
+}
+
+// Index resolve o template para o evento (minúsculas, sem caracteres proibidos)
+func (e *Elasticsearch) Index(evt *event.Event) string {
+	index := strings.ToLower(elasticsearchIndexReplacer.Replace(resolveTableTemplate(e.indexTemplate, evt, nil)))
+	return strings.TrimLeft(index, "-_+")
+}
+
+// elasticsearchID _id do documento com a chave lida de row: valor da chave
+// simples ou JSON da chave composta
+func elasticsearchID(evt *event.Event, row map[string]interface{}) string {
+	var id string
+	if len(evt.KeyColumns) == 1 {
+		id = redisValue(row[evt.KeyColumns[0]])
+	} else {
+		key := event.Event{KeyColumns: evt.KeyColumns, Key: row}
+		id = string(key.MessageKey())
+	}
+	if len(id) > elasticsearchMaxIDBytes {
+		sum := sha256.Sum256([]byte(id))
+		id = hex.EncodeToString(sum[:])
+	}
+	return id
+}
+
+// items monta as ações do _bulk para o evento. Um update que altera a chave
+// primária também remove o documento da chave anterior, com a mesma
+// verificação de posição, para que ele não fique órfão no índice.
+func (e *Elasticsearch) items(evt *event.Event) ([]*elasticsearchItem, error) {
+	switch evt.Op {
+	case event.OpInsert, event.OpRead, event.OpUpdate, event.OpDelete:
+	default:
+		return nil, nil
+	}
+	if len(evt.KeyColumns) == 0 {
+		// sem chave o documento seria duplicado a cada reenvio
+		if table := evt.FullTableName(); !e.warned[table] {
+			e.warned[table] = true
+			log.Warn().Str("table", table).Msg("Table without primary key, skipping Elasticsearch indexing")
+		}
+		return nil, nil
+	}
+
+	var items []*elasticsearchItem
+	id := elasticsearchID(evt, evt.Key)
+	if evt.Op == event.OpUpdate && evt.Before != nil {
+		if oldID := elasticsearchID(evt, evt.Before); oldID != id {
+			old, err := e.item(evt, oldID, true)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, old)
+		}
+	}
+	it, err := e.item(evt, id, evt.Op == event.OpDelete)
+	if err != nil {
+		return nil, err
+	}
+	return append(items, it), nil
+}
+
+// item monta o scripted upsert do evento para o documento id
+func (e *Elasticsearch) item(evt *event.Event, id string, remove bool) (*elasticsearchItem, error) {
+	it := &elasticsearchItem{evt: evt, index: e.Index(evt), id: id, action: "update"}
+	meta := map[string]interface{}{"_index": it.index, "_id": it.id, "retry_on_conflict": 3}
+	doc := evt.After
+	if doc == nil || remove {
+		doc = map[string]interface{}{}
+	}
+	source := map[string]interface{}{
+		"scripted_upsert": true,
+		"upsert":          map[string]interface{}{},
+		"script": map[string]interface{}{
+			"lang":   "painless",
+			"source": elasticsearchScript,
+			"params": map[string]interface{}{
+				"lsn":     evt.Position.LSN,
+				"seqval":  evt.Position.SeqVal,
+				"delete":  remove,
+				"partial": !remove && evt.Op == event.OpUpdate && len(evt.UnchangedColumns) > 0,
+				"doc":     doc,
+			},
+		},
+	}
+
+	metaLine, err := json.Marshal(map[string]interface{}{it.action: meta})
+	if err != nil {
+		return nil, err
+	}
+	sourceLine, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+	it.lines = append(append(append(metaLine, '\n'), sourceLine...), '\n')
+	return it, nil
+}
+
+func (e *Elasticsearch) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	errWrite := e.write(ctx, events)
+	e.mu.Lock()
+	e.lastErr = errWrite
+	e.mu.Unlock()
+	return errWrite
+}
+
+func (e *Elasticsearch) write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	var pending []*elasticsearchItem
+	size := 0
+	for i := range events {
+		evt := &events[i]
+		if evt.Op == event.OpTruncate {
+			// as ações anteriores precisam estar indexadas antes da remoção
+			if errBulk := e.bulk(ctx, pending); errBulk != nil {
+				return errBulk
+			}
+			pending, size = nil, 0
+			if errTruncate := e.deleteAll(ctx, evt); errTruncate != nil {
+				return errTruncate
+			}
+			continue
+		}
+
+		items, err := e.items(evt)
+		if err != nil {
+			return static.NewErrorUtil("Failed to encode event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+		for _, it := range items {
+			if len(pending) > 0 && size+len(it.lines) > e.maxBulkBytes {
+				if errBulk := e.bulk(ctx, pending); errBulk != nil {
+					return errBulk
+				}
+				pending, size = nil, 0
+			}
+			pending = append(pending, it)
+			size += len(it.lines)
+		}
+	}
+	return e.bulk(ctx, pending)
+}
+
+// bulk envia as ações e repete apenas os itens com erro transitório (429/5xx).
+// Os demais erros vão para o DLQ ou, sem DLQ configurado, falham o lote.
+func (e *Elasticsearch) bulk(ctx context.Context, items []*elasticsearchItem) static.ErrorUtil {
+	var lastErr error
+	for attempt := 0; len(items) > 0; attempt++ {
+		if attempt > 0 {
+			if attempt >= e.maxAttempts {
+				log.Error().Err(lastErr).Int("items", len(items)).Msg("Elasticsearch bulk failed")
+				return static.NewErrorUtil("Elasticsearch bulk failed", "SINK_WRITE_FAILED", lastErr, lastErr.Error())
+			}
+			wait := e.backoff.Duration(attempt - 1)
+			log.Warn().
+				Err(lastErr).
+				Int("items", len(items)).
+				Int("attempt", attempt).
+				Int("max_attempts", e.maxAttempts).
+				Dur("backoff", wait).
+				Msg("Elasticsearch bulk failed, retrying")
+			if err := retry.Sleep(ctx, wait); err != nil {
+				return static.NewErrorUtil("Elasticsearch bulk cancelled", "SINK_WRITE_FAILED", err, err.Error())
+			}
+		}
+
+		var body bytes.Buffer
+		for _, it := range items {
+			body.Write(it.lines)
+		}
+		status, data, err := e.do(ctx, http.MethodPost, "/_bulk?refresh="+e.refresh, "application/x-ndjson", body.Bytes())
+		if err != nil || status == http.StatusTooManyRequests || status >= 500 {
+			if err == nil {
+				err = fmt.Errorf("bulk responded with status %d", status)
+			}
+			lastErr = err
+			continue
+		}
+		if status >= 300 {
+			err = fmt.Errorf("bulk responded with status %d: %s", status, truncateBody(data))
+			log.Error().Err(err).Msg("Elasticsearch bulk rejected")
+			return static.NewErrorUtil("Elasticsearch bulk rejected", "SINK_WRITE_FAILED", err, err.Error())
+		}
+
+		var resp elasticsearchBulkResponse
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return static.NewErrorUtil("Invalid Elasticsearch bulk response", "SINK_WRITE_FAILED", err, err.Error())
+		}
+		if !resp.Errors {
+			return nil
+		}
+		if len(resp.Items) != len(items) {
+			err = fmt.Errorf("bulk returned %d items for %d actions", len(resp.Items), len(items))
+			return static.NewErrorUtil("Invalid Elasticsearch bulk response", "SINK_WRITE_FAILED", err, err.Error())
+		}
+
+		var retryItems []*elasticsearchItem
+		var rejected []ElasticsearchDLQRecord
+		for i, result := range resp.Items {
+			it := items[i]
+			item := result[it.action]
+			switch {
+			case item.Status < 300:
+			case item.Status == http.StatusConflict:
+				// conflito persistente após retry_on_conflict: outra escrita mais
+				// nova no mesmo documento venceu
+				log.Debug().Str("index", it.index).Str("id", it.id).Str("position", it.evt.Position.String()).Msg("Conflicting Elasticsearch action ignored")
+			case item.Status == http.StatusTooManyRequests || item.Status >= 500:
+				retryItems = append(retryItems, it)
+				lastErr = fmt.Errorf("bulk item %s/%s responded with status %d", it.index, it.id, item.Status)
+			default:
+				record := ElasticsearchDLQRecord{Index: it.index, ID: it.id, Action: it.action, Status: item.Status, FailedAt: time.Now().UTC()}
+				if item.Error != nil {
+					record.ErrorType, record.ErrorReason = item.Error.Type, item.Error.Reason
+				}
+				// o evento só é serializado para os itens rejeitados
+				record.Event, _ = json.Marshal(it.evt)
+				rejected = append(rejected, record)
+			}
+		}
+		if errDLQ := e.deadLetter(rejected); errDLQ != nil {
+			return errDLQ
+		}
+		items = retryItems
+	}
+	return nil
+}
+
+// deadLetter grava os itens recusados no DLQ com fsync antes de o lote ser confirmado
+func (e *Elasticsearch) deadLetter(records []ElasticsearchDLQRecord) static.ErrorUtil {
+	if len(records) == 0 {
+		return nil
+	}
+	first := records[0]
+	if e.dlq == nil {
+		err := fmt.Errorf("%s %s/%s: %s: %s", first.Action, first.Index, first.ID, first.ErrorType, first.ErrorReason)
+		log.Error().Err(err).Int("rejected", len(records)).Msg("Elasticsearch rejected bulk items and no DLQ is configured")
+		return static.NewErrorUtil("Elasticsearch rejected bulk items", "SINK_WRITE_FAILED", err, err.Error())
+	}
+
+	var buf bytes.Buffer
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return static.NewErrorUtil("Failed to encode DLQ record", "SINK_WRITE_FAILED", err, err.Error())
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if _, err := e.dlq.Write(buf.Bytes()); err != nil {
+		return static.NewErrorUtil("Failed to write Elasticsearch DLQ", "SINK_WRITE_FAILED", err, err.Error())
+	}
+	if err := e.dlq.Sync(); err != nil {
+		return static.NewErrorUtil("Failed to sync Elasticsearch DLQ", "SINK_WRITE_FAILED", err, err.Error())
+	}
+	log.Warn().
+		Int("rejected", len(records)).
+		Str("index", first.Index).
+		Str("error_type", first.ErrorType).
+		Str("error_reason", first.ErrorReason).
+		Msg("Elasticsearch bulk items sent to DLQ")
+	return nil
+}
+
+// deleteAll remove os documentos do índice da tabela truncada (_delete_by_query)
+func (e *Elasticsearch) deleteAll(ctx context.Context, truncate *event.Event) static.ErrorUtil {
+	index := e.Index(truncate)
+	path := "/" + url.PathEscape(index) + "/_delete_by_query?conflicts=proceed&refresh=" + strconv.FormatBool(e.refresh != "false")
+	body := []byte(`{"query":{"match_all":{}}}`)
+
+	var lastErr error
+	for attempt := 0; attempt < e.maxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := retry.Sleep(ctx, e.backoff.Duration(attempt-1)); err != nil {
+				return static.NewErrorUtil("Elasticsearch truncate cancelled", "SINK_WRITE_FAILED", err, err.Error())
+			}
+		}
+		status, data, err := e.do(ctx, http.MethodPost, path, "application/json", body)
+		switch {
+		case err != nil:
+			lastErr = err
+			continue
+		case status < 300 || status == http.StatusNotFound:
+			log.Info().Str("table", truncate.FullTableName()).Str("index", index).Msg("Elasticsearch documents removed after truncate")
+			return nil
+		case status == http.StatusTooManyRequests || status >= 500:
+			lastErr = fmt.Errorf("delete_by_query responded with status %d", status)
+			continue
+		}
+		lastErr = fmt.Errorf("delete_by_query responded with status %d: %s", status, truncateBody(data))
+		break
+	}
+	log.Error().Err(lastErr).Str("index", index).Msg("Elasticsearch truncate failed")
+	return static.NewErrorUtil("Elasticsearch truncate failed", "SINK_WRITE_FAILED", lastErr, lastErr.Error())
+}
+
+// do executa uma requisição na URL atual; erros de rede e 5xx passam para a próxima URL
+func (e *Elasticsearch) do(ctx context.Context, method string, path string, contentType string, body []byte) (int, []byte, error) {
+	e.mu.Lock()
+	base := e.urls[e.next%len(e.urls)]
+	e.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, method, base+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("User-Agent", static.APP_GO_CDC_NAME)
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+e.apiKey)
+	} else if e.username != "" {
+		req.SetBasicAuth(e.username, e.password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil || resp.StatusCode >= 500 {
+		e.mu.Lock()
+		e.next++
+		e.mu.Unlock()
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
+
+// truncateBody limita o corpo de erro incluído nas mensagens
+func truncateBody(data []byte) string {
+	if len(data) > 512 {
+		return string(data[:512]) + "..."
+	}
+	return string(data)
+}
+
+func (e *Elasticsearch) Flush(ctx context.Context) static.ErrorUtil {
+	return nil
+}
+
+func (e *Elasticsearch) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+// HealthCheck consulta _cluster/health (status red é considerado indisponível)
+func (e *Elasticsearch) HealthCheck(ctx context.Context) static.ErrorUtil {
+	status, data, err := e.do(ctx, http.MethodGet, "/_cluster/health", "", nil)
+	if err == nil && status >= 300 {
+		err = fmt.Errorf("cluster health responded with status %d", status)
+	}
+	if err == nil {
+		var health struct {
+			Status string `json:"status"`
+		}
+		if errJSON := json.Unmarshal(data, &health); errJSON == nil && health.Status == "red" {
+			err = fmt.Errorf("cluster status is red")
+		}
+	}
+	if err != nil {
+		return static.NewErrorUtil("Elasticsearch unhealthy", "SINK_UNHEALTHY", err, err.Error())
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lastErr
+}
+
+func (e *Elasticsearch) Close() static.ErrorUtil {
+	e.client.CloseIdleConnections()
+	if e.dlq != nil {
+		if err := e.dlq.Close(); err != nil {
+			return static.NewErrorUtil("Failed to close Elasticsearch DLQ", "SINK_CLOSE_FAILED", err, err.Error())
+		}
+	}
+	return nil
+}
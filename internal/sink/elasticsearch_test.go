@@ -0,0 +1,232 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+)
+
+// esBulkAction ação recebida pelo servidor de teste
+type esBulkAction struct {
+	meta   map[string]map[string]interface{}
+	params map[string]interface{}
+}
+
+// esServer servidor de teste do _bulk. status retorna o status de cada item
+// (chamada, índice na requisição); requests registra método e caminho.
+type esServer struct {
+	mu       sync.Mutex
+	requests []string
+	actions  [][]esBulkAction
+	status   func(call, item int) int
+}
+
+func (s *esServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	if !strings.HasSuffix(r.URL.Path, "/_bulk") {
+		w.Write([]byte(`{"deleted":0}`))
+		return
+	}
+
+	var actions []esBulkAction
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 1<<20), 1<<20)
+	for scanner.Scan() {
+		var action esBulkAction
+		json.Unmarshal(scanner.Bytes(), &action.meta)
+		scanner.Scan()
+		var source struct {
+			Script struct {
+				Params map[string]interface{} `json:"params"`
+			} `json:"script"`
+		}
+		json.Unmarshal(scanner.Bytes(), &source)
+		action.params = source.Script.Params
+		actions = append(actions, action)
+	}
+	call := len(s.actions)
+	s.actions = append(s.actions, actions)
+
+	resp := elasticsearchBulkResponse{}
+	for i := range actions {
+		status := http.StatusOK
+		if s.status != nil {
+			status = s.status(call, i)
+		}
+		result := elasticsearchBulkItemResult{Status: status}
+		if status >= 300 {
+			resp.Errors = true
+			result.Error = &struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			}{Type: "test_exception", Reason: fmt.Sprintf("status %d", status)}
+		}
+		resp.Items = append(resp.Items, map[string]elasticsearchBulkItemResult{"update": result})
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func newElasticsearchTest(t *testing.T, srv *esServer, dlq string) *Elasticsearch {
+	t.Helper()
+	ts := httptest.NewServer(srv)
+	t.Cleanup(ts.Close)
+	e, errES := NewElasticsearch(&config.Config{
+		ElasticsearchURLs:                  ts.URL,
+		ElasticsearchDLQFile:               dlq,
+		ElasticsearchRetryMaxAttempts:      3,
+		ElasticsearchRetryInitialBackoffMs: 1,
+		ElasticsearchRetryMaxBackoffMs:     5,
+	})
+	if errES != nil {
+		t.Fatalf("NewElasticsearch: %v", errES)
+	}
+	t.Cleanup(func() { e.Close() })
+	return e
+}
+
+func TestElasticsearchScriptedUpserts(t *testing.T) {
+	srv := &esServer{}
+	e := newElasticsearchTest(t, srv, "")
+
+	partial := testOrder(event.OpUpdate, testPosition(11, 1), nil, testRow(2, "new"))
+	partial.UnchangedColumns = []string{"Notes"}
+	events := []event.Event{testOrder(event.OpInsert, testPosition(10, 1), nil, testRow(1, "new")), partial, testOrder(event.OpDelete, testPosition(12, 1), testRow(3, "new"), nil)}
+	if errWrite := e.Write(context.Background(), events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	if len(srv.actions) != 1 || len(srv.actions[0]) != 3 {
+		t.Fatalf("bulk actions = %v", srv.actions)
+	}
+	for i, action := range srv.actions[0] {
+		meta, ok := action.meta["update"]
+		if !ok {
+			t.Fatalf("action %d is not a scripted update: %v", i, action.meta)
+		}
+		if _, versioned := meta["version"]; versioned || meta["_index"] != "cdc-shop-dbo-orders" || meta["_id"] != fmt.Sprint(i+1) {
+			t.Errorf("action %d meta = %v", i, meta)
+		}
+		if action.params["lsn"] != events[i].Position.LSN || action.params["seqval"] != events[i].Position.SeqVal {
+			t.Errorf("action %d position params = %v", i, action.params)
+		}
+	}
+	if got := srv.actions[0]; got[0].params["partial"] != false || got[1].params["partial"] != true || got[2].params["delete"] != true {
+		t.Errorf("params = %v / %v / %v", got[0].params, got[1].params, got[2].params)
+	}
+}
+
+// Update da chave primária: o documento da chave anterior é removido na mesma
+// requisição, com a posição do evento
+func TestElasticsearchPrimaryKeyChange(t *testing.T) {
+	srv := &esServer{}
+	e := newElasticsearchTest(t, srv, "")
+
+	moved := testOrder(event.OpUpdate, testPosition(20, 1), testRow(1, "new"), testRow(5, "paid"))
+	same := testOrder(event.OpUpdate, testPosition(21, 1), testRow(2, "new"), testRow(2, "paid"))
+	if errWrite := e.Write(context.Background(), []event.Event{moved, same}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	if len(srv.actions) != 1 || len(srv.actions[0]) != 3 {
+		t.Fatalf("bulk actions = %v", srv.actions)
+	}
+	want := []struct {
+		id     string
+		delete bool
+		lsn    string
+	}{{"1", true, moved.Position.LSN}, {"5", false, moved.Position.LSN}, {"2", false, same.Position.LSN}}
+	for i, action := range srv.actions[0] {
+		if action.meta["update"]["_id"] != want[i].id || action.params["delete"] != want[i].delete || action.params["lsn"] != want[i].lsn {
+			t.Errorf("action %d = %v %v, want %+v", i, action.meta, action.params, want[i])
+		}
+	}
+}
+
+func TestElasticsearchBulkItemErrors(t *testing.T) {
+	dlq := filepath.Join(t.TempDir(), "dlq.jsonl")
+	srv := &esServer{status: func(call, item int) int {
+		switch {
+		case item == 0:
+			return http.StatusConflict
+		case item == 1:
+			return http.StatusBadRequest
+		case item == 2 && call == 0:
+			return http.StatusTooManyRequests
+		}
+		return http.StatusOK
+	}}
+	e := newElasticsearchTest(t, srv, dlq)
+
+	events := []event.Event{testOrder(event.OpInsert, testPosition(10, 1), nil, testRow(1, "new")), testOrder(event.OpInsert, testPosition(11, 1), nil, testRow(2, "new")), testOrder(event.OpInsert, testPosition(12, 1), nil, testRow(3, "new"))}
+	if errWrite := e.Write(context.Background(), events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	// só o item com 429 é reenviado
+	if len(srv.actions) != 2 || len(srv.actions[1]) != 1 || srv.actions[1][0].meta["update"]["_id"] != "3" {
+		t.Fatalf("bulk calls = %v", srv.actions)
+	}
+
+	data, err := os.ReadFile(dlq)
+	if err != nil {
+		t.Fatalf("read DLQ: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("DLQ has %d records, want 1", len(lines))
+	}
+	var record ElasticsearchDLQRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("DLQ record: %v", err)
+	}
+	var evt event.Event
+	json.Unmarshal(record.Event, &evt)
+	if record.ID != "2" || record.Status != http.StatusBadRequest || record.ErrorType != "test_exception" || evt.Position != events[1].Position {
+		t.Errorf("DLQ record = %+v", record)
+	}
+}
+
+func TestElasticsearchRejectedWithoutDLQ(t *testing.T) {
+	srv := &esServer{status: func(call, item int) int { return http.StatusBadRequest }}
+	e := newElasticsearchTest(t, srv, "")
+
+	errWrite := e.Write(context.Background(), []event.Event{testOrder(event.OpInsert, testPosition(10, 1), nil, testRow(1, "new"))})
+	if errWrite == nil || errWrite.Code() != "SINK_WRITE_FAILED" {
+		t.Fatalf("expected SINK_WRITE_FAILED, got %v", errWrite)
+	}
+}
+
+func TestElasticsearchTruncate(t *testing.T) {
+	srv := &esServer{}
+	e := newElasticsearchTest(t, srv, "")
+
+	events := []event.Event{
+		testOrder(event.OpInsert, testPosition(10, 1), nil, testRow(1, "new")),
+		testOrder(event.OpTruncate, event.Position{LSN: testLSN(11)}, nil, nil),
+		testOrder(event.OpInsert, testPosition(12, 1), nil, testRow(2, "new")),
+	}
+	if errWrite := e.Write(context.Background(), events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	want := []string{"POST /_bulk", "POST /cdc-shop-dbo-orders/_delete_by_query", "POST /_bulk"}
+	if fmt.Sprint(srv.requests) != fmt.Sprint(want) {
+		t.Errorf("requests = %v, want %v", srv.requests, want)
+	}
+}
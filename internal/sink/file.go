@@ -0,0 +1,468 @@
+// internal/sink/file.go
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rs/zerolog/log"
+)
+
+// Compressões aceitas em APP_GO_CDC_FILE_COMPRESSION
+const (
+	FileCompressionNone = "none"
+	FileCompressionGzip = "gzip"
+	FileCompressionZstd = "zstd"
+)
+
+// fileRotationCheckInterval intervalo da verificação de idade do segmento aberto
+const fileRotationCheckInterval = time.Second
+
+// fileTempPrefix prefixo dos segmentos em escrita (ignorados por leitores)
+const fileTempPrefix = "."
+
+// fileTempSuffix sufixo dos segmentos em escrita
+const fileTempSuffix = ".tmp"
+
+// SegmentInfo entrada do manifest (JSON Lines) de um segmento fechado
+type SegmentInfo struct {
+	Segment       string         `json:"segment"`
+	Compression   string         `json:"compression"`
+	Events        int            `json:"events"`
+	Bytes         int64          `json:"bytes"` // JSON não comprimido
+	FirstPosition event.Position `json:"first_position"`
+	LastPosition  event.Position `json:"last_position"`
+	CreatedAt     time.Time      `json:"created_at"`
+	ClosedAt      time.Time      `json:"closed_at"`
+	// Recovered segmento encontrado em escrita na inicialização (parada
+	// abrupta): contém o que foi confirmado até o último Flush, sem faixa de
+	// posições e, se comprimido, sem o rodapé do formato. Events e Bytes
+	// contam as linhas completas legíveis do segmento.
+	Recovered bool `json:"recovered,omitempty"`
+}
+
+// segmentWriter compressor (ou buffer) sobre o arquivo do segmento
+type segmentWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// bufferedSegment segmento sem compressão
+type bufferedSegment struct {
+	*bufio.Writer
+}
+
+func (b bufferedSegment) Close() error {
+	return b.Flush()
+}
+
+// segment segmento aberto para escrita
+type segment struct {
+	file   *os.File
+	writer segmentWriter
+	info   SegmentInfo
+}
+
+// File grava os eventos em JSON Lines em segmentos rotacionados por tamanho
+// (bytes não comprimidos) ou idade. O segmento é escrito como arquivo temporário
+// oculto e renomeado ao ser fechado; cada segmento fechado é registrado no
+// manifest com a faixa de posições. Flush faz fsync do segmento aberto, então
+// os offsets só são gravados com os eventos já em disco.
+type File struct {
+	dir         string
+	prefix      string
+	compression string
+	maxBytes    int64
+	maxAge      time.Duration
+
+	mu       sync.Mutex
+	current  *segment
+	sequence int
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewFile cria o sink (construtor) e finaliza segmentos deixados em escrita por uma parada abrupta
+func NewFile(cfg *config.Config) (*File, static.ErrorUtil) {
+	if cfg.FileDir == "" {
+		return nil, static.NewErrorUtil("File sink directory not configured", "FILE_SINK_CONFIG_INVALID", nil, "APP_GO_CDC_FILE_DIR")
+	}
+	compression := strings.ToLower(strings.TrimSpace(cfg.FileCompression))
+	switch compression {
+	case "":
+		compression = FileCompressionNone
+	case FileCompressionNone, FileCompressionGzip, FileCompressionZstd:
+	default:
+		log.Error().Caller().Str("compression", cfg.FileCompression).Msg("Unsupported file sink compression")
+		return nil, static.NewErrorUtil("Unsupported file sink compression", "FILE_SINK_CONFIG_INVALID", nil, cfg.FileCompression)
+	}
+
+	f := &File{
+		dir:         cfg.FileDir,
+		prefix:      cfg.FilePrefix,
+		compression: compression,
+		maxBytes:    int64(cfg.FileMaxBytes),
+		maxAge:      time.Duration(cfg.FileMaxAgeSeconds) * time.Second,
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	if f.prefix == "" {
+		f.prefix = static.APP_GO_CDC_FILE_PREFIX
+	}
+	if f.maxBytes <= 0 {
+		f.maxBytes = static.APP_GO_CDC_FILE_MAX_BYTES
+	}
+	if f.maxAge <= 0 {
+		f.maxAge = static.APP_GO_CDC_FILE_MAX_AGE_SECONDS * time.Second
+	}
+
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		log.Error().Caller().Err(err).Str("dir", f.dir).Msg("Failed to create file sink directory")
+		return nil, static.NewErrorUtil("Failed to create file sink directory", "FILE_SINK_CONFIG_INVALID", err, err.Error())
+	}
+	if err := f.recover(); err != nil {
+		log.Error().Caller().Err(err).Str("dir", f.dir).Msg("Failed to recover file sink segments")
+		return nil, static.NewErrorUtil("Failed to recover file sink segments", "FILE_SINK_RECOVER_FAILED", err, err.Error())
+	}
+
+	go f.rotateLoop()
+	return f, nil
+}
+
+func (f *File) Name() string {
+	return TypeFile
+}
+
+// recover renomeia segmentos temporários de uma execução anterior e os registra no manifest
+func (f *File) recover() error {
+	temps, err := filepath.Glob(filepath.Join(f.dir, fileTempPrefix+f.prefix+"-*"+fileTempSuffix))
+	if err != nil {
+		return err
+	}
+	for _, temp := range temps {
+		stat, err := os.Stat(temp)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(temp), fileTempPrefix), fileTempSuffix)
+		compression := f.compressionOf(name)
+		events, size, err := scanSegment(temp, compression)
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(temp, filepath.Join(f.dir, name)); err != nil {
+			return err
+		}
+		info := SegmentInfo{
+			Segment:     name,
+			Compression: compression,
+			Events:      events,
+			Bytes:       size,
+			CreatedAt:   stat.ModTime().UTC(),
+			ClosedAt:    time.Now().UTC(),
+			Recovered:   true,
+		}
+		if err := f.appendManifest(info); err != nil {
+			return err
+		}
+		log.Warn().Str("segment", name).Msg("Unfinished file sink segment recovered")
+	}
+	return syncDir(f.dir)
+}
+
+// scanSegment conta as linhas completas e os bytes de JSON não comprimido de um
+// segmento. Um segmento interrompido termina em linha parcial ou, se
+// comprimido, sem o rodapé: a leitura para no primeiro erro.
+func scanSegment(path, compression string) (int, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	switch compression {
+	case FileCompressionGzip:
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			// parada antes do cabeçalho: segmento vazio
+			return 0, 0, nil
+		}
+		defer gz.Close()
+		reader = gz
+	case FileCompressionZstd:
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer decoder.Close()
+		reader = decoder
+	}
+
+	var (
+		events int
+		size   int64
+	)
+	buffered := bufio.NewReaderSize(reader, 64<<10)
+	for {
+		line, err := buffered.ReadBytes('\n')
+		if err != nil {
+			return events, size, nil
+		}
+		events++
+		size += int64(len(line))
+	}
+}
+
+func (f *File) compressionOf(name string) string {
+	switch {
+	case strings.HasSuffix(name, ".gz"):
+		return FileCompressionGzip
+	case strings.HasSuffix(name, ".zst"):
+		return FileCompressionZstd
+	}
+	return FileCompressionNone
+}
+
+// open cria um novo segmento temporário
+func (f *File) open() error {
+	now := time.Now().UTC()
+	f.sequence++
+	name := fmt.Sprintf("%s-%s-%06d.jsonl", f.prefix, now.Format("20060102T150405.000Z"), f.sequence)
+	switch f.compression {
+	case FileCompressionGzip:
+		name += ".gz"
+	case FileCompressionZstd:
+		name += ".zst"
+	}
+
+	file, err := os.OpenFile(filepath.Join(f.dir, fileTempPrefix+name+fileTempSuffix), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	var writer segmentWriter
+	switch f.compression {
+	case FileCompressionGzip:
+		writer = gzip.NewWriter(file)
+	case FileCompressionZstd:
+		encoder, err := zstd.NewWriter(file)
+		if err != nil {
+			file.Close()
+			return err
+		}
+		writer = encoder
+	default:
+		writer = bufferedSegment{bufio.NewWriterSize(file, 64<<10)}
+	}
+
+	f.current = &segment{
+		file:   file,
+		writer: writer,
+		info:   SegmentInfo{Segment: name, Compression: f.compression, CreatedAt: now},
+	}
+	return nil
+}
+
+// closeSegment finaliza o formato, faz fsync, renomeia e registra o segmento no manifest
+func (f *File) closeSegment() error {
+	seg := f.current
+	if seg == nil {
+		return nil
+	}
+	f.current = nil
+
+	temp := seg.file.Name()
+	if err := seg.writer.Close(); err != nil {
+		seg.file.Close()
+		return err
+	}
+	if err := seg.file.Sync(); err != nil {
+		seg.file.Close()
+		return err
+	}
+	if err := seg.file.Close(); err != nil {
+		return err
+	}
+	if seg.info.Events == 0 {
+		return os.Remove(temp)
+	}
+	if err := os.Rename(temp, filepath.Join(f.dir, seg.info.Segment)); err != nil {
+		return err
+	}
+	if err := syncDir(f.dir); err != nil {
+		return err
+	}
+
+	seg.info.ClosedAt = time.Now().UTC()
+	if err := f.appendManifest(seg.info); err != nil {
+		return err
+	}
+	log.Info().
+		Str("segment", seg.info.Segment).
+		Int("events", seg.info.Events).
+		Str("first_position", seg.info.FirstPosition.String()).
+		Str("last_position", seg.info.LastPosition.String()).
+		Msg("File sink segment closed")
+	return nil
+}
+
+// appendManifest acrescenta a entrada do segmento ao manifest com fsync
+func (f *File) appendManifest(info SegmentInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	manifest, err := os.OpenFile(filepath.Join(f.dir, f.prefix+"-manifest.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := manifest.Write(append(data, '\n')); err != nil {
+		manifest.Close()
+		return err
+	}
+	if err := manifest.Sync(); err != nil {
+		manifest.Close()
+		return err
+	}
+	return manifest.Close()
+}
+
+// syncDir faz fsync do diretório para persistir renomeações e criações
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// rotateDue indica se o segmento aberto atingiu o tamanho ou a idade máxima
+func (f *File) rotateDue() bool {
+	return f.current != nil && (f.current.info.Bytes >= f.maxBytes || time.Since(f.current.info.CreatedAt) >= f.maxAge)
+}
+
+// rotateLoop fecha segmentos antigos mesmo sem novos eventos
+func (f *File) rotateLoop() {
+	defer close(f.done)
+	ticker := time.NewTicker(fileRotationCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			f.mu.Lock()
+			if f.rotateDue() {
+				if err := f.closeSegment(); err != nil {
+					log.Error().Err(err).Msg("Failed to rotate file sink segment")
+				}
+			}
+			f.mu.Unlock()
+		}
+	}
+}
+
+func (f *File) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := range events {
+		evt := &events[i]
+		data, err := json.Marshal(evt)
+		if err != nil {
+			return static.NewErrorUtil("Failed to encode event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+
+		if f.rotateDue() {
+			if err := f.closeSegment(); err != nil {
+				log.Error().Err(err).Msg("Failed to close file sink segment")
+				return static.NewErrorUtil("Failed to close file sink segment", "SINK_WRITE_FAILED", err, err.Error())
+			}
+		}
+		if f.current == nil {
+			if err := f.open(); err != nil {
+				log.Error().Err(err).Str("dir", f.dir).Msg("Failed to open file sink segment")
+				return static.NewErrorUtil("Failed to open file sink segment", "SINK_WRITE_FAILED", err, err.Error())
+			}
+		}
+
+		seg := f.current
+		if _, err := seg.writer.Write(append(data, '\n')); err != nil {
+			return static.NewErrorUtil("Failed to write event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+		seg.info.Events++
+		seg.info.Bytes += int64(len(data) + 1)
+		// snapshots e histórico temporal não têm posição própria no log
+		if !evt.Position.IsZero() {
+			if seg.info.FirstPosition.IsZero() {
+				seg.info.FirstPosition = evt.Position
+			}
+			if evt.Position.Compare(seg.info.LastPosition) > 0 {
+				seg.info.LastPosition = evt.Position
+			}
+		}
+	}
+	return nil
+}
+
+// Flush descarrega o compressor e faz fsync do segmento aberto
+func (f *File) Flush(ctx context.Context) static.ErrorUtil {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.current == nil {
+		return nil
+	}
+	if err := f.current.writer.Flush(); err != nil {
+		return static.NewErrorUtil("Failed to flush file sink segment", "SINK_FLUSH_FAILED", err, err.Error())
+	}
+	if err := f.current.file.Sync(); err != nil {
+		return static.NewErrorUtil("Failed to sync file sink segment", "SINK_FLUSH_FAILED", err, err.Error())
+	}
+	return nil
+}
+
+func (f *File) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+func (f *File) HealthCheck(ctx context.Context) static.ErrorUtil {
+	if _, err := os.Stat(f.dir); err != nil {
+		return static.NewErrorUtil("File sink directory unavailable", "SINK_UNHEALTHY", err, err.Error())
+	}
+	return nil
+}
+
+func (f *File) Close() static.ErrorUtil {
+	select {
+	case <-f.stop:
+	default:
+		close(f.stop)
+	}
+	<-f.done
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.closeSegment(); err != nil {
+		log.Error().Err(err).Msg("Failed to close file sink segment")
+		return static.NewErrorUtil("Failed to close file sink segment", "SINK_CLOSE_FAILED", err, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+package sink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+func newFileTest(t *testing.T, cfg *config.Config) *File {
+	t.Helper()
+	if cfg.FilePrefix == "" {
+		cfg.FilePrefix = "cdc"
+	}
+	f, errFile := NewFile(cfg)
+	if errFile != nil {
+		t.Fatalf("NewFile: %v", errFile)
+	}
+	return f
+}
+
+// readManifest entradas do manifest na ordem de fechamento
+func readManifest(t *testing.T, dir string) []SegmentInfo {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "cdc-manifest.jsonl"))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var segments []SegmentInfo
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var info SegmentInfo
+		if err := json.Unmarshal(scanner.Bytes(), &info); err != nil {
+			t.Fatalf("manifest line %q: %v", scanner.Text(), err)
+		}
+		segments = append(segments, info)
+	}
+	return segments
+}
+
+// readSegment eventos de um segmento fechado, descomprimidos conforme o manifest
+func readSegment(t *testing.T, dir string, info SegmentInfo) []event.Event {
+	t.Helper()
+	file, err := os.Open(filepath.Join(dir, info.Segment))
+	if err != nil {
+		t.Fatalf("open segment: %v", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	switch info.Compression {
+	case FileCompressionGzip:
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("gzip reader: %v", err)
+		}
+		reader = gz
+	case FileCompressionZstd:
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			t.Fatalf("zstd reader: %v", err)
+		}
+		defer decoder.Close()
+		reader = decoder
+	}
+
+	var events []event.Event
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var evt event.Event
+		if err := json.Unmarshal(scanner.Bytes(), &evt); err != nil {
+			t.Fatalf("segment line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, evt)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read segment %s: %v", info.Segment, err)
+	}
+	return events
+}
+
+// jsonSize bytes de JSON Lines dos eventos
+func jsonSize(t *testing.T, events []event.Event) int64 {
+	t.Helper()
+	var size int64
+	for i := range events {
+		data, err := json.Marshal(&events[i])
+		if err != nil {
+			t.Fatalf("marshal: %v", err)
+		}
+		size += int64(len(data) + 1)
+	}
+	return size
+}
+
+// tempSegments segmentos ainda em escrita no diretório
+func tempSegments(t *testing.T, dir string) []string {
+	t.Helper()
+	temps, err := filepath.Glob(filepath.Join(dir, fileTempPrefix+"cdc-*"+fileTempSuffix))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	return temps
+}
+
+func TestFileRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	f := newFileTest(t, &config.Config{FileDir: dir, FileMaxBytes: 1})
+	ctx := context.Background()
+
+	events := []event.Event{
+		testOrder(event.OpInsert, testPosition(10, 1), nil, testRow(1, "new")),
+		testOrder(event.OpUpdate, testPosition(11, 1), testRow(1, "new"), testRow(1, "paid")),
+		testOrder(event.OpDelete, testPosition(12, 1), testRow(1, "paid"), nil),
+	}
+	if errWrite := f.Write(ctx, events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if errClose := f.Close(); errClose != nil {
+		t.Fatalf("Close: %v", errClose)
+	}
+
+	// o limite é verificado antes de cada evento: um evento por segmento
+	segments := readManifest(t, dir)
+	if len(segments) != 3 {
+		t.Fatalf("manifest = %+v, want 3 segments", segments)
+	}
+	for i, info := range segments {
+		if info.Events != 1 || info.FirstPosition != events[i].Position || info.LastPosition != events[i].Position {
+			t.Errorf("segment %d = %+v", i, info)
+		}
+		if info.Bytes != jsonSize(t, events[i:i+1]) {
+			t.Errorf("segment %d bytes = %d", i, info.Bytes)
+		}
+		if got := readSegment(t, dir, info); len(got) != 1 || got[0].Position != events[i].Position {
+			t.Errorf("segment %d events = %+v", i, got)
+		}
+	}
+	if temps := tempSegments(t, dir); len(temps) != 0 {
+		t.Errorf("temporary segments left: %v", temps)
+	}
+}
+
+func TestFileRotatesByAge(t *testing.T) {
+	dir := t.TempDir()
+	f := newFileTest(t, &config.Config{FileDir: dir, FileMaxAgeSeconds: 60})
+	defer f.Close()
+	ctx := context.Background()
+
+	first := testOrder(event.OpInsert, testPosition(10, 1), nil, testRow(1, "new"))
+	if errWrite := f.Write(ctx, []event.Event{first}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	f.mu.Lock()
+	f.current.info.CreatedAt = time.Now().Add(-time.Minute)
+	f.mu.Unlock()
+
+	// sem novos eventos o segmento vencido é fechado pela verificação periódica
+	deadline := time.Now().Add(5 * fileRotationCheckInterval)
+	for len(tempSegments(t, dir)) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("segment not rotated by age")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	second := testOrder(event.OpUpdate, testPosition(11, 1), testRow(1, "new"), testRow(1, "paid"))
+	if errWrite := f.Write(ctx, []event.Event{second}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if errClose := f.Close(); errClose != nil {
+		t.Fatalf("Close: %v", errClose)
+	}
+
+	segments := readManifest(t, dir)
+	if len(segments) != 2 || segments[0].LastPosition != first.Position || segments[1].FirstPosition != second.Position {
+		t.Errorf("manifest = %+v", segments)
+	}
+}
+
+// A faixa de posições ignora eventos sem posição (snapshot) e usa a maior posição do segmento
+func TestFileManifestPositionRange(t *testing.T) {
+	dir := t.TempDir()
+	f := newFileTest(t, &config.Config{FileDir: dir})
+	ctx := context.Background()
+
+	events := []event.Event{
+		testOrder(event.OpRead, event.Position{}, nil, testRow(1, "new")),
+		testOrder(event.OpInsert, testPosition(20, 2), nil, testRow(2, "new")),
+		testOrder(event.OpUpdate, testPosition(21, 1), testRow(2, "new"), testRow(2, "paid")),
+		testOrder(event.OpRead, event.Position{}, nil, testRow(3, "new")),
+		testOrder(event.OpDelete, testPosition(20, 5), testRow(1, "new"), nil),
+	}
+	if errWrite := f.Write(ctx, events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if errClose := f.Close(); errClose != nil {
+		t.Fatalf("Close: %v", errClose)
+	}
+
+	segments := readManifest(t, dir)
+	if len(segments) != 1 {
+		t.Fatalf("manifest = %+v", segments)
+	}
+	info := segments[0]
+	if info.FirstPosition != testPosition(20, 2) || info.LastPosition != testPosition(21, 1) {
+		t.Errorf("position range = %v..%v", info.FirstPosition, info.LastPosition)
+	}
+	if info.Events != len(events) || info.Bytes != jsonSize(t, events) || info.Recovered {
+		t.Errorf("segment = %+v", info)
+	}
+}
+
+func TestFileCompression(t *testing.T) {
+	for _, compression := range []string{FileCompressionNone, FileCompressionGzip, FileCompressionZstd} {
+		t.Run(compression, func(t *testing.T) {
+			dir := t.TempDir()
+			f := newFileTest(t, &config.Config{FileDir: dir, FileCompression: compression})
+			ctx := context.Background()
+
+			events := []event.Event{
+				testOrder(event.OpInsert, testPosition(30, 1), nil, testRow(1, "new")),
+				testOrder(event.OpUpdate, testPosition(31, 1), testRow(1, "new"), testRow(1, "paid")),
+			}
+			if errWrite := f.Write(ctx, events); errWrite != nil {
+				t.Fatalf("Write: %v", errWrite)
+			}
+			if errClose := f.Close(); errClose != nil {
+				t.Fatalf("Close: %v", errClose)
+			}
+
+			segments := readManifest(t, dir)
+			if len(segments) != 1 || segments[0].Compression != compression {
+				t.Fatalf("manifest = %+v", segments)
+			}
+			// Bytes conta o JSON antes da compressão
+			if segments[0].Bytes != jsonSize(t, events) {
+				t.Errorf("bytes = %d, want %d", segments[0].Bytes, jsonSize(t, events))
+			}
+			got := readSegment(t, dir, segments[0])
+			if len(got) != 2 || got[0].Position != events[0].Position || got[1].After["Status"] != "paid" {
+				t.Errorf("events = %+v", got)
+			}
+		})
+	}
+}
+
+// Parada abrupta: o segmento temporário é renomeado e registrado na próxima inicialização
+func TestFileRecoversTempSegment(t *testing.T) {
+	for _, compression := range []string{FileCompressionNone, FileCompressionGzip, FileCompressionZstd} {
+		t.Run(compression, func(t *testing.T) {
+			dir := t.TempDir()
+			f := newFileTest(t, &config.Config{FileDir: dir, FileCompression: compression})
+			ctx := context.Background()
+
+			events := []event.Event{
+				testOrder(event.OpInsert, testPosition(40, 1), nil, testRow(1, "new")),
+				testOrder(event.OpUpdate, testPosition(41, 1), testRow(1, "new"), testRow(1, "paid")),
+			}
+			if errWrite := f.Write(ctx, events); errWrite != nil {
+				t.Fatalf("Write: %v", errWrite)
+			}
+			if errFlush := f.Flush(ctx); errFlush != nil {
+				t.Fatalf("Flush: %v", errFlush)
+			}
+			// interrompe sem fechar o segmento
+			close(f.stop)
+			<-f.done
+			f.current.file.Close()
+
+			temps := tempSegments(t, dir)
+			if len(temps) != 1 {
+				t.Fatalf("temporary segments = %v", temps)
+			}
+
+			recovered := newFileTest(t, &config.Config{FileDir: dir, FileCompression: compression})
+			if errClose := recovered.Close(); errClose != nil {
+				t.Fatalf("Close: %v", errClose)
+			}
+
+			if temps := tempSegments(t, dir); len(temps) != 0 {
+				t.Errorf("temporary segments left: %v", temps)
+			}
+			segments := readManifest(t, dir)
+			if len(segments) != 1 {
+				t.Fatalf("manifest = %+v", segments)
+			}
+			info := segments[0]
+			name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(temps[0]), fileTempPrefix), fileTempSuffix)
+			if !info.Recovered || info.Compression != compression || info.Segment != name {
+				t.Errorf("recovered segment = %+v", info)
+			}
+			if info.Events != len(events) || info.Bytes != jsonSize(t, events) {
+				t.Errorf("recovered events = %d, bytes = %d, want %d, %d", info.Events, info.Bytes, len(events), jsonSize(t, events))
+			}
+			if !info.FirstPosition.IsZero() || !info.LastPosition.IsZero() {
+				t.Errorf("recovered position range = %v..%v", info.FirstPosition, info.LastPosition)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+// internal/sink/grpc.go
+package sink
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cdc/api/cdcv1"
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcStopTimeout espera máxima pelo GracefulStop antes de derrubar as conexões
+const grpcStopTimeout = 5 * time.Second
+
+// GRPC expõe o serviço ChangeStream (api/cdcv1) para consumidores que assinam
+// os eventos diretamente. Write guarda os eventos em um buffer de retenção em
+// memória (os APP_GO_CDC_GRPC_BUFFER_SIZE mais recentes) e acorda as
+// assinaturas; cada consumidor recebe os eventos posteriores à posição pedida
+// e confirma o que processou com Ack. Como o buffer não é ordenado por posição
+// (eventos temporais não têm posição e os de snapshot compartilham o LSN), as
+// posições são localizadas por busca linear e o ack guarda a sequência interna
+// do evento. A mesma posição se repete entre tabelas (snapshot e truncate no
+// LSN do ciclo, histórico temporal), então a busca considera também a tabela
+// enviada em Position.table. Ao reconectar sem from_position o consumidor retoma do último
+// ack, desde que os eventos ainda estejam no buffer; caso contrário Subscribe
+// falha com OUT_OF_RANGE. O buffer não segura a confirmação na origem: eventos
+// descartados antes de um consumidor lê-los não são reenviados.
+type GRPC struct {
+	server   *grpc.Server
+	listener net.Listener
+	capacity int
+
+	mu       sync.Mutex
+	buffer   []grpcEntry
+	base     uint64            // sequência de buffer[0]
+	evicted  bool              // algum evento já saiu do buffer
+	notify   chan struct{}     // fechado quando chegam eventos novos
+	acks     map[string]uint64 // consumidor -> sequência do próximo evento a enviar
+	closed   bool
+	serveErr error
+}
+
+// grpcChangeStream implementação do serviço (o método Ack do Sink tem outra assinatura)
+type grpcChangeStream struct {
+	cdcv1.UnimplementedChangeStreamServer
+	g *GRPC
+}
+
+func (s grpcChangeStream) Subscribe(req *cdcv1.SubscribeRequest, stream cdcv1.ChangeStream_SubscribeServer) error {
+	return s.g.subscribe(req, stream)
+}
+
+func (s grpcChangeStream) Ack(ctx context.Context, req *cdcv1.AckRequest) (*cdcv1.AckResponse, error) {
+	return s.g.ack(req)
+}
+
+// grpcEntry evento retido, já convertido para a mensagem enviada
+type grpcEntry struct {
+	table    string // schema.tabela em minúsculas
+	position event.Position
+	msg      *cdcv1.ChangeEvent
+}
+
+// NewGRPC cria o sink e inicia o servidor gRPC (construtor)
+func NewGRPC(cfg *config.Config) (*GRPC, static.ErrorUtil) {
+	var opts []grpc.ServerOption
+	if cfg.GRPCTLSCertFile != "" || cfg.GRPCTLSKeyFile != "" {
+		tlsConfig, err := buildTLSConfig(cfg.GRPCTLSClientCAFile, cfg.GRPCTLSCertFile, cfg.GRPCTLSKeyFile, false)
+		if err != nil {
+			log.Error().Caller().Err(err).Msg("Failed to load gRPC TLS configuration")
+			return nil, static.NewErrorUtil("Failed to load gRPC TLS configuration", "GRPC_CONFIG_INVALID", err, err.Error())
+		}
+		if tlsConfig.RootCAs != nil {
+			// a CA informada valida os certificados dos clientes
+			tlsConfig.ClientCAs, tlsConfig.RootCAs = tlsConfig.RootCAs, nil
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else if cfg.GRPCTLSClientCAFile != "" {
+		return nil, static.NewErrorUtil("gRPC client CA requires a server certificate", "GRPC_CONFIG_INVALID", nil, "APP_GO_CDC_GRPC_TLS_CERT_FILE")
+	}
+
+	addr := cfg.GRPCListenAddr
+	if addr == "" {
+		addr = static.APP_GO_CDC_GRPC_LISTEN_ADDR
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Error().Caller().Err(err).Str("addr", addr).Msg("Failed to listen for gRPC")
+		return nil, static.NewErrorUtil("Failed to listen for gRPC", "GRPC_CONFIG_INVALID", err, err.Error())
+	}
+
+	return newGRPC(listener, cfg.GRPCBufferSize, opts...), nil
+}
+
+// newGRPC registra o serviço e inicia o servidor no listener informado
+func newGRPC(listener net.Listener, capacity int, opts ...grpc.ServerOption) *GRPC {
+	g := &GRPC{
+		server:   grpc.NewServer(opts...),
+		listener: listener,
+		capacity: capacity,
+		notify:   make(chan struct{}),
+		acks:     make(map[string]uint64),
+	}
+	if g.capacity <= 0 {
+		g.capacity = static.APP_GO_CDC_GRPC_BUFFER_SIZE
+	}
+	cdcv1.RegisterChangeStreamServer(g.server, grpcChangeStream{g: g})
+
+	go func() {
+		if errServe := g.server.Serve(listener); errServe != nil && !errors.Is(errServe, grpc.ErrServerStopped) {
+			log.Error().Err(errServe).Msg("gRPC server stopped")
+			g.mu.Lock()
+			g.serveErr = errServe
+			g.mu.Unlock()
+		}
+	}()
+	log.Info().Str("addr", listener.Addr().String()).Int("buffer_size", g.capacity).Msg("gRPC ChangeStream server listening")
+	return g
+}
+
+func (g *GRPC) Name() string {
+	return TypeGRPC
+}
+
+// Addr endereço em que o servidor está escutando
+func (g *GRPC) Addr() net.Addr {
+	return g.listener.Addr()
+}
+
+func (g *GRPC) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	entries := make([]grpcEntry, 0, len(events))
+	for i := range events {
+		evt := &events[i]
+		payload, err := json.Marshal(evt)
+		if err != nil {
+			return static.NewErrorUtil("Failed to encode event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+		entries = append(entries, grpcEntry{
+			table:    strings.ToLower(evt.FullTableName()),
+			position: evt.Position,
+			msg: &cdcv1.ChangeEvent{
+				Op:         string(evt.Op),
+				Database:   evt.Database,
+				Schema:     evt.Schema,
+				Table:      evt.Table,
+				Position:   &cdcv1.Position{Lsn: evt.Position.LSN, Seqval: evt.Position.SeqVal, Table: evt.FullTableName()},
+				CommitTime: timestamppb.New(evt.CommitTime),
+				Key:        evt.MessageKey(),
+				Payload:    payload,
+			},
+		})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return static.NewErrorUtil("gRPC server closed", "SINK_WRITE_FAILED", nil, TypeGRPC)
+	}
+	g.buffer = append(g.buffer, entries...)
+	if excess := len(g.buffer) - g.capacity; excess > 0 {
+		g.evicted = true
+		g.buffer = append(g.buffer[:0:0], g.buffer[excess:]...)
+		g.base += uint64(excess)
+	}
+	close(g.notify)
+	g.notify = make(chan struct{})
+	return nil
+}
+
+// resume sequência a partir da qual reenviar após pos: o evento seguinte ao
+// que tem a posição ou, para posições compartilhadas (snapshot e truncate, sem
+// seqval), o primeiro com ela, para nenhum ser perdido. Só eventos de table são
+// considerados; sem table (clientes que não devolvem Position.table), os das
+// tabelas assinadas, e a posição precisa identificar uma única tabela. false
+// se a posição não está no buffer.
+func (g *GRPC) resume(pos event.Position, table string, tables map[string]bool) (uint64, bool, error) {
+	match := -1
+	for i := range g.buffer {
+		entry := &g.buffer[i]
+		if entry.position.Compare(pos) != 0 {
+			continue
+		}
+		if (table != "" && entry.table != table) || (table == "" && len(tables) > 0 && !tables[entry.table]) {
+			continue
+		}
+		if match < 0 {
+			match = i
+			continue
+		}
+		if g.buffer[match].table != entry.table {
+			return 0, false, status.Errorf(codes.InvalidArgument, "position %s is shared by %s and %s, set position.table", pos.String(), g.buffer[match].table, entry.table)
+		}
+	}
+	switch {
+	case match < 0:
+		return 0, false, nil
+	case pos.SeqVal == "":
+		return g.base + uint64(match), true, nil
+	}
+	return g.base + uint64(match) + 1, true, nil
+}
+
+// subscribe envia os eventos retidos após a posição inicial e depois os novos,
+// até o cliente cancelar ou o servidor fechar
+func (g *GRPC) subscribe(req *cdcv1.SubscribeRequest, stream cdcv1.ChangeStream_SubscribeServer) error {
+	ctx := stream.Context()
+	tables := make(map[string]bool, len(req.GetTables()))
+	for _, table := range req.GetTables() {
+		tables[strings.ToLower(strings.TrimSpace(table))] = true
+	}
+
+	g.mu.Lock()
+	from := event.Position{LSN: req.GetFromPosition().GetLsn(), SeqVal: req.GetFromPosition().GetSeqval()}
+	cursor := g.base + uint64(len(g.buffer))
+	if acked, ok := g.acks[req.GetConsumerId()]; from.IsZero() && ok {
+		cursor = acked
+	} else if !from.IsZero() {
+		seq, found, err := g.resume(from, strings.ToLower(req.GetFromPosition().GetTable()), tables)
+		switch {
+		case err != nil:
+			g.mu.Unlock()
+			return err
+		case found:
+			cursor = seq
+		case g.evicted:
+			g.mu.Unlock()
+			return status.Errorf(codes.OutOfRange, "position %s is no longer retained", from.String())
+		default:
+			// nada foi descartado: a posição é anterior a todo o buffer
+			cursor = g.base
+		}
+	}
+	if cursor < g.base {
+		g.mu.Unlock()
+		return status.Errorf(codes.OutOfRange, "acked events of consumer %s are no longer retained", req.GetConsumerId())
+	}
+	g.mu.Unlock()
+
+	log.Info().Str("consumer", req.GetConsumerId()).Str("from", from.String()).Strs("tables", req.GetTables()).Msg("gRPC consumer subscribed")
+	defer log.Info().Str("consumer", req.GetConsumerId()).Msg("gRPC consumer unsubscribed")
+
+	for {
+		g.mu.Lock()
+		if g.closed {
+			g.mu.Unlock()
+			return status.Error(codes.Unavailable, "server is shutting down")
+		}
+		if cursor < g.base {
+			g.mu.Unlock()
+			return status.Error(codes.OutOfRange, "consumer fell behind the retention buffer")
+		}
+		pending := append([]grpcEntry(nil), g.buffer[cursor-g.base:]...)
+		notify := g.notify
+		g.mu.Unlock()
+
+		for _, entry := range pending {
+			cursor++
+			if len(tables) > 0 && !tables[entry.table] {
+				continue
+			}
+			if err := stream.Send(entry.msg); err != nil {
+				return err
+			}
+		}
+		if len(pending) > 0 {
+			continue
+		}
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ack registra a sequência do evento processado pelo consumidor (nunca retrocede)
+func (g *GRPC) ack(req *cdcv1.AckRequest) (*cdcv1.AckResponse, error) {
+	if req.GetConsumerId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "consumer_id is required")
+	}
+	position := event.Position{LSN: req.GetPosition().GetLsn(), SeqVal: req.GetPosition().GetSeqval()}
+	if position.IsZero() {
+		return nil, status.Error(codes.InvalidArgument, "position is required")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	seq, found, err := g.resume(position, strings.ToLower(req.GetPosition().GetTable()), nil)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if g.evicted {
+			return nil, status.Errorf(codes.OutOfRange, "position %s is no longer retained", position.String())
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "position %s was not delivered", position.String())
+	}
+	if current, ok := g.acks[req.GetConsumerId()]; !ok || seq > current {
+		g.acks[req.GetConsumerId()] = seq
+	}
+	return &cdcv1.AckResponse{}, nil
+}
+
+func (g *GRPC) Flush(ctx context.Context) static.ErrorUtil {
+	return nil
+}
+
+// Ack do pipeline (posições confirmadas na origem); os consumidores usam o RPC Ack
+func (g *GRPC) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+func (g *GRPC) HealthCheck(ctx context.Context) static.ErrorUtil {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.serveErr != nil {
+		return static.NewErrorUtil("gRPC server stopped", "SINK_UNHEALTHY", g.serveErr, g.serveErr.Error())
+	}
+	return nil
+}
+
+// Close encerra as assinaturas e para o servidor (GracefulStop limitado a grpcStopTimeout)
+func (g *GRPC) Close() static.ErrorUtil {
+	g.mu.Lock()
+	if !g.closed {
+		g.closed = true
+		close(g.notify)
+	}
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		g.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(grpcStopTimeout):
+		g.server.Stop()
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"go-cdc/api/cdcv1"
+	"go-cdc/internal/event"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newGRPCTest(t *testing.T, capacity int) (*GRPC, cdcv1.ChangeStreamClient) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	g := newGRPC(listener, capacity)
+	t.Cleanup(func() { g.Close() })
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return g, cdcv1.NewChangeStreamClient(conn)
+}
+
+// grpcReceiveEvents lê n eventos da assinatura
+func grpcReceiveEvents(t *testing.T, client cdcv1.ChangeStreamClient, req *cdcv1.SubscribeRequest, n int) []*cdcv1.ChangeEvent {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Subscribe(ctx, req)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	var msgs []*cdcv1.ChangeEvent
+	for range n {
+		msg, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv after %d events: %v", len(msgs), err)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// grpcReceive lê n eventos da assinatura e retorna os LSNs recebidos
+func grpcReceive(t *testing.T, client cdcv1.ChangeStreamClient, req *cdcv1.SubscribeRequest, n int) []string {
+	t.Helper()
+	var lsns []string
+	for _, msg := range grpcReceiveEvents(t, client, req, n) {
+		lsns = append(lsns, msg.GetPosition().GetLsn())
+	}
+	return lsns
+}
+
+// grpcSubscribeError código de erro da assinatura (retornado no primeiro Recv)
+func grpcSubscribeError(t *testing.T, client cdcv1.ChangeStreamClient, req *cdcv1.SubscribeRequest) codes.Code {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Subscribe(ctx, req)
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	return status.Code(err)
+}
+
+func TestGRPCSubscribeResume(t *testing.T) {
+	g, client := newGRPCTest(t, 8)
+	ctx := context.Background()
+
+	// buffer fora de ordem de posição: histórico temporal sem posição e snapshot
+	// (LSN sem seqval) depois de eventos do log
+	events := []event.Event{
+		testOrder(event.OpInsert, testPosition(10, 1), nil, testRow(1, "new")),
+		testEvent(event.OpInsert, "Customers", testPosition(10, 2), nil, testRow(1, "new")),
+		testOrder(event.OpInsert, event.Position{}, nil, testRow(1, "new")),
+		testOrder(event.OpRead, event.Position{LSN: testLSN(5)}, nil, testRow(1, "new")),
+		testEvent(event.OpRead, "Customers", event.Position{LSN: testLSN(5)}, nil, testRow(1, "new")),
+		testOrder(event.OpInsert, testPosition(11, 1), nil, testRow(1, "new")),
+	}
+	if errWrite := g.Write(ctx, events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	orders := []string{"DBO.Orders"}
+	got := grpcReceive(t, client, &cdcv1.SubscribeRequest{
+		ConsumerId:   "c1",
+		Tables:       orders,
+		FromPosition: &cdcv1.Position{Lsn: testLSN(10), Seqval: testLSN(1)},
+	}, 3)
+	if want := []string{"", testLSN(5), testLSN(11)}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("from position received %v, want %v", got, want)
+	}
+
+	// ack do snapshot: a retomada reenvia a partir do primeiro evento com a posição
+	if _, err := client.Ack(ctx, &cdcv1.AckRequest{ConsumerId: "c1", Position: &cdcv1.Position{Lsn: testLSN(5), Table: "dbo.Orders"}}); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	got = grpcReceive(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c1", Tables: orders}, 2)
+	if want := []string{testLSN(5), testLSN(11)}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("resume from ack received %v, want %v", got, want)
+	}
+
+	// ack de um evento do log: a retomada começa no seguinte (ainda não escrito)
+	if _, err := client.Ack(ctx, &cdcv1.AckRequest{ConsumerId: "c1", Position: &cdcv1.Position{Lsn: testLSN(11), Seqval: testLSN(1)}}); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	if errWrite := g.Write(ctx, []event.Event{testOrder(event.OpInsert, testPosition(12, 1), nil, testRow(1, "new"))}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	got = grpcReceive(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c1", Tables: orders}, 1)
+	if want := []string{testLSN(12)}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("resume after log ack received %v, want %v", got, want)
+	}
+
+	// ack de posição nunca entregue
+	_, err := client.Ack(ctx, &cdcv1.AckRequest{ConsumerId: "c1", Position: &cdcv1.Position{Lsn: testLSN(99), Seqval: testLSN(1)}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Ack of unknown position = %v", err)
+	}
+}
+
+// Snapshot e histórico temporal repetem a posição em várias tabelas: o ack e
+// o from_position de uma tabela não podem resolver para o evento de outra
+func TestGRPCSharedPositionAcrossTables(t *testing.T) {
+	g, client := newGRPCTest(t, 8)
+	ctx := context.Background()
+
+	temporal := event.Position{LSN: testLSN(0), SeqVal: testLSN(1)}
+	snapshot := event.Position{LSN: testLSN(5)}
+	events := []event.Event{
+		testOrder(event.OpInsert, temporal, nil, testRow(1, "new")),
+		testEvent(event.OpInsert, "Customers", temporal, nil, testRow(1, "new")),
+		testOrder(event.OpRead, snapshot, nil, testRow(1, "new")),
+		testEvent(event.OpRead, "Customers", snapshot, nil, testRow(1, "new")),
+		testEvent(event.OpRead, "Customers", snapshot, nil, testRow(2, "new")),
+	}
+	if errWrite := g.Write(ctx, events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	// a posição recebida identifica a tabela
+	msgs := grpcReceiveEvents(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c1", FromPosition: &cdcv1.Position{Lsn: testLSN(0), Seqval: testLSN(0)}}, 5)
+	if got := msgs[1].GetPosition(); got.GetTable() != "dbo.Customers" || got.GetSeqval() != temporal.SeqVal {
+		t.Fatalf("position = %v", got)
+	}
+
+	// ack do histórico temporal de Customers: retoma depois dele, não do de Orders
+	if _, err := client.Ack(ctx, &cdcv1.AckRequest{ConsumerId: "c1", Position: msgs[1].GetPosition()}); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	customers := []string{"dbo.Customers"}
+	got := grpcReceiveEvents(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c1", Tables: customers}, 1)
+	if got[0].GetTable() != "Customers" || got[0].GetPosition().GetLsn() != snapshot.LSN {
+		t.Errorf("resume after temporal ack received %v", got[0])
+	}
+
+	// ack do snapshot de Customers: reenvia todo o snapshot de Customers
+	if _, err := client.Ack(ctx, &cdcv1.AckRequest{ConsumerId: "c1", Position: msgs[4].GetPosition()}); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	got = grpcReceiveEvents(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c1"}, 2)
+	if got[0].GetTable() != "Customers" || got[1].GetTable() != "Customers" {
+		t.Errorf("resume after snapshot ack received %v", got)
+	}
+
+	// sem a tabela a posição é ambígua, a menos que a assinatura restrinja as tabelas
+	_, err := client.Ack(ctx, &cdcv1.AckRequest{ConsumerId: "c2", Position: &cdcv1.Position{Lsn: snapshot.LSN}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Ack of shared position without table = %v", err)
+	}
+	from := &cdcv1.Position{Lsn: temporal.LSN, Seqval: temporal.SeqVal}
+	if code := grpcSubscribeError(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c2", FromPosition: from}); code != codes.InvalidArgument {
+		t.Errorf("subscribe from shared position without table = %v, want InvalidArgument", code)
+	}
+	got = grpcReceiveEvents(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c2", Tables: customers, FromPosition: from}, 2)
+	if got[0].GetTable() != "Customers" || got[0].GetPosition().GetLsn() != snapshot.LSN {
+		t.Errorf("subscribe filtered by table received %v", got)
+	}
+}
+
+func TestGRPCOutOfRangeAfterEviction(t *testing.T) {
+	g, client := newGRPCTest(t, 4)
+	ctx := context.Background()
+
+	if errWrite := g.Write(ctx, []event.Event{testOrder(event.OpInsert, testPosition(1, 1), nil, testRow(1, "new"))}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if _, err := client.Ack(ctx, &cdcv1.AckRequest{ConsumerId: "c1", Position: &cdcv1.Position{Lsn: testLSN(1), Seqval: testLSN(1)}}); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	// o evento seguinte ao ack sai do buffer
+	var events []event.Event
+	for n := 2; n <= 7; n++ {
+		events = append(events, testOrder(event.OpInsert, testPosition(n, 1), nil, testRow(1, "new")))
+	}
+	if errWrite := g.Write(ctx, events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	if code := grpcSubscribeError(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c1"}); code != codes.OutOfRange {
+		t.Errorf("resume from evicted ack = %v, want OutOfRange", code)
+	}
+	from := &cdcv1.Position{Lsn: testLSN(2), Seqval: testLSN(1)}
+	if code := grpcSubscribeError(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c2", FromPosition: from}); code != codes.OutOfRange {
+		t.Errorf("subscribe from evicted position = %v, want OutOfRange", code)
+	}
+
+	// posições ainda retidas continuam disponíveis
+	got := grpcReceive(t, client, &cdcv1.SubscribeRequest{ConsumerId: "c2", FromPosition: &cdcv1.Position{Lsn: testLSN(4), Seqval: testLSN(1)}}, 3)
+	if want := []string{testLSN(5), testLSN(6), testLSN(7)}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("received %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,325 @@
+// internal/sink/kafka.go
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+)
+
+// Mecanismos SASL aceitos em APP_GO_CDC_KAFKA_SASL_MECHANISM
+const (
+	KafkaSASLPlain       = "plain"
+	KafkaSASLScramSHA256 = "scram-sha-256"
+	KafkaSASLScramSHA512 = "scram-sha-512"
+)
+
+// kafkaMetadataMaxAge idade máxima da contagem de partições usada no fan-out
+const kafkaMetadataMaxAge = time.Minute
+
+// kafkaTopicInvalidChars caracteres fora do conjunto aceito em nomes de tópico
+var kafkaTopicInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+// kafkaPartitionKey chave de contexto do record com a partição fixa (fan-out)
+type kafkaPartitionKey struct{}
+
+// Kafka publica cada evento em um tópico derivado do template, com a chave
+// primária como chave da mensagem. O producer é idempotente (acks=all), então
+// reenvios internos não duplicam nem reordenam mensagens de uma partição.
+// Flush só retorna sem erro quando o broker confirmou todos os records, e só
+// então o pipeline grava os offsets da origem.
+type Kafka struct {
+	client        *kgo.Client
+	topicTemplate string
+	tombstones    bool
+
+	mu       sync.Mutex
+	errFirst error // primeiro erro de produção desde o último Flush
+}
+
+// NewKafka cria o sink (construtor). opts são aplicadas após as derivadas da
+// configuração, permitindo, por exemplo, apontar para um broker em memória
+// (kfake) com kgo.SeedBrokers
+func NewKafka(cfg *config.Config, opts ...kgo.Opt) (*Kafka, static.ErrorUtil) {
+	brokers := config.SplitList(cfg.KafkaBrokers)
+	if len(brokers) == 0 && len(opts) == 0 {
+		return nil, static.NewErrorUtil("Kafka brokers not configured", "KAFKA_CONFIG_INVALID", nil, "APP_GO_CDC_KAFKA_BROKERS")
+	}
+
+	clientID := cfg.KafkaClientID
+	if clientID == "" {
+		clientID = cfg.AppName
+	}
+	clientOpts := []kgo.Opt{
+		kgo.SeedBrokers(brokers...),
+		kgo.ClientID(clientID),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.RecordPartitioner(kgo.BasicConsistentPartitioner(kafkaPartition)),
+	}
+	if cfg.KafkaLingerMs > 0 {
+		clientOpts = append(clientOpts, kgo.ProducerLinger(time.Duration(cfg.KafkaLingerMs)*time.Millisecond))
+	}
+	if cfg.KafkaBatchMaxBytes > 0 {
+		clientOpts = append(clientOpts, kgo.ProducerBatchMaxBytes(int32(cfg.KafkaBatchMaxBytes)))
+	}
+
+	compression, errCompression := kafkaCompression(cfg.KafkaCompression)
+	if errCompression != nil {
+		return nil, errCompression
+	}
+	clientOpts = append(clientOpts, kgo.ProducerBatchCompression(compression))
+
+	if cfg.KafkaTLS {
+		tlsConfig, err := buildTLSConfig(cfg.KafkaTLSCAFile, cfg.KafkaTLSCertFile, cfg.KafkaTLSKeyFile, cfg.KafkaTLSInsecureSkipVerify)
+		if err != nil {
+			log.Error().Caller().Err(err).Msg("Failed to load Kafka TLS configuration")
+			return nil, static.NewErrorUtil("Failed to load Kafka TLS configuration", "KAFKA_CONFIG_INVALID", err, err.Error())
+		}
+		clientOpts = append(clientOpts, kgo.DialTLSConfig(tlsConfig))
+	}
+
+	if cfg.KafkaSASLMechanism != "" {
+		mechanism, errSASL := kafkaSASL(cfg)
+		if errSASL != nil {
+			return nil, errSASL
+		}
+		clientOpts = append(clientOpts, kgo.SASL(mechanism))
+	}
+
+	client, err := kgo.NewClient(append(clientOpts, opts...)...)
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to create Kafka client")
+		return nil, static.NewErrorUtil("Failed to create Kafka client", "KAFKA_CONFIG_INVALID", err, err.Error())
+	}
+
+	template := cfg.KafkaTopicTemplate
+	if template == "" {
+		template = static.APP_GO_CDC_KAFKA_TOPIC_TEMPLATE
+	}
+	return &Kafka{
+		client:        client,
+		topicTemplate: template,
+		tombstones:    cfg.KafkaTombstones,
+	}, nil
+}
+
+func kafkaCompression(name string) (kgo.CompressionCodec, static.ErrorUtil) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none":
+		return kgo.NoCompression(), nil
+	case "gzip":
+		return kgo.GzipCompression(), nil
+	case "snappy":
+		return kgo.SnappyCompression(), nil
+	case "lz4":
+		return kgo.Lz4Compression(), nil
+	case "zstd":
+		return kgo.ZstdCompression(), nil
+	}
+	log.Error().Caller().Str("compression", name).Msg("Unsupported Kafka compression codec")
+	return kgo.CompressionCodec{}, static.NewErrorUtil("Unsupported Kafka compression codec", "KAFKA_CONFIG_INVALID", nil, name)
+}
+
+func kafkaSASL(cfg *config.Config) (sasl.Mechanism, static.ErrorUtil) {
+	switch strings.ToLower(cfg.KafkaSASLMechanism) {
+	case KafkaSASLPlain:
+		return plain.Auth{User: cfg.KafkaSASLUser, Pass: cfg.KafkaSASLPass}.AsMechanism(), nil
+	case KafkaSASLScramSHA256:
+		return scram.Auth{User: cfg.KafkaSASLUser, Pass: cfg.KafkaSASLPass}.AsSha256Mechanism(), nil
+	case KafkaSASLScramSHA512:
+		return scram.Auth{User: cfg.KafkaSASLUser, Pass: cfg.KafkaSASLPass}.AsSha512Mechanism(), nil
+	}
+	log.Error().Caller().Str("mechanism", cfg.KafkaSASLMechanism).Msg("Unsupported Kafka SASL mechanism")
+	return nil, static.NewErrorUtil("Unsupported Kafka SASL mechanism", "KAFKA_CONFIG_INVALID", nil, cfg.KafkaSASLMechanism)
+}
+
+// kafkaPartition particiona pela chave com murmur2, compatível com o
+// particionador padrão do cliente Java: a mesma linha sempre vai para a mesma
+// partição. Sem chave, tudo vai para a partição 0 para preservar a ordem.
+func kafkaPartition(topic string) func(r *kgo.Record, n int) int {
+	return func(r *kgo.Record, n int) int {
+		if p, ok := r.Context.Value(kafkaPartitionKey{}).(int); ok && p < n {
+			return p
+		}
+		if r.Key == nil {
+			return 0
+		}
+		return int(murmur2(r.Key)&0x7fffffff) % n
+	}
+}
+
+// murmur2 implementação do hash usado pelo DefaultPartitioner do Kafka
+func murmur2(data []byte) uint32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := uint32(data[i]) | uint32(data[i+1])<<8 | uint32(data[i+2])<<16 | uint32(data[i+3])<<24
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
+
+func (k *Kafka) Name() string {
+	return TypeKafka
+}
+
+// Topic resolve o template ({db}, {schema}, {table}, {capture_instance}) para o evento
+func (k *Kafka) Topic(evt *event.Event) string {
+	return kafkaTopicInvalidChars.ReplaceAllString(resolveTableTemplate(k.topicTemplate, evt, nil), "_")
+}
+
+// Write enfileira os records no producer. Eventos sem linha (truncate e schema)
+// vão para todas as partições do tópico, para que cada consumidor os receba na
+// ordem correta em relação às linhas da sua partição.
+func (k *Kafka) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	for i := range events {
+		evt := &events[i]
+		value, err := json.Marshal(evt)
+		if err != nil {
+			return static.NewErrorUtil("Failed to encode event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+
+		topic := k.Topic(evt)
+		record := &kgo.Record{
+			Topic:     topic,
+			Key:       evt.MessageKey(),
+			Value:     value,
+			Timestamp: evt.CommitTime,
+			Headers: []kgo.RecordHeader{
+				{Key: "op", Value: []byte(evt.Op)},
+				{Key: "lsn", Value: []byte(evt.Position.LSN)},
+				{Key: "seqval", Value: []byte(evt.Position.SeqVal)},
+				{Key: "table", Value: []byte(evt.FullTableName())},
+			},
+		}
+
+		if evt.Op == event.OpTruncate || evt.Op == event.OpSchema {
+			partitions, errPartitions := k.partitions(ctx, topic)
+			if errPartitions != nil {
+				return errPartitions
+			}
+			for p := 0; p < partitions; p++ {
+				fanout := *record
+				fanout.Context = context.WithValue(ctx, kafkaPartitionKey{}, p)
+				k.client.Produce(ctx, &fanout, k.promise)
+			}
+			continue
+		}
+
+		k.client.Produce(ctx, record, k.promise)
+		if k.tombstones && evt.Op == event.OpDelete && record.Key != nil {
+			// tombstone: permite que a compactação do tópico remova a chave
+			k.client.Produce(ctx, &kgo.Record{Topic: topic, Key: record.Key, Timestamp: evt.CommitTime}, k.promise)
+		}
+	}
+	return nil
+}
+
+// partitions retorna o número de partições do tópico (metadata em cache)
+func (k *Kafka) partitions(ctx context.Context, topic string) (int, static.ErrorUtil) {
+	req := kmsg.NewPtrMetadataRequest()
+	reqTopic := kmsg.NewMetadataRequestTopic()
+	reqTopic.Topic = kmsg.StringPtr(topic)
+	req.Topics = append(req.Topics, reqTopic)
+
+	resp, err := k.client.RequestCachedMetadata(ctx, req, kafkaMetadataMaxAge)
+	if err == nil && len(resp.Topics) == 1 {
+		err = kerr.ErrorForCode(resp.Topics[0].ErrorCode)
+	}
+	if err == nil && (len(resp.Topics) != 1 || len(resp.Topics[0].Partitions) == 0) {
+		err = fmt.Errorf("no partitions found for topic %s", topic)
+	}
+	if err != nil {
+		log.Error().Caller().Err(err).Str("topic", topic).Msg("Failed to read Kafka topic metadata")
+		return 0, static.NewErrorUtil("Failed to read Kafka topic metadata", "SINK_WRITE_FAILED", err, topic)
+	}
+	return len(resp.Topics[0].Partitions), nil
+}
+
+func (k *Kafka) promise(r *kgo.Record, err error) {
+	if err == nil {
+		return
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.errFirst == nil {
+		k.errFirst = err
+		log.Error().Err(err).Str("topic", r.Topic).Int32("partition", r.Partition).Msg("Kafka produce failed")
+	}
+}
+
+// Flush aguarda a confirmação (acks=all) de todos os records enfileirados
+func (k *Kafka) Flush(ctx context.Context) static.ErrorUtil {
+	err := k.client.Flush(ctx)
+
+	k.mu.Lock()
+	if k.errFirst != nil {
+		err = k.errFirst
+		k.errFirst = nil
+	}
+	k.mu.Unlock()
+
+	if err != nil {
+		return static.NewErrorUtil("Failed to flush events to Kafka", "SINK_FLUSH_FAILED", err, err.Error())
+	}
+	return nil
+}
+
+func (k *Kafka) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+func (k *Kafka) HealthCheck(ctx context.Context) static.ErrorUtil {
+	if err := k.client.Ping(ctx); err != nil {
+		return static.NewErrorUtil("Kafka brokers unreachable", "SINK_UNHEALTHY", err, err.Error())
+	}
+	return nil
+}
+
+func (k *Kafka) Close() static.ErrorUtil {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	errFlush := k.Flush(ctx)
+	k.client.Close()
+	return errFlush
+}
@@ -0,0 +1,179 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+const kafkaTestTopic = "shop.dbo.Orders"
+
+func newKafkaTest(t *testing.T, cfg *config.Config) (*Kafka, *kfake.Cluster) {
+	t.Helper()
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(3, kafkaTestTopic))
+	if err != nil {
+		t.Fatalf("kfake.NewCluster: %v", err)
+	}
+	t.Cleanup(cluster.Close)
+
+	k, errKafka := NewKafka(cfg, kgo.SeedBrokers(cluster.ListenAddrs()...))
+	if errKafka != nil {
+		t.Fatalf("NewKafka: %v", errKafka)
+	}
+	t.Cleanup(func() { k.Close() })
+	return k, cluster
+}
+
+// consumeKafka lê want records do tópico desde o início
+func consumeKafka(t *testing.T, cluster *kfake.Cluster, want int) []*kgo.Record {
+	t.Helper()
+	consumer, err := kgo.NewClient(
+		kgo.SeedBrokers(cluster.ListenAddrs()...),
+		kgo.ConsumeTopics(kafkaTestTopic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	if err != nil {
+		t.Fatalf("consumer: %v", err)
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var records []*kgo.Record
+	for len(records) < want {
+		fetches := consumer.PollFetches(ctx)
+		if ctx.Err() != nil {
+			t.Fatalf("consumed %d of %d records", len(records), want)
+		}
+		records = append(records, fetches.Records()...)
+	}
+	return records
+}
+
+func TestKafkaTopic(t *testing.T) {
+	k := &Kafka{topicTemplate: "cdc.{db}.{schema}.{table}"}
+	evt := event.Event{Database: "shop", Schema: "dbo", Table: "Order Items"}
+	if got := k.Topic(&evt); got != "cdc.shop.dbo.Order_Items" {
+		t.Errorf("Topic = %q", got)
+	}
+}
+
+func TestKafkaPartitionsByKey(t *testing.T) {
+	k, cluster := newKafkaTest(t, &config.Config{})
+	ctx := context.Background()
+
+	var events []event.Event
+	for id := int64(1); id <= 20; id++ {
+		events = append(events, testOrder(event.OpInsert, testPosition(int(id), 1), nil, testRow(id, "new")))
+	}
+	// a mesma chave deve cair sempre na mesma partição
+	events = append(events, testOrder(event.OpUpdate, testPosition(21, 1), testRow(1, "new"), testRow(1, "paid")),
+		testOrder(event.OpUpdate, testPosition(22, 1), testRow(2, "new"), testRow(2, "paid")))
+
+	if errWrite := k.Write(ctx, events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if errFlush := k.Flush(ctx); errFlush != nil {
+		t.Fatalf("Flush: %v", errFlush)
+	}
+
+	used := map[int32]bool{}
+	for _, record := range consumeKafka(t, cluster, len(events)) {
+		want := int32(murmur2(record.Key)&0x7fffffff) % 3
+		if record.Partition != want {
+			t.Errorf("key %s on partition %d, want %d", record.Key, record.Partition, want)
+		}
+		used[record.Partition] = true
+	}
+	if len(used) < 2 {
+		t.Errorf("records not spread across partitions: %v", used)
+	}
+}
+
+func TestKafkaFanOutToAllPartitions(t *testing.T) {
+	k, cluster := newKafkaTest(t, &config.Config{KafkaTombstones: true})
+	ctx := context.Background()
+
+	events := []event.Event{
+		testOrder(event.OpTruncate, testPosition(1, 0), nil, nil),
+		testOrder(event.OpSchema, testPosition(1, 0), nil, nil),
+		testOrder(event.OpDelete, testPosition(2, 1), testRow(7, "new"), nil),
+	}
+	if errWrite := k.Write(ctx, events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if errFlush := k.Flush(ctx); errFlush != nil {
+		t.Fatalf("Flush: %v", errFlush)
+	}
+
+	// 3 partições para truncate e schema, mais delete e tombstone
+	ops := map[string]map[int32]int{}
+	tombstones := 0
+	for _, record := range consumeKafka(t, cluster, 8) {
+		if record.Value == nil {
+			tombstones++
+			continue
+		}
+		op := string(record.Headers[0].Value)
+		if ops[op] == nil {
+			ops[op] = map[int32]int{}
+		}
+		ops[op][record.Partition]++
+	}
+	for _, op := range []event.Operation{event.OpTruncate, event.OpSchema} {
+		if got := ops[string(op)]; len(got) != 3 || got[0] != 1 || got[1] != 1 || got[2] != 1 {
+			t.Errorf("%s records per partition = %v", op, got)
+		}
+	}
+	if len(ops[string(event.OpDelete)]) != 1 || tombstones != 1 {
+		t.Errorf("delete records = %v, tombstones = %d", ops[string(event.OpDelete)], tombstones)
+	}
+}
+
+func TestKafkaFlushWaitsForAcks(t *testing.T) {
+	k, cluster := newKafkaTest(t, &config.Config{})
+	ctx := context.Background()
+
+	release := make(chan struct{})
+	cluster.ControlKey(int16(kmsg.Produce), func(kmsg.Request) (kmsg.Response, error, bool) {
+		cluster.SleepControl(func() { <-release })
+		return nil, nil, false
+	})
+
+	if errWrite := k.Write(ctx, []event.Event{testOrder(event.OpInsert, testPosition(1, 1), nil, testRow(1, "new"))}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if errFlush := k.Flush(ctx); errFlush != nil {
+			done <- fmt.Errorf("%v", errFlush)
+			return
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Flush returned before the broker acknowledged: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Flush did not return after the broker acknowledged")
+	}
+}
@@ -0,0 +1,242 @@
+// internal/sink/nats.go
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/rs/zerolog/log"
+)
+
+// natsTokenReplacer caracteres com significado em subjects NATS (separador,
+// curingas e espaços) trocados nos nomes de banco, schema e tabela
+var natsTokenReplacer = strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_", "\t", "_")
+
+// NATS publica cada evento no JetStream em um subject derivado do template.
+// Nats-Msg-Id identifica o evento, então reenvios após falhas dentro da janela
+// de duplicatas do stream são descartados pelo servidor. Flush aguarda o PubAck
+// de todas as mensagens antes de o pipeline gravar os offsets da origem.
+type NATS struct {
+	conn            *nats.Conn
+	js              jetstream.JetStream
+	stream          string
+	subjectTemplate string
+
+	mu      sync.Mutex
+	futures []jetstream.PubAckFuture // publicações aguardando PubAck desde o último Flush
+}
+
+// NewNATS cria o sink (construtor). opts são aplicadas após as derivadas da
+// configuração (ex.: servidor embutido em testes)
+func NewNATS(ctx context.Context, cfg *config.Config, opts ...nats.Option) (*NATS, static.ErrorUtil) {
+	if cfg.NATSURL == "" {
+		return nil, static.NewErrorUtil("NATS URL not configured", "NATS_CONFIG_INVALID", nil, "APP_GO_CDC_NATS_URL")
+	}
+
+	name := cfg.NATSClientName
+	if name == "" {
+		name = cfg.AppName
+	}
+	natsOpts := []nats.Option{nats.Name(name), nats.MaxReconnects(-1)}
+	switch {
+	case cfg.NATSCredsFile != "":
+		natsOpts = append(natsOpts, nats.UserCredentials(cfg.NATSCredsFile))
+	case cfg.NATSToken != "":
+		natsOpts = append(natsOpts, nats.Token(cfg.NATSToken))
+	case cfg.NATSUser != "":
+		natsOpts = append(natsOpts, nats.UserInfo(cfg.NATSUser, cfg.NATSPass))
+	}
+	if cfg.NATSTLS {
+		tlsConfig, err := buildTLSConfig(cfg.NATSTLSCAFile, cfg.NATSTLSCertFile, cfg.NATSTLSKeyFile, cfg.NATSTLSInsecureSkipVerify)
+		if err != nil {
+			log.Error().Caller().Err(err).Msg("Failed to load NATS TLS configuration")
+			return nil, static.NewErrorUtil("Failed to load NATS TLS configuration", "NATS_CONFIG_INVALID", err, err.Error())
+		}
+		natsOpts = append(natsOpts, nats.Secure(tlsConfig))
+	}
+
+	conn, err := nats.Connect(cfg.NATSURL, append(natsOpts, opts...)...)
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to connect to NATS")
+		return nil, static.NewErrorUtil("Failed to connect to NATS", "NATS_CONNECT_FAILED", err, err.Error())
+	}
+
+	maxPending := cfg.NATSMaxPending
+	if maxPending <= 0 {
+		maxPending = static.APP_GO_CDC_NATS_MAX_PENDING
+	}
+	js, err := jetstream.New(conn, jetstream.WithPublishAsyncMaxPending(maxPending))
+	if err != nil {
+		conn.Close()
+		log.Error().Caller().Err(err).Msg("Failed to create JetStream context")
+		return nil, static.NewErrorUtil("Failed to create JetStream context", "NATS_CONNECT_FAILED", err, err.Error())
+	}
+
+	n := &NATS{
+		conn:            conn,
+		js:              js,
+		stream:          cfg.NATSStream,
+		subjectTemplate: cfg.NATSSubjectTemplate,
+	}
+	if n.stream == "" {
+		n.stream = static.APP_GO_CDC_NATS_STREAM
+	}
+	if n.subjectTemplate == "" {
+		n.subjectTemplate = static.APP_GO_CDC_NATS_SUBJECT_TEMPLATE
+	}
+
+	if errStream := n.ensureStream(ctx, cfg); errStream != nil {
+		conn.Close()
+		return nil, errStream
+	}
+	return n, nil
+}
+
+// ensureStream verifica se o stream existe e, se APP_GO_CDC_NATS_CREATE_STREAM
+// estiver ativo, o cria capturando os subjects do template
+func (n *NATS) ensureStream(ctx context.Context, cfg *config.Config) static.ErrorUtil {
+	_, err := n.js.Stream(ctx, n.stream)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) || !cfg.NATSCreateStream {
+		log.Error().Caller().Err(err).Str("stream", n.stream).Msg("JetStream stream not available")
+		return static.NewErrorUtil("JetStream stream not available", "NATS_STREAM_NOT_FOUND", err, n.stream)
+	}
+
+	duplicates := time.Duration(cfg.NATSDuplicateWindowSeconds) * time.Second
+	if duplicates <= 0 {
+		duplicates = static.APP_GO_CDC_NATS_DUPLICATE_WINDOW_SECONDS * time.Second
+	}
+	subject := strings.NewReplacer("{db}", "*", "{schema}", "*", "{table}", "*", "{capture_instance}", "*").Replace(n.subjectTemplate)
+	_, err = n.js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:       n.stream,
+		Subjects:   []string{subject},
+		Storage:    jetstream.FileStorage,
+		Duplicates: duplicates,
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Str("stream", n.stream).Msg("Failed to create JetStream stream")
+		return static.NewErrorUtil("Failed to create JetStream stream", "NATS_STREAM_CREATE_FAILED", err, err.Error())
+	}
+	log.Info().Str("stream", n.stream).Str("subject", subject).Msg("JetStream stream created")
+	return nil
+}
+
+func (n *NATS) Name() string {
+	return TypeNATS
+}
+
+// Subject resolve o template ({db}, {schema}, {table}, {capture_instance}) para o evento
+func (n *NATS) Subject(evt *event.Event) string {
+	return resolveTableTemplate(n.subjectTemplate, evt, natsTokenReplacer.Replace)
+}
+
+// natsMsgID identificador de deduplicação do evento. Eventos do log são únicos
+// pela tabela e posição; os gerados (snapshot, truncate, schema, histórico)
+// compartilham posições e levam também a operação, o instante e a chave.
+func natsMsgID(evt *event.Event) string {
+	id := evt.FullTableName() + ":" + evt.Position.String()
+	if evt.FromLog() {
+		return id
+	}
+	id += ":" + string(evt.Op)
+	if evt.Historical {
+		id += ":" + evt.CommitTime.UTC().Format(time.RFC3339Nano)
+	}
+	if key := evt.MessageKey(); key != nil {
+		id += ":" + string(key)
+	}
+	return id
+}
+
+func (n *NATS) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	for i := range events {
+		evt := &events[i]
+		data, err := json.Marshal(evt)
+		if err != nil {
+			return static.NewErrorUtil("Failed to encode event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+
+		msg := nats.NewMsg(n.Subject(evt))
+		msg.Data = data
+		msg.Header.Set("op", string(evt.Op))
+		msg.Header.Set("lsn", evt.Position.LSN)
+		msg.Header.Set("seqval", evt.Position.SeqVal)
+		msg.Header.Set("table", evt.FullTableName())
+
+		// bloqueia enquanto houver APP_GO_CDC_NATS_MAX_PENDING publicações sem PubAck
+		future, err := n.js.PublishMsgAsync(msg, jetstream.WithMsgID(natsMsgID(evt)), jetstream.WithExpectStream(n.stream))
+		if err != nil {
+			log.Error().Err(err).Str("subject", msg.Subject).Msg("JetStream publish failed")
+			return static.NewErrorUtil("Failed to publish event to JetStream", "SINK_WRITE_FAILED", err, err.Error())
+		}
+
+		n.mu.Lock()
+		n.futures = append(n.futures, future)
+		n.mu.Unlock()
+	}
+	return nil
+}
+
+// Flush aguarda o PubAck de todas as publicações pendentes
+func (n *NATS) Flush(ctx context.Context) static.ErrorUtil {
+	n.mu.Lock()
+	futures := n.futures
+	n.futures = nil
+	n.mu.Unlock()
+
+	var errFirst error
+	for _, future := range futures {
+		select {
+		case ack := <-future.Ok():
+			if ack.Duplicate {
+				log.Debug().Str("subject", future.Msg().Subject).Uint64("seq", ack.Sequence).Msg("JetStream discarded duplicate message")
+			}
+		case err := <-future.Err():
+			if errFirst == nil {
+				errFirst = err
+				log.Error().Err(err).Str("subject", future.Msg().Subject).Msg("JetStream publish not acknowledged")
+			}
+		case <-ctx.Done():
+			return static.NewErrorUtil("Failed to flush events to JetStream", "SINK_FLUSH_FAILED", ctx.Err(), ctx.Err().Error())
+		}
+	}
+
+	if errFirst != nil {
+		return static.NewErrorUtil("Failed to flush events to JetStream", "SINK_FLUSH_FAILED", errFirst, errFirst.Error())
+	}
+	return nil
+}
+
+func (n *NATS) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+func (n *NATS) HealthCheck(ctx context.Context) static.ErrorUtil {
+	if !n.conn.IsConnected() {
+		return static.NewErrorUtil("NATS connection lost", "SINK_UNHEALTHY", nil, n.conn.Status().String())
+	}
+	if _, err := n.js.AccountInfo(ctx); err != nil {
+		return static.NewErrorUtil("JetStream unavailable", "SINK_UNHEALTHY", err, err.Error())
+	}
+	return nil
+}
+
+func (n *NATS) Close() static.ErrorUtil {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	errFlush := n.Flush(ctx)
+	n.conn.Close()
+	return errFlush
+}
@@ -0,0 +1,171 @@
+package sink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func startNATSServer(t *testing.T, jetStream bool) *server.Server {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true, JetStream: jetStream, StoreDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("nats server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("nats server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv
+}
+
+func TestNATSSubject(t *testing.T) {
+	n := &NATS{subjectTemplate: "cdc.{db}.{schema}.{table}"}
+	evt := event.Event{Database: "shop", Schema: "dbo", Table: "Order Items.v2*"}
+	if got := n.Subject(&evt); got != "cdc.shop.dbo.Order_Items_v2_" {
+		t.Errorf("Subject = %q", got)
+	}
+}
+
+func TestNATSCreatesStream(t *testing.T) {
+	srv := startNATSServer(t, true)
+	ctx := context.Background()
+
+	if _, errNATS := NewNATS(ctx, &config.Config{NATSURL: srv.ClientURL()}); errNATS == nil || errNATS.Code() != "NATS_STREAM_NOT_FOUND" {
+		t.Fatalf("expected NATS_STREAM_NOT_FOUND without auto-create, got %v", errNATS)
+	}
+
+	n, errNATS := NewNATS(ctx, &config.Config{NATSURL: srv.ClientURL(), NATSCreateStream: true})
+	if errNATS != nil {
+		t.Fatalf("NewNATS: %v", errNATS)
+	}
+	defer n.Close()
+
+	stream, err := n.js.Stream(ctx, n.stream)
+	if err != nil {
+		t.Fatalf("stream not created: %v", err)
+	}
+	info := stream.CachedInfo()
+	if len(info.Config.Subjects) != 1 || info.Config.Subjects[0] != "cdc.*.*.*" || info.Config.Duplicates != 120*time.Second {
+		t.Errorf("stream config = %+v", info.Config)
+	}
+}
+
+func TestNATSDeduplicatesRepublish(t *testing.T) {
+	srv := startNATSServer(t, true)
+	ctx := context.Background()
+
+	n, errNATS := NewNATS(ctx, &config.Config{NATSURL: srv.ClientURL(), NATSCreateStream: true})
+	if errNATS != nil {
+		t.Fatalf("NewNATS: %v", errNATS)
+	}
+	defer n.Close()
+
+	events := []event.Event{
+		testOrder(event.OpInsert, testPosition(16, 1), nil, testRow(1, "new")),
+		testOrder(event.OpInsert, testPosition(17, 1), nil, testRow(2, "new")),
+	}
+	// a segunda publicação simula o reenvio do lote após uma falha antes do commit dos offsets
+	for range 2 {
+		if errWrite := n.Write(ctx, events); errWrite != nil {
+			t.Fatalf("Write: %v", errWrite)
+		}
+		if errFlush := n.Flush(ctx); errFlush != nil {
+			t.Fatalf("Flush: %v", errFlush)
+		}
+	}
+
+	stream, err := n.js.Stream(ctx, n.stream)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatalf("Info: %v", err)
+	}
+	if info.State.Msgs != 2 {
+		t.Errorf("stream has %d messages, want 2", info.State.Msgs)
+	}
+
+	msg, err := stream.GetLastMsgForSubject(ctx, "cdc.shop.dbo.Orders")
+	if err != nil {
+		t.Fatalf("GetLastMsgForSubject: %v", err)
+	}
+	if got := msg.Header.Get(jetstream.MsgIDHeader); got != natsMsgID(&events[1]) {
+		t.Errorf("%s = %q", jetstream.MsgIDHeader, got)
+	}
+}
+
+func TestNATSFlushWaitsForPubAck(t *testing.T) {
+	// servidor sem JetStream: o teste responde os PubAcks no lugar do stream
+	srv := startNATSServer(t, false)
+	conn, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	if err != nil {
+		t.Fatalf("jetstream.New: %v", err)
+	}
+	n := &NATS{conn: conn, js: js, stream: "GO_CDC", subjectTemplate: "cdc.{db}.{schema}.{table}"}
+
+	responder, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer responder.Close()
+	pending := make(chan *nats.Msg, 1)
+	if _, err := responder.ChanSubscribe("cdc.>", pending); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := responder.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	ctx := context.Background()
+	if errWrite := n.Write(ctx, []event.Event{testOrder(event.OpInsert, testPosition(16, 1), nil, testRow(1, "new"))}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if errFlush := n.Flush(ctx); errFlush != nil {
+			done <- errFlush
+			return
+		}
+		done <- nil
+	}()
+
+	var msg *nats.Msg
+	select {
+	case msg = <-pending:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not published")
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("Flush returned before the PubAck: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := msg.Respond([]byte(`{"stream":"GO_CDC","seq":1}`)); err != nil {
+		t.Fatalf("Respond: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Flush: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Flush did not return after the PubAck")
+	}
+}
@@ -0,0 +1,603 @@
+// internal/sink/parquet.go
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/schema"
+	"go-cdc/static"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+	"github.com/rs/zerolog/log"
+)
+
+// Colunas de metadados acrescentadas a cada linha
+const (
+	ParquetColumnOp         = "_op"
+	ParquetColumnLSN        = "_lsn"
+	ParquetColumnSeqVal     = "_seqval"
+	ParquetColumnCommitTime = "_commit_time"
+	// ParquetColumnUnchanged colunas LOB não alteradas no update (NULL nesses
+	// campos não significa valor nulo)
+	ParquetColumnUnchanged = "_unchanged_columns"
+)
+
+// parquetPathReplacer caracteres que quebrariam o caminho das partições
+var parquetPathReplacer = strings.NewReplacer("/", "_", `\`, "_", "=", "_")
+
+// parquetColumn coluna do arquivo com a conversão do valor do evento
+type parquetColumn struct {
+	name    string
+	index   int
+	convert func(v interface{}) (parquet.Value, error)
+}
+
+// parquetSchema schema Parquet derivado de uma versão do schema da tabela
+type parquetSchema struct {
+	schema  *parquet.Schema
+	columns []parquetColumn // colunas da tabela
+	meta    map[string]int  // índice das colunas de metadados
+	width   int
+}
+
+// parquetPartition linhas de uma partição (tabela/data/hora) e versão de schema
+type parquetPartition struct {
+	path   string
+	schema *parquetSchema
+	rows   []parquet.Row
+}
+
+// Parquet grava os eventos em arquivos Parquet particionados por
+// table=/date=/hour= (data e hora de commit em UTC), em um diretório local ou
+// em um bucket S3 compatível. O schema vem da versão do schema da tabela válida
+// no evento, com tipos lógicos para decimal, data/hora e UUID. Arquivos Parquet
+// não aceitam append: Flush grava um arquivo por partição com as linhas do lote,
+// então o tamanho dos arquivos acompanha APP_GO_CDC_BATCH_SIZE.
+type Parquet struct {
+	dir          string
+	s3           *minio.Client
+	bucket       string
+	prefix       string
+	codec        compress.Codec
+	rowGroupSize int64
+
+	mu         sync.Mutex
+	schemas    map[*schema.TableSchema]*parquetSchema
+	partitions map[string]*parquetPartition
+	order      []string
+	sequence   int
+}
+
+// NewParquet cria o sink (construtor). APP_GO_CDC_PARQUET_S3_BUCKET seleciona o
+// destino S3; caso contrário usa APP_GO_CDC_PARQUET_DIR
+func NewParquet(ctx context.Context, cfg *config.Config) (*Parquet, static.ErrorUtil) {
+	p := &Parquet{
+		dir:          cfg.ParquetDir,
+		bucket:       cfg.ParquetS3Bucket,
+		prefix:       strings.Trim(cfg.ParquetS3Prefix, "/"),
+		rowGroupSize: int64(cfg.ParquetRowGroupSize),
+		schemas:      make(map[*schema.TableSchema]*parquetSchema),
+		partitions:   make(map[string]*parquetPartition),
+	}
+	if p.rowGroupSize <= 0 {
+		p.rowGroupSize = static.APP_GO_CDC_PARQUET_ROW_GROUP_SIZE
+	}
+
+	switch strings.ToLower(strings.TrimSpace(cfg.ParquetCompression)) {
+	case "", "snappy":
+		p.codec = &parquet.Snappy
+	case "none":
+		p.codec = &parquet.Uncompressed
+	case "gzip":
+		p.codec = &parquet.Gzip
+	case "zstd":
+		p.codec = &parquet.Zstd
+	default:
+		log.Error().Caller().Str("compression", cfg.ParquetCompression).Msg("Unsupported Parquet compression codec")
+		return nil, static.NewErrorUtil("Unsupported Parquet compression codec", "PARQUET_CONFIG_INVALID", nil, cfg.ParquetCompression)
+	}
+
+	if p.bucket == "" {
+		if p.dir == "" {
+			return nil, static.NewErrorUtil("Parquet destination not configured", "PARQUET_CONFIG_INVALID", nil, "APP_GO_CDC_PARQUET_DIR or APP_GO_CDC_PARQUET_S3_BUCKET")
+		}
+		if err := os.MkdirAll(p.dir, 0o755); err != nil {
+			log.Error().Caller().Err(err).Str("dir", p.dir).Msg("Failed to create Parquet directory")
+			return nil, static.NewErrorUtil("Failed to create Parquet directory", "PARQUET_CONFIG_INVALID", err, err.Error())
+		}
+		return p, nil
+	}
+
+	creds := credentials.NewStaticV4(cfg.ParquetS3AccessKey, cfg.ParquetS3SecretKey, "")
+	if cfg.ParquetS3AccessKey == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{&credentials.EnvAWS{}, &credentials.IAM{}})
+	}
+	lookup := minio.BucketLookupAuto
+	if cfg.ParquetS3PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(cfg.ParquetS3Endpoint, &minio.Options{
+		Creds:        creds,
+		Secure:       cfg.ParquetS3UseSSL,
+		Region:       cfg.ParquetS3Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Failed to create S3 client")
+		return nil, static.NewErrorUtil("Failed to create S3 client", "PARQUET_CONFIG_INVALID", err, err.Error())
+	}
+	p.s3 = client
+
+	if errBucket := p.HealthCheck(ctx); errBucket != nil {
+		return nil, errBucket
+	}
+	return p, nil
+}
+
+func (p *Parquet) Name() string {
+	return TypeParquet
+}
+
+// schemaFor retorna (em cache) o schema Parquet da versão do schema da tabela
+func (p *Parquet) schemaFor(tableSchema *schema.TableSchema) *parquetSchema {
+	if ps, ok := p.schemas[tableSchema]; ok {
+		return ps
+	}
+
+	group := parquet.Group{
+		ParquetColumnOp:         parquet.String(),
+		ParquetColumnLSN:        parquet.String(),
+		ParquetColumnSeqVal:     parquet.String(),
+		ParquetColumnCommitTime: parquet.Optional(parquet.Timestamp(parquet.Microsecond)),
+		ParquetColumnUnchanged:  parquet.Optional(parquet.String()),
+	}
+	converters := make(map[string]func(v interface{}) (parquet.Value, error))
+	for _, col := range tableSchema.CapturedColumns() {
+		if _, reserved := group[col.Name]; reserved {
+			log.Warn().Str("table", tableSchema.Table).Str("column", col.Name).Msg("Column name collides with Parquet metadata column, skipping")
+			continue
+		}
+		node, convert := parquetNode(col)
+		group[col.Name] = parquet.Optional(node)
+		converters[col.Name] = convert
+	}
+
+	name := parquetPathReplacer.Replace(tableSchema.Table)
+	ps := &parquetSchema{schema: parquet.NewSchema(name, group), meta: make(map[string]int)}
+	for i, columnPath := range ps.schema.Columns() {
+		columnName := columnPath[0]
+		if convert, ok := converters[columnName]; ok {
+			ps.columns = append(ps.columns, parquetColumn{name: columnName, index: i, convert: convert})
+		} else {
+			ps.meta[columnName] = i
+		}
+	}
+	ps.width = len(ps.schema.Columns())
+	p.schemas[tableSchema] = ps
+	return ps
+}
+
+// parquetNode mapeia o tipo SQL Server da coluna para o tipo Parquet e a conversão do valor
+func parquetNode(col schema.Column) (parquet.Node, func(v interface{}) (parquet.Value, error)) {
+	switch strings.ToLower(col.Type) {
+	case "bit":
+		return parquet.Leaf(parquet.BooleanType), func(v interface{}) (parquet.Value, error) {
+			b, ok := v.(bool)
+			if !ok {
+				return parquet.Value{}, fmt.Errorf("expected bool, got %T", v)
+			}
+			return parquet.BooleanValue(b), nil
+		}
+	case "tinyint":
+		return parquet.Uint(8), int32Converter
+	case "smallint":
+		return parquet.Int(16), int32Converter
+	case "int":
+		return parquet.Int(32), int32Converter
+	case "bigint":
+		return parquet.Int(64), func(v interface{}) (parquet.Value, error) {
+			n, err := toInt64(v)
+			return parquet.Int64Value(n), err
+		}
+	case "real":
+		return parquet.Leaf(parquet.FloatType), func(v interface{}) (parquet.Value, error) {
+			f, err := toFloat64(v)
+			return parquet.FloatValue(float32(f)), err
+		}
+	case "float":
+		return parquet.Leaf(parquet.DoubleType), func(v interface{}) (parquet.Value, error) {
+			f, err := toFloat64(v)
+			return parquet.DoubleValue(f), err
+		}
+	case "decimal", "numeric", "money", "smallmoney":
+		return parquetDecimal(col.Precision, col.Scale)
+	case "date":
+		return parquet.Date(), func(v interface{}) (parquet.Value, error) {
+			t, err := toTime(v)
+			if err != nil {
+				return parquet.Value{}, err
+			}
+			days := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+			return parquet.Int32Value(int32(days)), nil
+		}
+	case "time":
+		return parquet.Time(parquet.Nanosecond), func(v interface{}) (parquet.Value, error) {
+			t, err := toTime(v)
+			if err != nil {
+				return parquet.Value{}, err
+			}
+			midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+			return parquet.Int64Value(int64(t.Sub(midnight))), nil
+		}
+	case "datetime", "datetime2", "smalldatetime":
+		// sem fuso: valor de relógio, não ajustado para UTC
+		return parquet.TimestampAdjusted(parquet.Microsecond, false), timestampConverter
+	case "datetimeoffset":
+		return parquet.Timestamp(parquet.Microsecond), timestampConverter
+	case "uniqueidentifier":
+		return parquet.UUID(), func(v interface{}) (parquet.Value, error) {
+			s, ok := v.(string)
+			if !ok {
+				return parquet.Value{}, fmt.Errorf("expected uuid string, got %T", v)
+			}
+			raw, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+			if err != nil || len(raw) != 16 {
+				return parquet.Value{}, fmt.Errorf("invalid uuid %q", s)
+			}
+			return parquet.FixedLenByteArrayValue(raw), nil
+		}
+	case "binary", "varbinary", "image", "timestamp", "rowversion", "hierarchyid", "geography", "geometry":
+		return parquet.Leaf(parquet.ByteArrayType), func(v interface{}) (parquet.Value, error) {
+			if b, ok := v.([]byte); ok {
+				return parquet.ByteArrayValue(b), nil
+			}
+			// valores substituídos pela política de LOB (hash, referência externa)
+			return parquet.ByteArrayValue([]byte(parquetText(v))), nil
+		}
+	}
+	// texto, xml, sql_variant e demais tipos
+	return parquet.String(), func(v interface{}) (parquet.Value, error) {
+		return parquet.ByteArrayValue([]byte(parquetText(v))), nil
+	}
+}
+
+// parquetDecimal decimal com precisão e escala da coluna: int32 até 9 dígitos,
+// int64 até 18 e fixed_len_byte_array(16) acima
+func parquetDecimal(precision, scale int) (parquet.Node, func(v interface{}) (parquet.Value, error)) {
+	precision = min(max(precision, 1), 38)
+	multiplier := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	unscaled := func(v interface{}) (*big.Int, error) {
+		r := new(big.Rat)
+		switch value := v.(type) {
+		case string:
+			if _, ok := r.SetString(strings.TrimSpace(value)); !ok {
+				return nil, fmt.Errorf("invalid decimal %q", value)
+			}
+		case float64:
+			r.SetFloat64(value)
+		case int64:
+			r.SetInt64(value)
+		default:
+			return nil, fmt.Errorf("expected decimal, got %T", v)
+		}
+		r.Mul(r, multiplier)
+		// arredonda meio para longe do zero caso o valor tenha mais casas que a escala
+		n, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+		if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+			n.Add(n, big.NewInt(int64(r.Sign())))
+		}
+		return n, nil
+	}
+
+	switch {
+	case precision <= 9:
+		return parquet.Decimal(scale, precision, parquet.Int32Type), func(v interface{}) (parquet.Value, error) {
+			n, err := unscaled(v)
+			if err != nil {
+				return parquet.Value{}, err
+			}
+			return parquet.Int32Value(int32(n.Int64())), nil
+		}
+	case precision <= 18:
+		return parquet.Decimal(scale, precision, parquet.Int64Type), func(v interface{}) (parquet.Value, error) {
+			n, err := unscaled(v)
+			if err != nil {
+				return parquet.Value{}, err
+			}
+			return parquet.Int64Value(n.Int64()), nil
+		}
+	}
+	return parquet.Decimal(scale, precision, parquet.FixedLenByteArrayType(16)), func(v interface{}) (parquet.Value, error) {
+		n, err := unscaled(v)
+		if err != nil {
+			return parquet.Value{}, err
+		}
+		return parquet.FixedLenByteArrayValue(twosComplement(n, 16)), nil
+	}
+}
+
+// twosComplement codifica n em complemento de dois big-endian com size bytes
+func twosComplement(n *big.Int, size int) []byte {
+	if n.Sign() < 0 {
+		n = new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), uint(size*8)), n)
+	}
+	out := make([]byte, size)
+	return n.FillBytes(out)
+}
+
+func int32Converter(v interface{}) (parquet.Value, error) {
+	n, err := toInt64(v)
+	return parquet.Int32Value(int32(n)), err
+}
+
+func timestampConverter(v interface{}) (parquet.Value, error) {
+	t, err := toTime(v)
+	return parquet.Int64Value(t.UnixMicro()), err
+}
+
+func toInt64(v interface{}) (int64, error) {
+	switch value := v.(type) {
+	case int64:
+		return value, nil
+	case int32:
+		return int64(value), nil
+	case int:
+		return int64(value), nil
+	case uint8:
+		return int64(value), nil
+	case float64:
+		return int64(value), nil
+	case string:
+		return strconv.ParseInt(value, 10, 64)
+	}
+	return 0, fmt.Errorf("expected integer, got %T", v)
+}
+
+func toFloat64(v interface{}) (float64, error) {
+	switch value := v.(type) {
+	case float64:
+		return value, nil
+	case float32:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case string:
+		return strconv.ParseFloat(value, 64)
+	}
+	return 0, fmt.Errorf("expected float, got %T", v)
+}
+
+// toTime aceita time.Time e o texto ISO-8601 das colunas de período temporais
+func toTime(v interface{}) (time.Time, error) {
+	switch value := v.(type) {
+	case time.Time:
+		return value, nil
+	case string:
+		return time.Parse(time.RFC3339Nano, value)
+	}
+	return time.Time{}, fmt.Errorf("expected time, got %T", v)
+}
+
+// parquetText representação textual de valores fora do tipo da coluna
+func parquetText(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
+
+// partitionPath table=schema.tabela/date=AAAA-MM-DD/hour=HH do instante de commit
+func partitionPath(evt *event.Event) string {
+	at := evt.CommitTime.UTC()
+	if evt.CommitTime.IsZero() {
+		at = time.Now().UTC()
+	}
+	return path.Join(
+		"table="+parquetPathReplacer.Replace(evt.FullTableName()),
+		"date="+at.Format("2006-01-02"),
+		"hour="+at.Format("15"),
+	)
+}
+
+// Write converte os eventos em linhas e as acumula por partição até o Flush.
+// Truncates viram uma linha só com os metadados; eventos de schema são ignorados
+// (a nova versão gera um novo arquivo).
+func (p *Parquet) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i := range events {
+		evt := &events[i]
+		if evt.Op == event.OpSchema {
+			continue
+		}
+		if evt.TableSchema == nil {
+			log.Warn().Str("table", evt.FullTableName()).Str("op", string(evt.Op)).Msg("Event without table schema, skipping Parquet row")
+			continue
+		}
+
+		ps := p.schemaFor(evt.TableSchema)
+		row, err := ps.row(evt)
+		if err != nil {
+			log.Error().Err(err).Str("table", evt.FullTableName()).Str("position", evt.Position.String()).Msg("Failed to convert event to Parquet row")
+			return static.NewErrorUtil("Failed to convert event to Parquet row", "SINK_WRITE_FAILED", err, err.Error())
+		}
+
+		partPath := partitionPath(evt)
+		key := partPath + "@" + evt.TableSchema.ValidFrom
+		partition, ok := p.partitions[key]
+		if !ok {
+			partition = &parquetPartition{path: partPath, schema: ps}
+			p.partitions[key] = partition
+			p.order = append(p.order, key)
+		}
+		partition.rows = append(partition.rows, row)
+	}
+	return nil
+}
+
+// row monta a linha na ordem das colunas do schema
+func (ps *parquetSchema) row(evt *event.Event) (parquet.Row, error) {
+	row := make(parquet.Row, ps.width)
+	required := func(name, value string) {
+		index := ps.meta[name]
+		row[index] = parquet.ByteArrayValue([]byte(value)).Level(0, 0, index)
+	}
+	optional := func(index int, value parquet.Value, present bool) {
+		if !present {
+			row[index] = parquet.NullValue().Level(0, 0, index)
+			return
+		}
+		row[index] = value.Level(0, 1, index)
+	}
+
+	required(ParquetColumnOp, string(evt.Op))
+	required(ParquetColumnLSN, evt.Position.LSN)
+	required(ParquetColumnSeqVal, evt.Position.SeqVal)
+	optional(ps.meta[ParquetColumnCommitTime], parquet.Int64Value(evt.CommitTime.UnixMicro()), !evt.CommitTime.IsZero())
+	optional(ps.meta[ParquetColumnUnchanged], parquet.ByteArrayValue([]byte(strings.Join(evt.UnchangedColumns, ","))), len(evt.UnchangedColumns) > 0)
+
+	var values map[string]interface{}
+	if evt.Op != event.OpTruncate {
+		values = evt.Row()
+	}
+	for _, col := range ps.columns {
+		value, present := values[col.name]
+		if !present || value == nil {
+			optional(col.index, parquet.Value{}, false)
+			continue
+		}
+		converted, err := col.convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", col.name, err)
+		}
+		optional(col.index, converted, true)
+	}
+	return row, nil
+}
+
+// Flush grava um arquivo por partição acumulada. As linhas são descartadas
+// mesmo em caso de erro: o pipeline reenvia o lote inteiro.
+func (p *Parquet) Flush(ctx context.Context) static.ErrorUtil {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	partitions, order := p.partitions, p.order
+	p.partitions, p.order = make(map[string]*parquetPartition), nil
+
+	for _, key := range order {
+		partition := partitions[key]
+		var buf bytes.Buffer
+		writer := parquet.NewWriter(&buf, partition.schema.schema,
+			parquet.Compression(p.codec),
+			parquet.MaxRowsPerRowGroup(p.rowGroupSize),
+			parquet.CreatedBy(static.APP_GO_CDC_NAME, "", ""),
+		)
+		if _, err := writer.WriteRows(partition.rows); err != nil {
+			return static.NewErrorUtil("Failed to encode Parquet file", "SINK_FLUSH_FAILED", err, err.Error())
+		}
+		if err := writer.Close(); err != nil {
+			return static.NewErrorUtil("Failed to encode Parquet file", "SINK_FLUSH_FAILED", err, err.Error())
+		}
+
+		p.sequence++
+		name := path.Join(partition.path, fmt.Sprintf("part-%s-%06d.parquet", time.Now().UTC().Format("20060102T150405.000Z"), p.sequence))
+		if err := p.store(ctx, name, buf.Bytes()); err != nil {
+			log.Error().Err(err).Str("file", name).Msg("Failed to store Parquet file")
+			return static.NewErrorUtil("Failed to store Parquet file", "SINK_FLUSH_FAILED", err, err.Error())
+		}
+		log.Debug().Str("file", name).Int("rows", len(partition.rows)).Int("bytes", buf.Len()).Msg("Parquet file written")
+	}
+	return nil
+}
+
+// store grava o arquivo no bucket ou, localmente, como temporário com fsync e renomeação
+func (p *Parquet) store(ctx context.Context, name string, data []byte) error {
+	if p.s3 != nil {
+		object := name
+		if p.prefix != "" {
+			object = p.prefix + "/" + name
+		}
+		_, err := p.s3.PutObject(ctx, p.bucket, object, bytes.NewReader(data), int64(len(data)),
+			minio.PutObjectOptions{ContentType: "application/vnd.apache.parquet"})
+		return err
+	}
+
+	target := filepath.Join(p.dir, filepath.FromSlash(name))
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+func (p *Parquet) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+func (p *Parquet) HealthCheck(ctx context.Context) static.ErrorUtil {
+	if p.s3 == nil {
+		if _, err := os.Stat(p.dir); err != nil {
+			return static.NewErrorUtil("Parquet directory unavailable", "SINK_UNHEALTHY", err, err.Error())
+		}
+		return nil
+	}
+	exists, err := p.s3.BucketExists(ctx, p.bucket)
+	if err != nil {
+		return static.NewErrorUtil("S3 bucket unreachable", "SINK_UNHEALTHY", err, err.Error())
+	}
+	if !exists {
+		return static.NewErrorUtil("S3 bucket not found", "SINK_UNHEALTHY", nil, p.bucket)
+	}
+	return nil
+}
+
+func (p *Parquet) Close() static.ErrorUtil {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return p.Flush(ctx)
+}
@@ -0,0 +1,268 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/schema"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/minio/minio-go/v7"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// parquetTestFile caminho esperado: table=/date=/hour=/part-<instante>-<seq>.parquet
+var parquetTestFile = regexp.MustCompile(`^table=dbo\.Orders/date=2024-05-01/hour=(10|11)/part-\d{8}T\d{6}\.\d{3}Z-\d{6}\.parquet$`)
+
+// parquetTestSchema dbo.Orders com as colunas de tipos lógicos do Parquet
+var parquetTestSchema = ordersTestSchemaWith("00000000000000000001",
+	schema.Column{Name: "Amount", Type: "decimal", Precision: 10, Scale: 2, Captured: true},
+	schema.Column{Name: "Balance", Type: "numeric", Precision: 28, Scale: 4, Captured: true},
+	schema.Column{Name: "CreatedAt", Type: "datetime2", Captured: true},
+	schema.Column{Name: "PaidAt", Type: "datetimeoffset", Captured: true},
+	schema.Column{Name: "RowGuid", Type: "uniqueidentifier", Captured: true},
+)
+
+// parquetTestEvents duas linhas na hora 10 e um truncate na hora 11
+func parquetTestEvents() []event.Event {
+	at := time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)
+	row := func(id int64, amount, balance string) map[string]interface{} {
+		row := testRow(id, "new")
+		row["Amount"] = amount
+		row["Balance"] = balance
+		row["CreatedAt"] = time.Date(2024, 4, 30, 22, 0, 0, 123456000, time.UTC)
+		row["PaidAt"] = time.Date(2024, 5, 1, 7, 0, 0, 0, time.FixedZone("", -3*3600))
+		row["RowGuid"] = "6F9619FF-8B86-D011-B42D-00C04FC964FF"
+		return row
+	}
+	events := []event.Event{
+		testOrder(event.OpInsert, testPosition(16, 1), nil, row(1, "19.99", "-12345.6789")),
+		testOrder(event.OpInsert, testPosition(16, 2), nil, row(2, "0.005", "1")),
+		testOrder(event.OpTruncate, event.Position{}, nil, nil),
+	}
+	for i := range events {
+		events[i].TableSchema = parquetTestSchema
+		events[i].CommitTime = at
+	}
+	events[2].CommitTime = at.Add(time.Hour)
+	return events
+}
+
+func TestParquetLocalRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	p, errParquet := NewParquet(context.Background(), &config.Config{ParquetDir: dir})
+	if errParquet != nil {
+		t.Fatalf("NewParquet: %v", errParquet)
+	}
+	writeParquet(t, p)
+
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		data, err := os.ReadFile(path)
+		files[filepath.ToSlash(rel)] = data
+		return err
+	})
+	if err != nil {
+		t.Fatalf("walk: %v", err)
+	}
+	checkParquetFiles(t, files)
+}
+
+func TestParquetS3RoundTrip(t *testing.T) {
+	backend := s3mem.New()
+	if err := backend.CreateBucket("lake"); err != nil {
+		t.Fatalf("CreateBucket: %v", err)
+	}
+	srv := httptest.NewServer(gofakes3.New(backend).Server())
+	defer srv.Close()
+
+	ctx := context.Background()
+	p, errParquet := NewParquet(ctx, &config.Config{
+		ParquetS3Bucket:    "lake",
+		ParquetS3Prefix:    "/cdc/",
+		ParquetS3Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		ParquetS3Region:    "us-east-1",
+		ParquetS3AccessKey: "key",
+		ParquetS3SecretKey: "secret",
+		ParquetS3PathStyle: true,
+	})
+	if errParquet != nil {
+		t.Fatalf("NewParquet: %v", errParquet)
+	}
+	writeParquet(t, p)
+
+	files := make(map[string][]byte)
+	for object := range p.s3.ListObjects(ctx, "lake", minio.ListObjectsOptions{Recursive: true}) {
+		if object.Err != nil {
+			t.Fatalf("ListObjects: %v", object.Err)
+		}
+		if !strings.HasPrefix(object.Key, "cdc/") {
+			t.Errorf("object %s outside the prefix", object.Key)
+		}
+		reader, err := p.s3.GetObject(ctx, "lake", object.Key, minio.GetObjectOptions{})
+		if err != nil {
+			t.Fatalf("GetObject: %v", err)
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", object.Key, err)
+		}
+		files[strings.TrimPrefix(object.Key, "cdc/")] = data
+	}
+	checkParquetFiles(t, files)
+}
+
+func TestParquetMissingBucket(t *testing.T) {
+	srv := httptest.NewServer(gofakes3.New(s3mem.New()).Server())
+	defer srv.Close()
+
+	_, errParquet := NewParquet(context.Background(), &config.Config{
+		ParquetS3Bucket:    "missing",
+		ParquetS3Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		ParquetS3Region:    "us-east-1",
+		ParquetS3AccessKey: "key",
+		ParquetS3SecretKey: "secret",
+		ParquetS3PathStyle: true,
+	})
+	if errParquet == nil || errParquet.Code() != "SINK_UNHEALTHY" {
+		t.Fatalf("expected SINK_UNHEALTHY for missing bucket, got %v", errParquet)
+	}
+}
+
+func writeParquet(t *testing.T, p *Parquet) {
+	t.Helper()
+	ctx := context.Background()
+	if errWrite := p.Write(ctx, parquetTestEvents()); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if errFlush := p.Flush(ctx); errFlush != nil {
+		t.Fatalf("Flush: %v", errFlush)
+	}
+}
+
+// checkParquetFiles valida o layout das partições, os tipos lógicos e os valores lidos de volta
+func checkParquetFiles(t *testing.T, files map[string][]byte) {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) != 2 {
+		t.Fatalf("files = %v, want one per hour partition", names)
+	}
+	for _, name := range names {
+		if !parquetTestFile.MatchString(name) {
+			t.Errorf("unexpected file path %s", name)
+		}
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(files[names[0]]), int64(len(files[names[0]])))
+	if err != nil {
+		t.Fatalf("OpenFile %s: %v", names[0], err)
+	}
+	logical := func(column string) format.LogicalTypeValue {
+		leaf, ok := f.Schema().Lookup(column)
+		if !ok {
+			t.Fatalf("column %s not found", column)
+		}
+		if lt := leaf.Node.Type().LogicalType(); lt != nil {
+			return lt.Value
+		}
+		return nil
+	}
+	if dec, ok := logical("Amount").(*format.DecimalType); !ok || dec.Precision != 10 || dec.Scale != 2 {
+		t.Errorf("Amount logical type = %v", logical("Amount"))
+	}
+	if dec, ok := logical("Balance").(*format.DecimalType); !ok || dec.Precision != 28 || dec.Scale != 4 {
+		t.Errorf("Balance logical type = %v", logical("Balance"))
+	}
+	// datetime2 é valor de relógio (sem ajuste para UTC); datetimeoffset é um instante
+	if ts, ok := logical("CreatedAt").(*format.TimestampType); !ok || ts.String() != "TIMESTAMP(isAdjustedToUTC=false,unit=MICROS)" {
+		t.Errorf("CreatedAt logical type = %v", logical("CreatedAt"))
+	}
+	if ts, ok := logical("PaidAt").(*format.TimestampType); !ok || ts.String() != "TIMESTAMP(isAdjustedToUTC=true,unit=MICROS)" {
+		t.Errorf("PaidAt logical type = %v", logical("PaidAt"))
+	}
+	if _, ok := logical("RowGuid").(*format.UUIDType); !ok {
+		t.Errorf("RowGuid logical type = %v", logical("RowGuid"))
+	}
+
+	rows := readParquetRows(t, f)
+	if len(rows) != 2 {
+		t.Fatalf("read %d rows, want 2", len(rows))
+	}
+	first, second := rows[0], rows[1]
+	if got := string(first[ParquetColumnOp].ByteArray()); got != "insert" || first["Id"].Int32() != 1 {
+		t.Errorf("first row op = %s, id = %v", got, first["Id"])
+	}
+	if first["Amount"].Int64() != 1999 || second["Amount"].Int64() != 1 {
+		t.Errorf("Amount unscaled = %d, %d", first["Amount"].Int64(), second["Amount"].Int64())
+	}
+	balance := new(big.Int).SetBytes(first["Balance"].ByteArray())
+	balance.Sub(balance, new(big.Int).Lsh(big.NewInt(1), 128))
+	if balance.Int64() != -123456789 {
+		t.Errorf("Balance unscaled = %s", balance)
+	}
+	if got := first["CreatedAt"].Int64(); got != time.Date(2024, 4, 30, 22, 0, 0, 123456000, time.UTC).UnixMicro() {
+		t.Errorf("CreatedAt = %d", got)
+	}
+	if got := first["PaidAt"].Int64(); got != time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC).UnixMicro() {
+		t.Errorf("PaidAt = %d", got)
+	}
+	if got := first["RowGuid"].ByteArray(); len(got) != 16 || got[0] != 0x6f || got[15] != 0xff {
+		t.Errorf("RowGuid = %x", got)
+	}
+
+	g, err := parquet.OpenFile(bytes.NewReader(files[names[1]]), int64(len(files[names[1]])))
+	if err != nil {
+		t.Fatalf("OpenFile %s: %v", names[1], err)
+	}
+	truncate := readParquetRows(t, g)
+	if len(truncate) != 1 || string(truncate[0][ParquetColumnOp].ByteArray()) != "truncate" || !truncate[0]["Id"].IsNull() {
+		t.Errorf("truncate rows = %v", truncate)
+	}
+}
+
+// readParquetRows lê as linhas do arquivo indexadas pelo nome da coluna
+func readParquetRows(t *testing.T, f *parquet.File) []map[string]parquet.Value {
+	t.Helper()
+	columns := f.Schema().Columns()
+	var out []map[string]parquet.Value
+	for _, rowGroup := range f.RowGroups() {
+		rows := rowGroup.Rows()
+		buf := make([]parquet.Row, rowGroup.NumRows())
+		n, err := rows.ReadRows(buf)
+		rows.Close()
+		if err != nil && err != io.EOF {
+			t.Fatalf("ReadRows: %v", err)
+		}
+		for _, row := range buf[:n] {
+			values := make(map[string]parquet.Value, len(row))
+			for _, value := range row {
+				values[columns[value.Column()][0]] = value
+			}
+			out = append(out, values)
+		}
+	}
+	return out
+}
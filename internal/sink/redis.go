@@ -0,0 +1,242 @@
+// internal/sink/redis.go
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// redisGlobEscaper escapa os curingas de SCAN MATCH nos nomes usados no padrão
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// Redis grava cada evento com XADD no stream da tabela (com MAXLEN) e, se
+// APP_GO_CDC_REDIS_HASH_TEMPLATE estiver definido, espelha o estado atual de
+// cada linha em um hash por chave primária. Cada lote é enviado em um pipeline
+// e Write só retorna após as respostas do servidor.
+type Redis struct {
+	client         *redis.Client
+	streamTemplate string
+	hashTemplate   string
+	maxLen         int64
+	approx         bool
+}
+
+// NewRedis cria o sink (construtor)
+func NewRedis(cfg *config.Config) (*Redis, static.ErrorUtil) {
+	if cfg.RedisURL == "" {
+		return nil, static.NewErrorUtil("Redis URL not configured", "REDIS_CONFIG_INVALID", nil, "APP_GO_CDC_REDIS_URL")
+	}
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		log.Error().Caller().Err(err).Msg("Invalid Redis URL")
+		return nil, static.NewErrorUtil("Invalid Redis URL", "REDIS_CONFIG_INVALID", err, err.Error())
+	}
+	if cfg.RedisTLS {
+		tlsConfig, err := buildTLSConfig(cfg.RedisTLSCAFile, cfg.RedisTLSCertFile, cfg.RedisTLSKeyFile, cfg.RedisTLSInsecureSkipVerify)
+		if err != nil {
+			log.Error().Caller().Err(err).Msg("Failed to load Redis TLS configuration")
+			return nil, static.NewErrorUtil("Failed to load Redis TLS configuration", "REDIS_CONFIG_INVALID", err, err.Error())
+		}
+		opts.TLSConfig = tlsConfig
+	}
+	if opts.ClientName == "" {
+		opts.ClientName = cfg.AppName
+	}
+
+	r := &Redis{
+		client:         redis.NewClient(opts),
+		streamTemplate: cfg.RedisStreamTemplate,
+		hashTemplate:   cfg.RedisHashTemplate,
+		maxLen:         int64(cfg.RedisStreamMaxLen),
+		approx:         cfg.RedisStreamMaxLenApprox,
+	}
+	if r.streamTemplate == "" {
+		r.streamTemplate = static.APP_GO_CDC_REDIS_STREAM_TEMPLATE
+	}
+	return r, nil
+}
+
+func (r *Redis) Name() string {
+	return TypeRedis
+}
+
+// Stream resolve o template ({db}, {schema}, {table}, {capture_instance}) para o evento
+func (r *Redis) Stream(evt *event.Event) string {
+	return resolveTableTemplate(r.streamTemplate, evt, nil)
+}
+
+// hashKey chave do hash da linha: template com {key} = valores das colunas
+// chave de row separados por ':'
+func (r *Redis) hashKey(evt *event.Event, row map[string]interface{}) (string, bool) {
+	if r.hashTemplate == "" || len(evt.KeyColumns) == 0 || row == nil {
+		return "", false
+	}
+	values := make([]string, len(evt.KeyColumns))
+	for i, column := range evt.KeyColumns {
+		values[i] = redisValue(row[column])
+	}
+	return strings.ReplaceAll(resolveTableTemplate(r.hashTemplate, evt, nil), "{key}", strings.Join(values, ":")), true
+}
+
+// redisValue converte o valor da coluna para o texto gravado no Redis
+func redisValue(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Sprint(value)
+	}
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(encoded)
+}
+
+func (r *Redis) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	pipe := r.client.Pipeline()
+	for i := range events {
+		evt := &events[i]
+		data, err := json.Marshal(evt)
+		if err != nil {
+			return static.NewErrorUtil("Failed to encode event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+
+		args := &redis.XAddArgs{
+			Stream: r.Stream(evt),
+			Approx: r.approx,
+			Values: []interface{}{
+				"op", string(evt.Op),
+				"lsn", evt.Position.LSN,
+				"seqval", evt.Position.SeqVal,
+				"key", string(evt.MessageKey()),
+				"event", data,
+			},
+		}
+		if r.maxLen > 0 {
+			args.MaxLen = r.maxLen
+		}
+		pipe.XAdd(ctx, args)
+
+		if evt.Op == event.OpTruncate && r.hashTemplate != "" {
+			// as alterações anteriores precisam estar gravadas antes da remoção
+			if errExec := r.exec(ctx, pipe); errExec != nil {
+				return errExec
+			}
+			if errTruncate := r.removeTableHashes(ctx, evt); errTruncate != nil {
+				return errTruncate
+			}
+			continue
+		}
+		r.mirror(ctx, pipe, evt)
+	}
+	return r.exec(ctx, pipe)
+}
+
+func (r *Redis) exec(ctx context.Context, pipe redis.Pipeliner) static.ErrorUtil {
+	if pipe.Len() == 0 {
+		return nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Error().Err(err).Msg("Redis pipeline failed")
+		return static.NewErrorUtil("Failed to write events to Redis", "SINK_WRITE_FAILED", err, err.Error())
+	}
+	return nil
+}
+
+// mirror aplica o evento ao hash da linha: insert e snapshot substituem o hash,
+// update altera apenas as colunas recebidas (LOBs não alterados ficam como
+// estão) e delete remove a chave. Um update que altera a chave primária remove
+// o hash da chave antiga. Colunas NULL não são gravadas no hash.
+func (r *Redis) mirror(ctx context.Context, pipe redis.Pipeliner, evt *event.Event) {
+	key, ok := r.hashKey(evt, evt.Key)
+	if !ok {
+		return
+	}
+	if evt.Op == event.OpUpdate {
+		if oldKey, ok := r.hashKey(evt, evt.Before); ok && oldKey != key {
+			pipe.Del(ctx, oldKey)
+		}
+	}
+
+	switch evt.Op {
+	case event.OpInsert, event.OpRead, event.OpUpdate:
+		fields := make([]interface{}, 0, len(evt.After)*2)
+		var nulls []string
+		for column, value := range evt.After {
+			if value == nil {
+				nulls = append(nulls, column)
+				continue
+			}
+			fields = append(fields, column, redisValue(value))
+		}
+		if evt.Op != event.OpUpdate {
+			pipe.Del(ctx, key)
+		} else if len(nulls) > 0 {
+			pipe.HDel(ctx, key, nulls...)
+		}
+		if len(fields) > 0 {
+			pipe.HSet(ctx, key, fields...)
+		}
+	case event.OpDelete:
+		pipe.Del(ctx, key)
+	}
+}
+
+// removeTableHashes remove os hashes da tabela truncada (SCAN + UNLINK)
+func (r *Redis) removeTableHashes(ctx context.Context, truncate *event.Event) static.ErrorUtil {
+	pattern := strings.ReplaceAll(resolveTableTemplate(redisGlobEscaper.Replace(r.hashTemplate), truncate, redisGlobEscaper.Replace), `{key}`, "*")
+	iter := r.client.Scan(ctx, 0, pattern, 1000).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return static.NewErrorUtil("Failed to scan Redis hashes", "SINK_WRITE_FAILED", err, err.Error())
+	}
+	for start := 0; start < len(keys); start += 1000 {
+		if err := r.client.Unlink(ctx, keys[start:min(start+1000, len(keys))]...).Err(); err != nil {
+			return static.NewErrorUtil("Failed to remove Redis hashes", "SINK_WRITE_FAILED", err, err.Error())
+		}
+	}
+	log.Info().Str("table", truncate.FullTableName()).Int("keys", len(keys)).Msg("Redis hashes removed after truncate")
+	return nil
+}
+
+func (r *Redis) Flush(ctx context.Context) static.ErrorUtil {
+	return nil
+}
+
+func (r *Redis) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+func (r *Redis) HealthCheck(ctx context.Context) static.ErrorUtil {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return static.NewErrorUtil("Redis unreachable", "SINK_UNHEALTHY", err, err.Error())
+	}
+	return nil
+}
+
+func (r *Redis) Close() static.ErrorUtil {
+	if err := r.client.Close(); err != nil {
+		return static.NewErrorUtil("Failed to close Redis client", "SINK_CLOSE_FAILED", err, err.Error())
+	}
+	return nil
+}
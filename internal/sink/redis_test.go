@@ -0,0 +1,130 @@
+package sink
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newRedisTest(t *testing.T, cfg *config.Config) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	cfg.RedisURL = "redis://" + mr.Addr()
+	r, errRedis := NewRedis(cfg)
+	if errRedis != nil {
+		t.Fatalf("NewRedis: %v", errRedis)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r, mr
+}
+
+func TestRedisStreamMaxLen(t *testing.T) {
+	r, mr := newRedisTest(t, &config.Config{RedisStreamMaxLen: 3})
+
+	var events []event.Event
+	for id := int64(1); id <= 5; id++ {
+		events = append(events, testOrder(event.OpInsert, event.Position{}, nil, map[string]interface{}{"Id": id}))
+	}
+	if errWrite := r.Write(context.Background(), events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	entries, err := mr.Stream("cdc:shop:dbo:Orders")
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("stream has %d entries, want 3", len(entries))
+	}
+	// MAXLEN descarta as entradas mais antigas
+	if entries[0].Values[0] != "op" || entries[0].Values[7] != `{"Id":3}` {
+		t.Errorf("oldest entry = %v", entries[0].Values)
+	}
+}
+
+func TestRedisMirrorsRows(t *testing.T) {
+	r, mr := newRedisTest(t, &config.Config{RedisHashTemplate: "row:{schema}.{table}:{key}"})
+	ctx := context.Background()
+
+	write := func(evt event.Event) {
+		t.Helper()
+		if errWrite := r.Write(ctx, []event.Event{evt}); errWrite != nil {
+			t.Fatalf("Write: %v", errWrite)
+		}
+	}
+
+	write(testOrder(event.OpInsert, event.Position{}, nil, map[string]interface{}{"Id": int64(1), "Status": "new", "Note": "x"}))
+	if got := mr.HGet("row:dbo.Orders:1", "Status"); got != "new" {
+		t.Fatalf("Status after insert = %q", got)
+	}
+
+	// update parcial: colunas ausentes são mantidas, NULL remove o campo
+	write(testOrder(event.OpUpdate, event.Position{},
+		map[string]interface{}{"Id": int64(1), "Status": "new", "Note": "x"},
+		map[string]interface{}{"Id": int64(1), "Status": "paid", "Note": nil}))
+	if got, _ := mr.HKeys("row:dbo.Orders:1"); len(got) != 2 || mr.HGet("row:dbo.Orders:1", "Status") != "paid" {
+		t.Fatalf("hash after update = %v", got)
+	}
+
+	// update da chave primária move o hash
+	write(testOrder(event.OpUpdate, event.Position{},
+		map[string]interface{}{"Id": int64(1), "Status": "paid"},
+		map[string]interface{}{"Id": int64(2), "Status": "paid"}))
+	if mr.Exists("row:dbo.Orders:1") {
+		t.Error("hash of the old primary key not removed")
+	}
+	if got := mr.HGet("row:dbo.Orders:2", "Status"); got != "paid" {
+		t.Errorf("Status under new key = %q", got)
+	}
+
+	write(testOrder(event.OpDelete, event.Position{}, map[string]interface{}{"Id": int64(2), "Status": "paid"}, nil))
+	if mr.Exists("row:dbo.Orders:2") {
+		t.Error("hash not removed on delete")
+	}
+}
+
+func TestRedisTruncateRemovesTableHashes(t *testing.T) {
+	r, mr := newRedisTest(t, &config.Config{RedisHashTemplate: "row:{schema}.{table}:{key}"})
+	ctx := context.Background()
+
+	events := []event.Event{
+		testOrder(event.OpInsert, event.Position{}, nil, map[string]interface{}{"Id": int64(1)}),
+		testOrder(event.OpInsert, event.Position{}, nil, map[string]interface{}{"Id": int64(2)}),
+		testEvent(event.OpInsert, "Orders*", event.Position{}, nil, map[string]interface{}{"Id": int64(1)}),
+		testEvent(event.OpInsert, "Customers", event.Position{}, nil, map[string]interface{}{"Id": int64(1)}),
+		testOrder(event.OpTruncate, event.Position{}, nil, nil),
+		// alterações após o truncate no mesmo lote são preservadas
+		testOrder(event.OpInsert, event.Position{}, nil, map[string]interface{}{"Id": int64(3)}),
+	}
+	if errWrite := r.Write(ctx, events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	keys := mr.Keys()
+	for _, key := range []string{"row:dbo.Orders:1", "row:dbo.Orders:2"} {
+		if slices.Contains(keys, key) {
+			t.Errorf("%s not removed by truncate", key)
+		}
+	}
+	for _, key := range []string{"row:dbo.Orders:3", "row:dbo.Orders*:1", "row:dbo.Customers:1"} {
+		if !slices.Contains(keys, key) {
+			t.Errorf("%s removed by truncate", key)
+		}
+	}
+	if entries, _ := mr.Stream("cdc:shop:dbo:Orders"); len(entries) != 4 || entries[2].Values[1] != string(event.OpTruncate) {
+		t.Errorf("stream entries = %v", entries)
+	}
+
+	// curingas no nome da tabela são escapados no padrão do SCAN
+	if errWrite := r.Write(ctx, []event.Event{testEvent(event.OpTruncate, "Orders*", event.Position{}, nil, nil)}); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+	if mr.Exists("row:dbo.Orders*:1") || !mr.Exists("row:dbo.Orders:3") {
+		t.Errorf("keys after escaped truncate = %v", mr.Keys())
+	}
+}
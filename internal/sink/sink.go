@@ -0,0 +1,116 @@
+// internal/sink/sink.go
+package sink
+
+import (
+	"context"
+	"strings"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Sink destino dos eventos. O pipeline chama Write com cada lote (em ordem de
+// posição) e em seguida Flush; as posições só são confirmadas na origem quando
+// Flush de todos os sinks retorna sem erro, então a entrega é at-least-once e
+// um lote pode ser reenviado após falhas.
+type Sink interface {
+	Name() string
+	// Write recebe um lote; pode apenas enfileirar/bufferizar
+	Write(ctx context.Context, events []event.Event) static.ErrorUtil
+	// Flush retorna quando tudo que foi escrito foi confirmado pelo destino
+	Flush(ctx context.Context) static.ErrorUtil
+	// Ack informa as posições já persistidas no offset store
+	Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil
+	HealthCheck(ctx context.Context) static.ErrorUtil
+	Close() static.ErrorUtil
+}
+
+// Tipos de sink aceitos em APP_GO_CDC_SINKS
+const (
+	TypeStdout        = "stdout"
+	TypeKafka         = "kafka"
+	TypeNATS          = "nats"
+	TypeRedis         = "redis"
+	TypeWebhook       = "webhook"
+	TypeFile          = "file"
+	TypeParquet       = "parquet"
+	TypeApply         = "apply"
+	TypeElasticsearch = "elasticsearch"
+	TypeClickHouse    = "clickhouse"
+	TypeGRPC          = "grpc"
+)
+
+// NewFromConfig cria os sinks listados em APP_GO_CDC_SINKS (separados por vírgula)
+func NewFromConfig(ctx context.Context, cfg *config.Config) ([]Sink, static.ErrorUtil) {
+	var sinks []Sink
+	for _, name := range config.SplitList(strings.ToLower(cfg.Sinks)) {
+		var s Sink
+		var errSink static.ErrorUtil
+
+		switch name {
+		case TypeStdout:
+			s = NewStdout()
+		case TypeKafka:
+			s, errSink = NewKafka(cfg)
+		case TypeNATS:
+			s, errSink = NewNATS(ctx, cfg)
+		case TypeRedis:
+			s, errSink = NewRedis(cfg)
+		case TypeWebhook:
+			s, errSink = NewWebhook(cfg)
+		case TypeFile:
+			s, errSink = NewFile(cfg)
+		case TypeParquet:
+			s, errSink = NewParquet(ctx, cfg)
+		case TypeApply:
+			s, errSink = NewApply(ctx, cfg)
+		case TypeElasticsearch, "opensearch":
+			s, errSink = NewElasticsearch(cfg)
+		case TypeClickHouse:
+			s, errSink = NewClickHouse(cfg)
+		case TypeGRPC:
+			s, errSink = NewGRPC(cfg)
+		default:
+			log.Error().Caller().Str("sink", name).Msg("Unsupported sink type")
+			errSink = static.NewErrorUtil("Unsupported sink type", "SINK_UNSUPPORTED", nil, name)
+		}
+
+		if errSink != nil {
+			closeAll(sinks)
+			return nil, errSink
+		}
+		log.Info().Str("sink", s.Name()).Msg("Sink initialized")
+		sinks = append(sinks, s)
+	}
+
+	if len(sinks) == 0 {
+		log.Error().Caller().Msg("No sink configured")
+		return nil, static.NewErrorUtil("No sink configured", "SINK_NOT_CONFIGURED", nil, "APP_GO_CDC_SINKS")
+	}
+	return sinks, nil
+}
+
+// resolveTableTemplate substitui os marcadores de tabela no template, aplicando
+// escape aos nomes quando informado
+func resolveTableTemplate(template string, evt *event.Event, escape func(string) string) string {
+	if escape == nil {
+		escape = func(s string) string { return s }
+	}
+	return strings.NewReplacer(
+		"{db}", escape(evt.Database),
+		"{schema}", escape(evt.Schema),
+		"{table}", escape(evt.Table),
+		"{capture_instance}", escape(evt.CaptureInstance),
+	).Replace(template)
+}
+
+func closeAll(sinks []Sink) {
+	for _, s := range sinks {
+		if errClose := s.Close(); errClose != nil {
+			log.Error().Err(errClose).Str("sink", s.Name()).Msg("Failed to close sink")
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package sink
+
+import (
+	"fmt"
+
+	"go-cdc/internal/event"
+	"go-cdc/internal/schema"
+)
+
+// ordersTestSchema schema de dbo.Orders usado nos testes dos sinks: chave Id,
+// uma coluna curta, um LOB e uma coluna computada
+var ordersTestSchema = &schema.TableSchema{
+	Table:     "dbo.Orders",
+	ValidFrom: "00000000000000000001",
+	Columns: []schema.Column{
+		{Name: "Id", Type: "int", Ordinal: 1, Captured: true},
+		{Name: "Status", Type: "nvarchar", MaxLength: 20, Ordinal: 2, Captured: true, Nullable: true},
+		{Name: "Notes", Type: "nvarchar", MaxLength: -1, Ordinal: 3, Captured: true, Nullable: true},
+		{Name: "Total", Type: "int", Ordinal: 4, Captured: true, Nullable: true, Computed: true},
+	},
+}
+
+// ordersTestSchemaWith nova versão de ordersTestSchema com as colunas extras no fim
+func ordersTestSchemaWith(validFrom string, extra ...schema.Column) *schema.TableSchema {
+	version := *ordersTestSchema
+	version.ValidFrom = validFrom
+	version.Columns = append([]schema.Column(nil), ordersTestSchema.Columns...)
+	for _, col := range extra {
+		col.Ordinal = len(version.Columns) + 1
+		version.Columns = append(version.Columns, col)
+	}
+	return &version
+}
+
+// testLSN LSN ou seqval n em hex com largura fixa
+func testLSN(n int) string {
+	return fmt.Sprintf("%020x", n)
+}
+
+// testPosition posição (lsn, seq) do log
+func testPosition(lsn, seq int) event.Position {
+	return event.Position{LSN: testLSN(lsn), SeqVal: testLSN(seq)}
+}
+
+// testRow imagem de linha com a chave Id
+func testRow(id int64, status string) map[string]interface{} {
+	return map[string]interface{}{"Id": id, "Status": status}
+}
+
+// testEvent evento da tabela dbo.<table> do banco shop. A chave Id vem das
+// imagens; eventos sem linha (truncate, schema) ficam sem chave.
+func testEvent(op event.Operation, table string, position event.Position, before, after map[string]interface{}) event.Event {
+	evt := event.Event{
+		Op:       op,
+		Database: "shop",
+		Schema:   "dbo",
+		Table:    table,
+		Position: position,
+		Before:   before,
+		After:    after,
+	}
+	if before != nil || after != nil {
+		evt.SetKey([]string{"Id"})
+	}
+	return evt
+}
+
+// testOrder evento de dbo.Orders com ordersTestSchema
+func testOrder(op event.Operation, position event.Position, before, after map[string]interface{}) event.Event {
+	evt := testEvent(op, "Orders", position, before, after)
+	evt.TableSchema = ordersTestSchema
+	return evt
+}
@@ -0,0 +1,63 @@
+// internal/sink/stdout.go
+package sink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"go-cdc/internal/event"
+	"go-cdc/static"
+)
+
+// Stdout escreve cada evento como uma linha JSON na saída padrão
+type Stdout struct {
+	mu     sync.Mutex
+	writer *bufio.Writer
+}
+
+// NewStdout cria o sink (construtor)
+func NewStdout() *Stdout {
+	return &Stdout{writer: bufio.NewWriter(os.Stdout)}
+}
+
+func (s *Stdout) Name() string {
+	return TypeStdout
+}
+
+func (s *Stdout) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	encoder := json.NewEncoder(s.writer)
+	for i := range events {
+		if err := encoder.Encode(&events[i]); err != nil {
+			return static.NewErrorUtil("Failed to write event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+	}
+	return nil
+}
+
+func (s *Stdout) Flush(ctx context.Context) static.ErrorUtil {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writer.Flush(); err != nil {
+		return static.NewErrorUtil("Failed to flush events", "SINK_FLUSH_FAILED", err, err.Error())
+	}
+	return nil
+}
+
+func (s *Stdout) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+func (s *Stdout) HealthCheck(ctx context.Context) static.ErrorUtil {
+	return nil
+}
+
+func (s *Stdout) Close() static.ErrorUtil {
+	return s.Flush(context.Background())
+}
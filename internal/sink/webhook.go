@@ -0,0 +1,320 @@
+// internal/sink/webhook.go
+package sink
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/retry"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Cabeçalhos enviados em cada requisição do webhook
+const (
+	WebhookHeaderTable     = "X-Go-Cdc-Table"
+	WebhookHeaderCount     = "X-Go-Cdc-Count"
+	WebhookHeaderFirstPos  = "X-Go-Cdc-First-Position"
+	WebhookHeaderLastPos   = "X-Go-Cdc-Last-Position"
+	WebhookSignaturePrefix = "sha256="
+)
+
+// Webhook envia os eventos em POSTs JSON ({"events": [...]}) para a URL
+// configurada. Os eventos são agrupados por tabela: cada tabela é enviada em
+// ordem, em requisições de até APP_GO_CDC_WEBHOOK_MAX_BATCH_BYTES, e tabelas
+// diferentes são enviadas em paralelo até APP_GO_CDC_WEBHOOK_CONCURRENCY
+// requisições simultâneas. Write só retorna após todas as respostas 2xx.
+type Webhook struct {
+	client          *http.Client
+	url             string
+	headers         map[string]string
+	tableHeaders    map[string]map[string]string // schema.tabela em minúsculas -> cabeçalhos
+	secret          []byte
+	signatureHeader string
+	maxBatchBytes   int
+	maxAttempts     int
+	maxBackoff      time.Duration
+	backoff         retry.Backoff
+	slots           chan struct{}
+
+	mu      sync.Mutex
+	lastErr static.ErrorUtil // resultado da última entrega, usado no health check
+}
+
+// NewWebhook cria o sink (construtor)
+func NewWebhook(cfg *config.Config) (*Webhook, static.ErrorUtil) {
+	if cfg.WebhookURL == "" {
+		return nil, static.NewErrorUtil("Webhook URL not configured", "WEBHOOK_CONFIG_INVALID", nil, "APP_GO_CDC_WEBHOOK_URL")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.WebhookTLSCAFile != "" || cfg.WebhookTLSCertFile != "" || cfg.WebhookTLSInsecureSkipVerify {
+		tlsConfig, err := buildTLSConfig(cfg.WebhookTLSCAFile, cfg.WebhookTLSCertFile, cfg.WebhookTLSKeyFile, cfg.WebhookTLSInsecureSkipVerify)
+		if err != nil {
+			log.Error().Caller().Err(err).Msg("Failed to load webhook TLS configuration")
+			return nil, static.NewErrorUtil("Failed to load webhook TLS configuration", "WEBHOOK_CONFIG_INVALID", err, err.Error())
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	timeout := time.Duration(cfg.WebhookTimeoutMs) * time.Millisecond
+	if timeout <= 0 {
+		timeout = static.APP_GO_CDC_WEBHOOK_TIMEOUT_MS * time.Millisecond
+	}
+	concurrency := cfg.WebhookConcurrency
+	if concurrency <= 0 {
+		concurrency = static.APP_GO_CDC_WEBHOOK_CONCURRENCY
+	}
+	maxBatchBytes := cfg.WebhookMaxBatchBytes
+	if maxBatchBytes <= 0 {
+		maxBatchBytes = static.APP_GO_CDC_WEBHOOK_MAX_BATCH_BYTES
+	}
+	maxAttempts := max(cfg.WebhookRetryMaxAttempts, 1)
+	initial := time.Duration(max(cfg.WebhookRetryInitialBackoffMs, 1)) * time.Millisecond
+	maxBackoff := max(time.Duration(cfg.WebhookRetryMaxBackoffMs)*time.Millisecond, initial)
+
+	w := &Webhook{
+		client:          &http.Client{Transport: transport, Timeout: timeout},
+		url:             cfg.WebhookURL,
+		headers:         parseHeaders(config.ParseTableMap(cfg.WebhookHeaders)),
+		tableHeaders:    make(map[string]map[string]string),
+		secret:          []byte(cfg.WebhookSecret),
+		signatureHeader: cfg.WebhookSignatureHeader,
+		maxBatchBytes:   maxBatchBytes,
+		maxAttempts:     maxAttempts,
+		maxBackoff:      maxBackoff,
+		backoff:         retry.NewBackoff(initial, maxBackoff),
+		slots:           make(chan struct{}, concurrency),
+	}
+	if w.signatureHeader == "" {
+		w.signatureHeader = static.APP_GO_CDC_WEBHOOK_SIGNATURE_HEADER
+	}
+
+	// cabeçalhos por tabela: schema.tabela=Nome:valor,Nome:valor;...
+	for table, raw := range config.ParseTableMap(cfg.WebhookTableHeaders) {
+		headers := make(map[string]string)
+		for _, item := range config.SplitList(raw) {
+			name, value, found := strings.Cut(item, ":")
+			if !found || strings.TrimSpace(name) == "" {
+				return nil, static.NewErrorUtil("Invalid webhook table header", "WEBHOOK_CONFIG_INVALID", nil, table+": "+item)
+			}
+			headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+		}
+		w.tableHeaders[table] = headers
+	}
+	return w, nil
+}
+
+// parseHeaders converte o mapa Nome=valor (chaves em minúsculas) para a forma canônica
+func parseHeaders(raw map[string]string) map[string]string {
+	headers := make(map[string]string, len(raw))
+	for name, value := range raw {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	return headers
+}
+
+func (w *Webhook) Name() string {
+	return TypeWebhook
+}
+
+// webhookRequest eventos de uma tabela que cabem em uma requisição
+type webhookRequest struct {
+	table  string
+	events []json.RawMessage
+	first  event.Position
+	last   event.Position
+}
+
+func (w *Webhook) Write(ctx context.Context, events []event.Event) static.ErrorUtil {
+	var tables []string
+	requests := make(map[string][]*webhookRequest)
+	sizes := make(map[string]int)
+	for i := range events {
+		evt := &events[i]
+		data, err := json.Marshal(evt)
+		if err != nil {
+			return static.NewErrorUtil("Failed to encode event", "SINK_WRITE_FAILED", err, err.Error())
+		}
+
+		table := strings.ToLower(evt.FullTableName())
+		queue, known := requests[table]
+		if !known {
+			tables = append(tables, table)
+		}
+		if len(queue) == 0 || sizes[table]+len(data)+1 > w.maxBatchBytes {
+			// evento maior que o limite vai sozinho em uma requisição
+			if len(data) > w.maxBatchBytes {
+				log.Warn().Str("table", table).Int("bytes", len(data)).Msg("Event larger than webhook max batch bytes, sending alone")
+			}
+			queue = append(queue, &webhookRequest{table: evt.FullTableName(), first: evt.Position})
+			sizes[table] = len(`{"events":[]}`)
+		}
+		current := queue[len(queue)-1]
+		current.events = append(current.events, data)
+		current.last = evt.Position
+		sizes[table] += len(data) + 1
+		requests[table] = queue
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var errFirst static.ErrorUtil
+	for _, table := range tables {
+		wg.Add(1)
+		go func(queue []*webhookRequest) {
+			defer wg.Done()
+			for _, req := range queue {
+				if errSend := w.send(ctx, req); errSend != nil {
+					once.Do(func() {
+						errFirst = errSend
+						cancel()
+					})
+					return
+				}
+			}
+		}(requests[table])
+	}
+	wg.Wait()
+
+	w.mu.Lock()
+	w.lastErr = errFirst
+	w.mu.Unlock()
+	return errFirst
+}
+
+// send envia uma requisição, repetindo em erros de rede, 5xx e 429. Retry-After
+// (segundos ou data HTTP) tem precedência sobre o backoff calculado, limitado a
+// APP_GO_CDC_WEBHOOK_RETRY_MAX_BACKOFF_MS.
+func (w *Webhook) send(ctx context.Context, req *webhookRequest) static.ErrorUtil {
+	body, err := json.Marshal(map[string][]json.RawMessage{"events": req.events})
+	if err != nil {
+		return static.NewErrorUtil("Failed to encode webhook body", "SINK_WRITE_FAILED", err, err.Error())
+	}
+
+	var errSend error
+	for attempt := 0; ; attempt++ {
+		select {
+		case w.slots <- struct{}{}:
+		case <-ctx.Done():
+			return static.NewErrorUtil("Webhook delivery cancelled", "SINK_WRITE_FAILED", ctx.Err(), ctx.Err().Error())
+		}
+		status, retryAfter, err := w.post(ctx, req, body)
+		<-w.slots
+
+		if err == nil && status < 300 {
+			return nil
+		}
+		retryable := err != nil || status == http.StatusTooManyRequests || status >= 500
+		if err == nil {
+			err = fmt.Errorf("webhook responded with status %d", status)
+		}
+		errSend = err
+		if !retryable || attempt+1 >= w.maxAttempts || ctx.Err() != nil {
+			break
+		}
+
+		wait := w.backoff.Duration(attempt)
+		if retryAfter > 0 {
+			wait = min(retryAfter, w.maxBackoff)
+		}
+		log.Warn().
+			Err(err).
+			Str("table", req.table).
+			Int("attempt", attempt+1).
+			Int("max_attempts", w.maxAttempts).
+			Dur("backoff", wait).
+			Msg("Webhook delivery failed, retrying")
+		if errSleep := retry.Sleep(ctx, wait); errSleep != nil {
+			break
+		}
+	}
+
+	log.Error().Err(errSend).Str("table", req.table).Int("events", len(req.events)).Msg("Webhook delivery failed")
+	return static.NewErrorUtil("Webhook delivery failed", "SINK_WRITE_FAILED", errSend, errSend.Error())
+}
+
+// post executa uma tentativa e retorna o status e a espera pedida em Retry-After
+func (w *Webhook) post(ctx context.Context, req *webhookRequest, body []byte) (int, time.Duration, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", static.APP_GO_CDC_NAME)
+	for name, value := range w.headers {
+		httpReq.Header.Set(name, value)
+	}
+	for name, value := range w.tableHeaders[strings.ToLower(req.table)] {
+		httpReq.Header.Set(name, value)
+	}
+	httpReq.Header.Set(WebhookHeaderTable, req.table)
+	httpReq.Header.Set(WebhookHeaderCount, strconv.Itoa(len(req.events)))
+	httpReq.Header.Set(WebhookHeaderFirstPos, req.first.String())
+	httpReq.Header.Set(WebhookHeaderLastPos, req.last.String())
+	if len(w.secret) > 0 {
+		mac := hmac.New(sha256.New, w.secret)
+		mac.Write(body)
+		httpReq.Header.Set(w.signatureHeader, WebhookSignaturePrefix+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(httpReq)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	// descarta o corpo para reaproveitar a conexão
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")), nil
+}
+
+// parseRetryAfter interpreta Retry-After em segundos ou como data HTTP
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+func (w *Webhook) Flush(ctx context.Context) static.ErrorUtil {
+	return nil
+}
+
+func (w *Webhook) Ack(ctx context.Context, positions map[string]event.Position) static.ErrorUtil {
+	return nil
+}
+
+// HealthCheck reporta a falha da última entrega (o destino não tem endpoint de saúde)
+func (w *Webhook) HealthCheck(ctx context.Context) static.ErrorUtil {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+func (w *Webhook) Close() static.ErrorUtil {
+	w.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,251 @@
+package sink
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+)
+
+// webhookCall requisição recebida pelo servidor de teste
+type webhookCall struct {
+	header http.Header
+	body   []byte
+	events []event.Event
+}
+
+// webhookServer servidor de teste que registra as requisições. respond retorna
+// true quando escreveu a resposta; caso contrário o servidor responde 200.
+func webhookServer(t *testing.T, respond func(w http.ResponseWriter, n int) bool) (*httptest.Server, func() []webhookCall) {
+	t.Helper()
+	var mu sync.Mutex
+	var calls []webhookCall
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload struct {
+			Events []event.Event `json:"events"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid webhook body: %v", err)
+		}
+		mu.Lock()
+		calls = append(calls, webhookCall{header: r.Header.Clone(), body: body, events: payload.Events})
+		n := len(calls)
+		mu.Unlock()
+		if respond != nil && respond(w, n) {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []webhookCall {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]webhookCall(nil), calls...)
+	}
+}
+
+func newWebhookTest(t *testing.T, cfg *config.Config) *Webhook {
+	t.Helper()
+	w, errWebhook := NewWebhook(cfg)
+	if errWebhook != nil {
+		t.Fatalf("NewWebhook: %v", errWebhook)
+	}
+	t.Cleanup(func() { w.Close() })
+	return w
+}
+
+func TestWebhookSignsBody(t *testing.T) {
+	srv, calls := webhookServer(t, nil)
+	w := newWebhookTest(t, &config.Config{
+		WebhookURL:          srv.URL,
+		WebhookSecret:       "s3cret",
+		WebhookHeaders:      "authorization=Bearer token",
+		WebhookTableHeaders: "dbo.Orders=X-Tenant:42",
+	})
+
+	events := []event.Event{testOrder(event.OpInsert, testPosition(1, 1), nil, testRow(1, "new")), testOrder(event.OpInsert, testPosition(2, 1), nil, testRow(2, "new"))}
+	if errWrite := w.Write(context.Background(), events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	got := calls()
+	if len(got) != 1 {
+		t.Fatalf("got %d requests, want 1", len(got))
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(got[0].body)
+	want := WebhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+
+	header := got[0].header
+	if header.Get("X-Go-Cdc-Signature") != want {
+		t.Errorf("signature = %q, want %q", header.Get("X-Go-Cdc-Signature"), want)
+	}
+	if header.Get("Authorization") != "Bearer token" || header.Get("X-Tenant") != "42" {
+		t.Errorf("configured headers not sent: %v", header)
+	}
+	if header.Get(WebhookHeaderTable) != "dbo.Orders" || header.Get(WebhookHeaderCount) != "2" ||
+		header.Get(WebhookHeaderFirstPos) != events[0].Position.String() || header.Get(WebhookHeaderLastPos) != events[1].Position.String() {
+		t.Errorf("batch headers = %v", header)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []func(w http.ResponseWriter)
+		wantCalls int
+		wantErr   bool
+		minWait   time.Duration
+	}{
+		{
+			name: "5xx and 429 with Retry-After",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+				func(w http.ResponseWriter) {
+					// 1 s pedido pelo servidor, limitado ao backoff máximo de 300 ms
+					w.Header().Set("Retry-After", "1")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+			},
+			wantCalls: 3,
+			minWait:   250 * time.Millisecond,
+		},
+		{
+			name:      "4xx is not retried",
+			responses: []func(w http.ResponseWriter){func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadRequest) }},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+		{
+			name: "attempts exhausted",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			},
+			wantCalls: 4,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := webhookServer(t, func(w http.ResponseWriter, n int) bool {
+				if n > len(tt.responses) {
+					return false
+				}
+				tt.responses[n-1](w)
+				return true
+			})
+			w := newWebhookTest(t, &config.Config{
+				WebhookURL:                   srv.URL,
+				WebhookRetryMaxAttempts:      4,
+				WebhookRetryInitialBackoffMs: 1,
+				WebhookRetryMaxBackoffMs:     300,
+			})
+
+			start := time.Now()
+			errWrite := w.Write(context.Background(), []event.Event{testOrder(event.OpInsert, testPosition(1, 1), nil, testRow(1, "new"))})
+			elapsed := time.Since(start)
+
+			if (errWrite != nil) != tt.wantErr {
+				t.Fatalf("Write error = %v, want error %v", errWrite, tt.wantErr)
+			}
+			if errWrite != nil && errWrite.Code() != "SINK_WRITE_FAILED" {
+				t.Errorf("error code = %s", errWrite.Code())
+			}
+			if got := len(calls()); got != tt.wantCalls {
+				t.Errorf("got %d requests, want %d", got, tt.wantCalls)
+			}
+			if elapsed < tt.minWait || elapsed > 900*time.Millisecond {
+				t.Errorf("Write took %v", elapsed)
+			}
+			if errHealth := w.HealthCheck(context.Background()); (errHealth != nil) != tt.wantErr {
+				t.Errorf("HealthCheck = %v", errHealth)
+			}
+		})
+	}
+}
+
+func TestWebhookSplitsBatches(t *testing.T) {
+	srv, calls := webhookServer(t, nil)
+	w := newWebhookTest(t, &config.Config{WebhookURL: srv.URL, WebhookMaxBatchBytes: 1024})
+
+	var events []event.Event
+	for id := int64(1); id <= 10; id++ {
+		events = append(events, testOrder(event.OpInsert, testPosition(int(id), 1), nil, testRow(id, "new")))
+	}
+	if errWrite := w.Write(context.Background(), events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	got := calls()
+	if len(got) < 2 {
+		t.Fatalf("got %d requests, want the batch split", len(got))
+	}
+	var next int64 = 1
+	for _, call := range got {
+		if len(call.body) > 1024 {
+			t.Errorf("request body has %d bytes", len(call.body))
+		}
+		if call.header.Get(WebhookHeaderCount) != fmt.Sprint(len(call.events)) {
+			t.Errorf("%s = %s for %d events", WebhookHeaderCount, call.header.Get(WebhookHeaderCount), len(call.events))
+		}
+		// as requisições de uma tabela são sequenciais e preservam a ordem
+		for _, evt := range call.events {
+			if id := int64(evt.After["Id"].(float64)); id != next {
+				t.Fatalf("event %d delivered out of order, want %d", id, next)
+			}
+			next++
+		}
+	}
+	if next != 11 {
+		t.Errorf("delivered %d events, want 10", next-1)
+	}
+}
+
+func TestWebhookConcurrencyLimit(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv, calls := webhookServer(t, func(w http.ResponseWriter, n int) bool {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		return false
+	})
+	w := newWebhookTest(t, &config.Config{WebhookURL: srv.URL, WebhookConcurrency: 2})
+
+	var events []event.Event
+	for _, table := range []string{"Orders", "Customers", "Invoices", "Payments", "Shipments"} {
+		events = append(events, testEvent(event.OpInsert, table, testPosition(1, 1), nil, testRow(1, "new")))
+	}
+	if errWrite := w.Write(context.Background(), events); errWrite != nil {
+		t.Fatalf("Write: %v", errWrite)
+	}
+
+	if got := len(calls()); got != 5 {
+		t.Errorf("got %d requests, want one per table", got)
+	}
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak concurrent requests = %d, want 2", got)
+	}
+}
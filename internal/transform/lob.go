@@ -0,0 +1,226 @@
+// internal/transform/lob.go
+package transform
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go-cdc/internal/blob"
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/schema"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// LOBMode como o valor de uma coluna LOB é emitido
+type LOBMode string
+
+const (
+	LOBInclude     LOBMode = "include"     // valor completo
+	LOBTruncate    LOBMode = "truncate"    // primeiros N bytes + coluna em TruncatedColumns
+	LOBHash        LOBMode = "hash"        // {"sha256": ..., "length": ...}
+	LOBExternalize LOBMode = "externalize" // grava no blob store e emite {"ref": ..., "sha256": ..., "length": ...}
+)
+
+// LOBPolicy política aplicada a uma coluna
+type LOBPolicy struct {
+	Mode     LOBMode
+	MaxBytes int
+}
+
+// ParseLOBPolicy interpreta "include", "hash", "externalize" ou "truncate:<bytes>"
+func ParseLOBPolicy(raw string) (LOBPolicy, bool) {
+	mode, arg, _ := strings.Cut(strings.ToLower(strings.TrimSpace(raw)), ":")
+	switch LOBMode(mode) {
+	case LOBInclude, LOBHash, LOBExternalize:
+		return LOBPolicy{Mode: LOBMode(mode)}, arg == ""
+	case LOBTruncate:
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return LOBPolicy{}, false
+		}
+		return LOBPolicy{Mode: LOBTruncate, MaxBytes: n}, true
+	}
+	return LOBPolicy{}, false
+}
+
+// LOB aplica as políticas de colunas LOB e trata LOBs não alterados em updates
+type LOB struct {
+	defaultPolicy LOBPolicy
+	columns       map[string]LOBPolicy // schema.tabela.coluna em minúsculas
+	store         blob.Store
+}
+
+// NewLOB cria a transformação a partir da configuração. store pode ser nil se
+// nenhuma política usar externalize.
+func NewLOB(cfg *config.Config, store blob.Store) (*LOB, static.ErrorUtil) {
+	invalid := func(raw string) static.ErrorUtil {
+		log.Error().Caller().Str("policy", raw).Msg("Invalid LOB policy")
+		return static.NewErrorUtil("Invalid LOB policy", "LOB_POLICY_INVALID", nil, raw)
+	}
+
+	defaultPolicy := LOBPolicy{Mode: LOBInclude}
+	if cfg.LOBDefaultPolicy != "" {
+		policy, ok := ParseLOBPolicy(cfg.LOBDefaultPolicy)
+		if !ok {
+			return nil, invalid(cfg.LOBDefaultPolicy)
+		}
+		defaultPolicy = policy
+	}
+
+	needsStore := defaultPolicy.Mode == LOBExternalize
+	columns := make(map[string]LOBPolicy)
+	for column, raw := range config.ParseTableMap(cfg.LOBPolicies) {
+		policy, ok := ParseLOBPolicy(raw)
+		if !ok {
+			return nil, invalid(column + "=" + raw)
+		}
+		columns[column] = policy
+		needsStore = needsStore || policy.Mode == LOBExternalize
+	}
+
+	if needsStore && store == nil {
+		log.Error().Caller().Msg("LOB externalize policy requires a blob store")
+		return nil, static.NewErrorUtil("LOB externalize policy requires a blob store", "LOB_POLICY_INVALID", nil, "APP_GO_CDC_LOB_BLOB_DIR")
+	}
+
+	return &LOB{defaultPolicy: defaultPolicy, columns: columns, store: store}, nil
+}
+
+func (l *LOB) Name() string {
+	return "lob"
+}
+
+// Apply altera os eventos no próprio lote
+func (l *LOB) Apply(ctx context.Context, events []event.Event) ([]event.Event, static.ErrorUtil) {
+	for i := range events {
+		evt := &events[i]
+		if evt.TableSchema == nil || evt.Op == event.OpSchema {
+			continue
+		}
+		table := strings.ToLower(evt.FullTableName())
+
+		for _, col := range evt.TableSchema.CapturedColumns() {
+			policy, explicit := l.columns[table+"."+strings.ToLower(col.Name)]
+			lob := IsLOB(&col)
+			if !lob && !explicit {
+				continue
+			}
+			if !explicit {
+				policy = l.defaultPolicy
+			}
+
+			if lob {
+				markUnchangedLOB(evt, col.Name)
+			}
+			if policy.Mode == LOBInclude {
+				continue
+			}
+
+			for _, row := range []map[string]interface{}{evt.Before, evt.After} {
+				if errApply := l.applyPolicy(ctx, evt, row, col.Name, policy); errApply != nil {
+					return nil, errApply
+				}
+			}
+		}
+	}
+	return events, nil
+}
+
+// IsLOB indica tipos (max), xml e os tipos legados text/ntext/image
+func IsLOB(col *schema.Column) bool {
+	switch strings.ToLower(col.Type) {
+	case "xml", "text", "ntext", "image":
+		return true
+	case "varchar", "nvarchar", "varbinary":
+		return col.MaxLength == -1
+	}
+	return false
+}
+
+// markUnchangedLOB: em updates o CDC retorna NULL para LOBs não alterados. A
+// coluna é removida das imagens e listada em UnchangedColumns para que o
+// consumidor não a interprete como NULL.
+func markUnchangedLOB(evt *event.Event, column string) {
+	if evt.Op != event.OpUpdate || evt.UpdatedColumns == nil || slices.Contains(evt.UpdatedColumns, column) {
+		return
+	}
+	if value, ok := evt.After[column]; !ok || value != nil {
+		return
+	}
+
+	delete(evt.After, column)
+	if evt.Before != nil && evt.Before[column] == nil {
+		delete(evt.Before, column)
+	}
+	evt.UnchangedColumns = append(evt.UnchangedColumns, column)
+}
+
+func (l *LOB) applyPolicy(ctx context.Context, evt *event.Event, row map[string]interface{}, column string, policy LOBPolicy) static.ErrorUtil {
+	value, ok := row[column]
+	if !ok || value == nil {
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return nil
+	}
+
+	switch policy.Mode {
+	case LOBTruncate:
+		if len(data) <= policy.MaxBytes {
+			return nil
+		}
+		if s, isString := value.(string); isString {
+			row[column] = truncateUTF8(s, policy.MaxBytes)
+		} else {
+			row[column] = data[:policy.MaxBytes]
+		}
+		if !slices.Contains(evt.TruncatedColumns, column) {
+			evt.TruncatedColumns = append(evt.TruncatedColumns, column)
+		}
+	case LOBHash:
+		sum := sha256.Sum256(data)
+		row[column] = map[string]interface{}{
+			"sha256": hex.EncodeToString(sum[:]),
+			"length": len(data),
+		}
+	case LOBExternalize:
+		sum := sha256.Sum256(data)
+		digest := hex.EncodeToString(sum[:])
+		ref, errPut := l.store.Put(ctx, digest, data)
+		if errPut != nil {
+			return errPut
+		}
+		row[column] = map[string]interface{}{
+			"ref":    ref,
+			"sha256": digest,
+			"length": len(data),
+		}
+	}
+	return nil
+}
+
+// truncateUTF8 corta em no máximo n bytes sem quebrar um caractere multibyte
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
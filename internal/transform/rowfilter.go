@@ -0,0 +1,89 @@
+// internal/transform/rowfilter.go
+package transform
+
+import (
+	"context"
+	"strings"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/predicate"
+	"go-cdc/static"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RowFilter descarta eventos de linhas que não satisfazem o predicado da tabela.
+// Em updates as duas imagens são avaliadas, para que uma linha que sai do filtro
+// seja vista pelo consumidor como delete e uma que entra como insert, sem
+// expor a imagem fora do filtro.
+type RowFilter struct {
+	predicates map[string]*predicate.Predicate // schema.tabela em minúsculas
+}
+
+// NewRowFilter compila os predicados de APP_GO_CDC_ROW_FILTERS
+func NewRowFilter(cfg *config.Config) (*RowFilter, static.ErrorUtil) {
+	predicates := make(map[string]*predicate.Predicate)
+	for table, raw := range config.ParseTableMapQuoted(cfg.CDCRowFilters) {
+		p, err := predicate.Parse(raw)
+		if err != nil {
+			log.Error().Caller().Err(err).Str("table", table).Msg("Invalid row filter")
+			return nil, static.NewErrorUtil("Invalid row filter", "ROW_FILTER_INVALID", err, table+": "+err.Error())
+		}
+		predicates[table] = p
+		log.Info().Str("table", table).Str("predicate", p.String()).Msg("Row filter configured")
+	}
+	return &RowFilter{predicates: predicates}, nil
+}
+
+func (f *RowFilter) Name() string {
+	return "row_filter"
+}
+
+func (f *RowFilter) Apply(ctx context.Context, events []event.Event) ([]event.Event, static.ErrorUtil) {
+	if len(f.predicates) == 0 {
+		return events, nil
+	}
+
+	kept := events[:0]
+	for _, evt := range events {
+		p, ok := f.predicates[strings.ToLower(evt.FullTableName())]
+		if !ok || evt.Op == event.OpSchema {
+			kept = append(kept, evt)
+			continue
+		}
+
+		switch evt.Op {
+		case event.OpInsert, event.OpRead:
+			if !p.Match(evt.After) {
+				continue
+			}
+		case event.OpDelete:
+			if !p.Match(evt.Before) {
+				continue
+			}
+		case event.OpUpdate:
+			before := evt.Before != nil && p.Match(evt.Before)
+			after := p.Match(evt.After)
+			switch {
+			case after && (before || evt.Before == nil):
+			case after:
+				// linha entrou no filtro
+				evt.Op = event.OpInsert
+				evt.Before = nil
+				evt.UpdatedColumns = nil
+			case before:
+				// linha saiu do filtro: a imagem posterior não é exposta
+				evt.Op = event.OpDelete
+				evt.After = nil
+				evt.UpdatedColumns = nil
+				evt.UnchangedColumns = nil
+				evt.SetKey(evt.KeyColumns)
+			default:
+				continue
+			}
+		}
+		kept = append(kept, evt)
+	}
+	return kept, nil
+}
@@ -0,0 +1,88 @@
+package transform
+
+import (
+	"context"
+	"testing"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+)
+
+func TestRowFilterApply(t *testing.T) {
+	filter, errFilter := NewRowFilter(&config.Config{
+		CDCRowFilters: "dbo.Orders=TenantId = 42 AND Note <> 'a;b';dbo.Notes=[Body;Text] LIKE '%;%'",
+	})
+	if errFilter != nil {
+		t.Fatalf("NewRowFilter: %v", errFilter)
+	}
+
+	in := func(tenant int64) map[string]interface{} {
+		return map[string]interface{}{"Id": int64(1), "TenantId": tenant, "Note": "x"}
+	}
+	orders := func(op event.Operation, before, after map[string]interface{}) event.Event {
+		evt := event.Event{Op: op, Schema: "dbo", Table: "Orders", KeyColumns: []string{"Id"}, Before: before, After: after}
+		if op == event.OpUpdate {
+			evt.UpdatedColumns = []string{"TenantId"}
+		}
+		evt.SetKey(evt.KeyColumns)
+		return evt
+	}
+
+	tests := []struct {
+		name       string
+		evt        event.Event
+		wantKept   bool
+		wantOp     event.Operation
+		wantBefore bool
+		wantAfter  bool
+	}{
+		{"insert matching", orders(event.OpInsert, nil, in(42)), true, event.OpInsert, false, true},
+		{"insert not matching", orders(event.OpInsert, nil, in(7)), false, "", false, false},
+		{"snapshot read matching", orders(event.OpRead, nil, in(42)), true, event.OpRead, false, true},
+		{"snapshot read not matching", orders(event.OpRead, nil, in(7)), false, "", false, false},
+		{"delete matching", orders(event.OpDelete, in(42), nil), true, event.OpDelete, true, false},
+		{"delete not matching", orders(event.OpDelete, in(7), nil), false, "", false, false},
+		{"update inside filter", orders(event.OpUpdate, in(42), in(42)), true, event.OpUpdate, true, true},
+		{"update outside filter", orders(event.OpUpdate, in(7), in(8)), false, "", false, false},
+		{"update entering filter", orders(event.OpUpdate, in(7), in(42)), true, event.OpInsert, false, true},
+		{"update leaving filter", orders(event.OpUpdate, in(42), in(7)), true, event.OpDelete, true, false},
+		{"update without before image", orders(event.OpUpdate, nil, in(42)), true, event.OpUpdate, false, true},
+		{"unfiltered table", event.Event{Op: event.OpInsert, Schema: "dbo", Table: "Customers", After: in(7)}, true, event.OpInsert, false, true},
+		{"schema event", event.Event{Op: event.OpSchema, Schema: "dbo", Table: "Orders"}, true, event.OpSchema, false, false},
+		{"bracketed column with separator", event.Event{Op: event.OpInsert, Schema: "dbo", Table: "Notes", After: map[string]interface{}{"Body;Text": "a;b"}}, true, event.OpInsert, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errApply := filter.Apply(context.Background(), []event.Event{tt.evt})
+			if errApply != nil {
+				t.Fatalf("Apply: %v", errApply)
+			}
+			if !tt.wantKept {
+				if len(out) != 0 {
+					t.Fatalf("expected event to be dropped, got %+v", out)
+				}
+				return
+			}
+			if len(out) != 1 {
+				t.Fatalf("expected event to be kept")
+			}
+			got := out[0]
+			if got.Op != tt.wantOp || (got.Before != nil) != tt.wantBefore || (got.After != nil) != tt.wantAfter {
+				t.Errorf("got op=%s before=%v after=%v", got.Op, got.Before, got.After)
+			}
+			if got.Op != tt.evt.Op && got.UpdatedColumns != nil {
+				t.Errorf("converted event keeps updated columns %v", got.UpdatedColumns)
+			}
+			if got.Op == event.OpDelete && got.Key["Id"] != int64(1) {
+				t.Errorf("delete key = %v", got.Key)
+			}
+		})
+	}
+}
+
+func TestNewRowFilterRejectsInvalidPredicate(t *testing.T) {
+	if _, errFilter := NewRowFilter(&config.Config{CDCRowFilters: "dbo.Orders=TenantId = (SELECT 1)"}); errFilter == nil {
+		t.Fatal("expected error for invalid predicate")
+	}
+}
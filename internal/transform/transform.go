@@ -0,0 +1,64 @@
+// internal/transform/transform.go
+package transform
+
+import (
+	"context"
+
+	"go-cdc/internal/blob"
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/static"
+)
+
+// Transform ajusta um lote de eventos; pode alterar, descartar ou acrescentar eventos
+type Transform interface {
+	Name() string
+	Apply(ctx context.Context, events []event.Event) ([]event.Event, static.ErrorUtil)
+}
+
+// Chain aplica as transformações em ordem
+type Chain []Transform
+
+// Apply executa cada transformação sobre o resultado da anterior
+func (c Chain) Apply(ctx context.Context, events []event.Event) ([]event.Event, static.ErrorUtil) {
+	for _, t := range c {
+		var errApply static.ErrorUtil
+		events, errApply = t.Apply(ctx, events)
+		if errApply != nil {
+			return nil, errApply
+		}
+		if len(events) == 0 {
+			break
+		}
+	}
+	return events, nil
+}
+
+// NewFromConfig monta a cadeia de transformações da configuração: políticas de
+// LOB (APP_GO_CDC_LOB_*), com o blob store local quando APP_GO_CDC_LOB_BLOB_DIR
+// está definido, seguidas do filtro de linhas (APP_GO_CDC_ROW_FILTERS). As
+// políticas vêm antes para marcar os LOBs não alterados enquanto o evento ainda
+// é um update: o filtro transforma em insert o update que traz a linha para
+// dentro do predicado e limpa UpdatedColumns, e o NULL do CDC chegaria ao
+// destino como dado. Predicados sobre colunas LOB avaliam o valor já com a
+// política aplicada.
+func NewFromConfig(cfg *config.Config) (Chain, static.ErrorUtil) {
+	rowFilter, errFilter := NewRowFilter(cfg)
+	if errFilter != nil {
+		return nil, errFilter
+	}
+
+	var store blob.Store
+	if cfg.LOBBlobDir != "" {
+		fileStore, errBlob := blob.NewFileStore(cfg.LOBBlobDir)
+		if errBlob != nil {
+			return nil, errBlob
+		}
+		store = fileStore
+	}
+	lob, errLOB := NewLOB(cfg, store)
+	if errLOB != nil {
+		return nil, errLOB
+	}
+	return Chain{lob, rowFilter}, nil
+}
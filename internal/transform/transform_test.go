@@ -0,0 +1,119 @@
+package transform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-cdc/internal/config"
+	"go-cdc/internal/event"
+	"go-cdc/internal/schema"
+)
+
+func TestNewFromConfigAppliesLOBPolicies(t *testing.T) {
+	blobDir := filepath.Join(t.TempDir(), "blobs")
+	cfg := &config.Config{
+		LOBDefaultPolicy: "hash",
+		LOBPolicies:      "dbo.docs.body=externalize;dbo.docs.title=truncate:4",
+		LOBBlobDir:       blobDir,
+	}
+	chain, errChain := NewFromConfig(cfg)
+	if errChain != nil {
+		t.Fatalf("NewFromConfig: %v", errChain)
+	}
+
+	tableSchema := &schema.TableSchema{
+		Table: "dbo.docs",
+		Columns: []schema.Column{
+			{Name: "id", Type: "int", Captured: true},
+			{Name: "title", Type: "nvarchar", MaxLength: 200, Captured: true},
+			{Name: "body", Type: "nvarchar", MaxLength: -1, Captured: true},
+			{Name: "raw", Type: "varbinary", MaxLength: -1, Captured: true},
+		},
+	}
+	events := []event.Event{{
+		Op:          event.OpInsert,
+		Schema:      "dbo",
+		Table:       "docs",
+		After:       map[string]interface{}{"id": 1, "title": "abcdef", "body": "large body", "raw": []byte{1, 2, 3}},
+		TableSchema: tableSchema,
+	}}
+
+	events, errApply := chain.Apply(context.Background(), events)
+	if errApply != nil {
+		t.Fatalf("Apply: %v", errApply)
+	}
+	after := events[0].After
+
+	if after["title"] != "abcd" || len(events[0].TruncatedColumns) != 1 {
+		t.Errorf("title = %v, truncated = %v", after["title"], events[0].TruncatedColumns)
+	}
+	raw, ok := after["raw"].(map[string]interface{})
+	if !ok || raw["length"] != 3 || raw["sha256"] == "" {
+		t.Errorf("raw not hashed by default policy: %v", after["raw"])
+	}
+	body, ok := after["body"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("body not externalized: %v", after["body"])
+	}
+	data, err := os.ReadFile(strings.TrimPrefix(body["ref"].(string), "file://"))
+	if err != nil || string(data) != "large body" {
+		t.Errorf("blob %v = %q, %v", body["ref"], data, err)
+	}
+}
+
+func TestNewFromConfigRejectsInvalidPolicy(t *testing.T) {
+	if _, errChain := NewFromConfig(&config.Config{LOBDefaultPolicy: "compress"}); errChain == nil {
+		t.Fatal("expected error for invalid policy")
+	}
+	if _, errChain := NewFromConfig(&config.Config{LOBPolicies: "dbo.docs.body=externalize"}); errChain == nil {
+		t.Fatal("expected error for externalize without blob dir")
+	}
+}
+
+// Update que traz a linha para o filtro sem alterar o LOB: o NULL do CDC não
+// pode chegar ao insert sintetizado como se fosse o conteúdo da coluna
+func TestNewFromConfigMarksUnchangedLOBBeforeRowFilter(t *testing.T) {
+	chain, errChain := NewFromConfig(&config.Config{
+		LOBDefaultPolicy: "include",
+		CDCRowFilters:    "dbo.Orders=Status = 'paid'",
+	})
+	if errChain != nil {
+		t.Fatalf("NewFromConfig: %v", errChain)
+	}
+
+	evt := event.Event{
+		Op:             event.OpUpdate,
+		Schema:         "dbo",
+		Table:          "Orders",
+		KeyColumns:     []string{"Id"},
+		Before:         map[string]interface{}{"Id": int64(1), "Status": "new", "Notes": nil},
+		After:          map[string]interface{}{"Id": int64(1), "Status": "paid", "Notes": nil},
+		UpdatedColumns: []string{"Status"},
+		TableSchema: &schema.TableSchema{
+			Table: "dbo.Orders",
+			Columns: []schema.Column{
+				{Name: "Id", Type: "int", Captured: true},
+				{Name: "Status", Type: "nvarchar", MaxLength: 20, Captured: true},
+				{Name: "Notes", Type: "nvarchar", MaxLength: -1, Captured: true},
+			},
+		},
+	}
+	evt.SetKey(evt.KeyColumns)
+
+	out, errApply := chain.Apply(context.Background(), []event.Event{evt})
+	if errApply != nil {
+		t.Fatalf("Apply: %v", errApply)
+	}
+	if len(out) != 1 || out[0].Op != event.OpInsert {
+		t.Fatalf("expected the update entering the filter as an insert, got %+v", out)
+	}
+	if _, ok := out[0].After["Notes"]; ok {
+		t.Errorf("insert carries the CDC placeholder: After = %v", out[0].After)
+	}
+	if len(out[0].UnchangedColumns) != 1 || out[0].UnchangedColumns[0] != "Notes" {
+		t.Errorf("UnchangedColumns = %v", out[0].UnchangedColumns)
+	}
+}
@@ -4,9 +4,17 @@ package main
 import (
 	"context"
 	"go-cdc/database"
+	"go-cdc/internal/admin"
+	"go-cdc/internal/cdc"
 	"go-cdc/internal/config"
 	"go-cdc/internal/logger"
 	"go-cdc/internal/monitoring"
+	"go-cdc/internal/offset"
+	"go-cdc/internal/pipeline"
+	"go-cdc/internal/schema"
+	"go-cdc/internal/sink"
+	"go-cdc/internal/transform"
+	"go-cdc/static"
 	dlog "log"
 	"os"
 	"os/signal"
@@ -31,6 +39,11 @@ func main() {
 	log.Info().Msg("Logger initialized with pod metadata")
 	log.Info().Msgf("Configuration: %s", cfg.ToString(false))
 
+	// Subcomando administrativo: go-cdc cdc <enable|disable|status>
+	if len(os.Args) > 1 && os.Args[1] == "cdc" {
+		os.Exit(admin.RunCDC(os.Args[2:], cfg))
+	}
+
 	// 4. Inicializar database - INJEÇÃO
 	log.Info().Msg("Initializing database connection pool...")
 	dbManager, dbErr := database.Init(cfg)
@@ -53,15 +66,63 @@ func main() {
 	)
 	go healthMonitor.Start(ctx)
 
+	// 7. Pipeline: stream CDC -> transformações -> sinks
+	log.Info().Msg("Initializing CDC pipeline...")
+	runner, errPipeline := newPipeline(ctx, cfg, dbManager)
+	if errPipeline != nil {
+		log.Fatal().Err(errPipeline).Caller().Msgf("Failed to initialize pipeline: %s", errPipeline.ToString())
+	}
+	go monitoring.NewHealthMonitor(cfg, runner).WithCheckType("sinks").Start(ctx)
+
+	pipelineDone := make(chan static.ErrorUtil, 1)
+	go func() {
+		pipelineDone <- runner.Run(ctx)
+	}()
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	<-sigChan
-	log.Info().Msg("Shutdown signal received, exiting...")
-	cancel()
+	exitCode := 0
+	select {
+	case <-sigChan:
+		log.Info().Msg("Shutdown signal received, exiting...")
+		cancel()
+		// aguarda o pipeline confirmar o último lote e fechar os sinks
+		<-pipelineDone
+	case errRun := <-pipelineDone:
+		if errRun != nil {
+			exitCode = 1
+		}
+		cancel()
+	}
 
 	// Aguarda um pouco para garantir log final
 	time.Sleep(500 * time.Millisecond)
 
 	log.Info().Msg("Application stopped")
+	if exitCode != 0 {
+		dbManager.Close()
+		os.Exit(exitCode)
+	}
+}
+
+// newPipeline monta o stream CDC, as transformações e os sinks configurados
+func newPipeline(ctx context.Context, cfg *config.Config, dbManager *database.DatabaseManager) (*pipeline.Runner, static.ErrorUtil) {
+	history, errHistory := schema.NewFileHistory(cfg.CDCSchemaHistoryFile)
+	if errHistory != nil {
+		return nil, errHistory
+	}
+	stream := cdc.NewStream(cfg, dbManager.GetSQLServer(), offset.NewFileStore(cfg.CDCOffsetFile), history)
+
+	transforms, errTransforms := transform.NewFromConfig(cfg)
+	if errTransforms != nil {
+		return nil, errTransforms
+	}
+
+	sinks, errSinks := sink.NewFromConfig(ctx, cfg)
+	if errSinks != nil {
+		return nil, errSinks
+	}
+
+	return pipeline.NewRunner(cfg, stream, transforms, sinks), nil
 }
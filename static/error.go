@@ -27,6 +27,11 @@ func (e *AbstractError) ToString() string {
 	return "ErrorUtil { Code: " + e.code + ", Message: " + e.error + ", PropagationErrorString: " + e.propagationErrorString + " }"
 }
 
+// Unwrap expõe o erro de origem para errors.Is/errors.As
+func (e *AbstractError) Unwrap() error {
+	return e.propagationError
+}
+
 func (e *AbstractError) SetPropagationError(err error) {
 	e.propagationError = err
 }
@@ -52,4 +57,5 @@ var (
 	ErrDBConnectionFailed      = NewErrorUtil("Failed to connect to the database", "DB_CONNECTION_FAILED", nil, "")
 	ErrLoggerInitFailed        = NewErrorUtil("Failed to initialize logger", "LOGGER_INIT_FAILED", nil, "")
 	ErrUnsupportedDBTechnology = NewErrorUtil("Unsupported database technology", "UNSUPPORTED_DB_TECHNOLOGY", nil, "")
+	ErrLSNNotReachable         = NewErrorUtil("Stored LSN is no longer reachable", "CDC_LSN_NOT_REACHABLE", nil, "")
 )
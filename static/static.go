@@ -14,3 +14,74 @@ const APP_GO_CDC_DB_PING_TIMEOUT_SECONDS = 3      // in seconds
 const APP_GO_CDC_DB_CONN_MAX_IDLE_TIME = 5        // in minutes
 const APP_GO_CDC_NODE_NAME = "localhost"
 const APP_GO_CDC_POD_NAMESPACE = "default"
+
+const APP_GO_CDC_DB_AUTH_MODE = "sql"
+
+const APP_GO_CDC_POLL_INTERVAL_MS = 1000
+const APP_GO_CDC_BATCH_SIZE = 1000
+const APP_GO_CDC_OFFSET_FILE = "go-cdc-offsets.json"
+const APP_GO_CDC_SCHEMA_HISTORY_FILE = "go-cdc-schema-history.jsonl"
+
+const APP_GO_CDC_DB_RETRY_MAX_ATTEMPTS = 5
+const APP_GO_CDC_DB_RETRY_INITIAL_BACKOFF_MS = 200 // in milliseconds
+const APP_GO_CDC_DB_RETRY_MAX_BACKOFF_MS = 10000   // in milliseconds
+const APP_GO_CDC_STREAM_MAX_BACKOFF_SECONDS = 60   // in seconds
+
+const APP_GO_CDC_LOB_DEFAULT_POLICY = "include"
+const APP_GO_CDC_SNAPSHOT_CHUNK_SIZE = 1024
+
+const APP_GO_CDC_TRUNCATE_DETECTION = "ddl"
+const APP_GO_CDC_TRUNCATE_ROWCOUNT_DROP_PCT = 50
+
+const APP_GO_CDC_SINKS = "stdout"
+
+const APP_GO_CDC_KAFKA_TOPIC_TEMPLATE = "{db}.{schema}.{table}"
+const APP_GO_CDC_KAFKA_LINGER_MS = 5             // in milliseconds
+const APP_GO_CDC_KAFKA_BATCH_MAX_BYTES = 1000000 // in bytes
+const APP_GO_CDC_KAFKA_COMPRESSION = "snappy"
+
+const APP_GO_CDC_NATS_STREAM = "GO_CDC"
+const APP_GO_CDC_NATS_SUBJECT_TEMPLATE = "cdc.{db}.{schema}.{table}"
+const APP_GO_CDC_NATS_DUPLICATE_WINDOW_SECONDS = 120 // in seconds
+const APP_GO_CDC_NATS_MAX_PENDING = 4096
+
+const APP_GO_CDC_REDIS_STREAM_TEMPLATE = "cdc:{db}:{schema}:{table}"
+const APP_GO_CDC_REDIS_STREAM_MAXLEN = 100000
+
+const APP_GO_CDC_WEBHOOK_SIGNATURE_HEADER = "X-Go-Cdc-Signature"
+const APP_GO_CDC_WEBHOOK_TIMEOUT_MS = 10000 // in milliseconds
+const APP_GO_CDC_WEBHOOK_CONCURRENCY = 4
+const APP_GO_CDC_WEBHOOK_MAX_BATCH_BYTES = 1048576 // in bytes
+const APP_GO_CDC_WEBHOOK_RETRY_MAX_ATTEMPTS = 6
+const APP_GO_CDC_WEBHOOK_RETRY_INITIAL_BACKOFF_MS = 500 // in milliseconds
+const APP_GO_CDC_WEBHOOK_RETRY_MAX_BACKOFF_MS = 30000   // in milliseconds
+
+const APP_GO_CDC_FILE_PREFIX = "go-cdc"
+const APP_GO_CDC_FILE_MAX_BYTES = 134217728 // in bytes
+const APP_GO_CDC_FILE_MAX_AGE_SECONDS = 300 // in seconds
+const APP_GO_CDC_FILE_COMPRESSION = "none"
+
+const APP_GO_CDC_PARQUET_COMPRESSION = "snappy"
+const APP_GO_CDC_PARQUET_ROW_GROUP_SIZE = 65536 // in rows
+const APP_GO_CDC_PARQUET_S3_ENDPOINT = "s3.amazonaws.com"
+
+const APP_GO_CDC_APPLY_TABLE_TEMPLATE = "{schema}.{table}"
+const APP_GO_CDC_APPLY_MAX_OPEN_CONNS = 4
+
+const APP_GO_CDC_ELASTICSEARCH_INDEX_TEMPLATE = "cdc-{db}-{schema}-{table}"
+const APP_GO_CDC_ELASTICSEARCH_REFRESH = "false"
+const APP_GO_CDC_ELASTICSEARCH_MAX_BULK_BYTES = 5242880 // in bytes
+const APP_GO_CDC_ELASTICSEARCH_TIMEOUT_MS = 30000       // in milliseconds
+const APP_GO_CDC_ELASTICSEARCH_RETRY_MAX_ATTEMPTS = 6
+const APP_GO_CDC_ELASTICSEARCH_RETRY_INITIAL_BACKOFF_MS = 500 // in milliseconds
+const APP_GO_CDC_ELASTICSEARCH_RETRY_MAX_BACKOFF_MS = 30000   // in milliseconds
+
+const APP_GO_CDC_CLICKHOUSE_DATABASE = "default"
+const APP_GO_CDC_CLICKHOUSE_TABLE_TEMPLATE = "{schema}_{table}"
+const APP_GO_CDC_CLICKHOUSE_TIMEOUT_MS = 30000 // in milliseconds
+const APP_GO_CDC_CLICKHOUSE_RETRY_MAX_ATTEMPTS = 6
+const APP_GO_CDC_CLICKHOUSE_RETRY_INITIAL_BACKOFF_MS = 500 // in milliseconds
+const APP_GO_CDC_CLICKHOUSE_RETRY_MAX_BACKOFF_MS = 30000   // in milliseconds
+
+const APP_GO_CDC_GRPC_LISTEN_ADDR = ":9090"
+const APP_GO_CDC_GRPC_BUFFER_SIZE = 10000 // in events